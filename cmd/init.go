@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 )
 
@@ -17,4 +19,35 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(infoCmd)
 	rootCmd.AddCommand(helloCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(maintenanceCmd)
+
+	// export 命令参数
+	exportCmd.Flags().StringVarP(&exportOpts.Table, "table", "t", "", "数据表: users, helloworld")
+	exportCmd.Flags().StringVarP(&exportOpts.Format, "format", "f", "", "输出格式: jsonl, csv（默认根据文件扩展名推断，否则为 jsonl）")
+	exportCmd.Flags().StringVarP(&exportOpts.Output, "output", "o", "-", "输出文件，- 表示标准输出")
+	exportCmd.Flags().IntVar(&exportOpts.BatchSize, "batch-size", 500, "每批读取条数")
+	exportCmd.Flags().IntVar(&exportOpts.Limit, "limit", 0, "最多导出条数，0 表示不限制")
+	exportCmd.Flags().UintVar(&exportOpts.AfterID, "after-id", 0, "仅导出 ID 大于该值的记录")
+	exportCmd.Flags().StringVar(&exportOpts.Since, "since", "", "仅导出创建时间不早于该时间的记录 (RFC3339 或 2006-01-02)")
+	exportCmd.Flags().StringVar(&exportOpts.Until, "until", "", "仅导出创建时间早于该时间的记录 (RFC3339 或 2006-01-02)")
+	exportCmd.Flags().BoolVar(&exportOpts.WithPasswordHash, "with-password-hash", false, "导出用户时包含密码哈希（迁移到新实例时需要）")
+	_ = exportCmd.MarkFlagRequired("table")
+
+	// import 命令参数
+	importCmd.Flags().StringVarP(&importOpts.Table, "table", "t", "", "数据表: users, helloworld")
+	importCmd.Flags().StringVarP(&importOpts.Format, "format", "f", "", "输入格式: jsonl, csv（默认根据文件扩展名推断，否则为 jsonl）")
+	importCmd.Flags().StringVarP(&importOpts.Input, "input", "i", "-", "输入文件，- 表示标准输入")
+	importCmd.Flags().IntVar(&importOpts.BatchSize, "batch-size", 500, "每批（每个事务）导入条数")
+	importCmd.Flags().StringVar(&importOpts.OnConflict, "on-conflict", "skip", "冲突策略: skip, overwrite, fail")
+	importCmd.Flags().BoolVar(&importOpts.DryRun, "dry-run", false, "仅校验并统计，不写入数据库")
+	_ = importCmd.MarkFlagRequired("table")
+
+	// maintenance 命令参数
+	maintenanceCmd.Flags().StringVar(&maintenanceOpts.Addr, "addr", "", "服务地址，如 http://127.0.0.1:8080（默认根据 server 配置推断）")
+	maintenanceCmd.Flags().StringVarP(&maintenanceOpts.Message, "message", "m", "", "开启时的提示消息（默认使用 maintenance.message）")
+	maintenanceCmd.Flags().DurationVar(&maintenanceOpts.RetryAfter, "retry-after", 0, "开启时的 Retry-After，如 10m（默认使用 maintenance.retry_after）")
+	maintenanceCmd.Flags().BoolVar(&maintenanceOpts.Insecure, "insecure", false, "跳过 TLS 证书校验（自签名证书）")
+	maintenanceCmd.Flags().DurationVar(&maintenanceOpts.Timeout, "timeout", 10*time.Second, "请求超时")
 }
@@ -77,6 +77,55 @@ var helloCmd = &cobra.Command{
 	},
 }
 
+var (
+	exportOpts      cli.ExportOptions
+	importOpts      cli.ImportOptions
+	maintenanceOpts cli.MaintenanceOptions
+)
+
+// exportCmd 是批量导出数据的命令
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "批量导出数据表为 JSONL/CSV",
+	Example: `  echohub export --table users -o users.jsonl
+  echohub export --table users --with-password-hash -o users-full.jsonl
+  echohub export --table helloworld --format csv --since 2024-01-01 > hw.csv`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cli.DoExport(exportOpts)
+	},
+}
+
+// importCmd 是批量导入数据的命令
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "从 JSONL/CSV 批量导入数据表",
+	Long: `从 JSONL/CSV 批量导入数据表。导入用于迁移与恢复，不会写入 user.registered 等发件箱事件。
+覆盖已有用户时只能失效本进程的缓存：服务端使用 memory 缓存时，运行中的服务在 cache.default_ttl 内
+仍可能返回旧的密码与角色，需要立即生效时请使用 redis 缓存或在导入后重启服务。`,
+	Example: `  echohub import --table users -i users.jsonl --on-conflict skip
+  echohub import --table helloworld -i hw.csv --on-conflict overwrite --dry-run`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cli.DoImport(importOpts)
+	},
+}
+
+// maintenanceCmd 是切换运行中服务维护模式的命令
+var maintenanceCmd = &cobra.Command{
+	Use:   "maintenance on|off|status",
+	Short: "开启、关闭或查询运行中服务的维护模式",
+	Example: `  ADMIN_TOKEN=... echohub maintenance on --message "数据库迁移中" --retry-after 10m
+  echohub maintenance off --addr https://api.example.com
+  echohub maintenance status`,
+	Args:         cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs:    []string{cli.MaintenanceOn, cli.MaintenanceOff, cli.MaintenanceStatus},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cli.DoMaintenance(args[0], maintenanceOpts)
+	},
+}
+
 // Execute 是根命令的入口函数
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
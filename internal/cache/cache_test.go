@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLRUAndTTL(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(2, time.Minute)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	require.NoError(t, m.Set(ctx, "a", []byte("1"), 0))
+	require.NoError(t, m.Set(ctx, "b", []byte("2"), 0))
+
+	// 访问 a 后 b 成为最久未使用，写入 c 时淘汰 b
+	_, err := m.Get(ctx, "a")
+	require.NoError(t, err)
+	require.NoError(t, m.Set(ctx, "c", []byte("3"), 0))
+
+	_, err = m.Get(ctx, "b")
+	assert.ErrorIs(t, err, ErrMiss)
+	assert.Equal(t, 2, m.Len())
+
+	// 过期后读取未命中并被删除
+	require.NoError(t, m.Set(ctx, "short", []byte("x"), time.Second))
+	now = now.Add(2 * time.Second)
+	_, err = m.Get(ctx, "short")
+	assert.ErrorIs(t, err, ErrMiss)
+
+	require.NoError(t, m.Delete(ctx, "a", "missing"))
+	_, err = m.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrMiss)
+}
+
+func TestRedisWithMiniredis(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+
+	r, err := NewRedis(RedisOptions{Addr: mr.Addr(), KeyPrefix: "test:", TTL: time.Minute})
+	require.NoError(t, err)
+	defer r.Close()
+
+	_, err = r.Get(ctx, "k")
+	assert.ErrorIs(t, err, ErrMiss)
+
+	require.NoError(t, r.Set(ctx, "k", []byte("v"), 0))
+	assert.True(t, mr.Exists("test:k"), "keys should be prefixed")
+	assert.Equal(t, time.Minute, mr.TTL("test:k"))
+
+	v, err := r.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v"), v)
+
+	mr.FastForward(2 * time.Minute)
+	_, err = r.Get(ctx, "k")
+	assert.ErrorIs(t, err, ErrMiss)
+
+	require.NoError(t, r.Set(ctx, "k2", []byte("v"), 0))
+	require.NoError(t, r.Delete(ctx, "k2"))
+	assert.False(t, mr.Exists("test:k2"))
+}
+
+type cachedItem struct {
+	ID     uint
+	Secret string `json:"-"`
+}
+
+func TestGetOrLoadSingleflight(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.Server.Mode = "debug"
+	loader := NewLoader(NewMemory(0, time.Minute), util.NewLogger(cfg))
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (*cachedItem, error) {
+		calls.Add(1)
+		<-release
+		return &cachedItem{ID: 1, Secret: "s3cret"}, nil
+	}
+
+	// 并发加载同一键只调用一次加载函数
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := GetOrLoad(ctx, loader, "item:1", 0, load)
+			assert.NoError(t, err)
+			assert.Equal(t, uint(1), v.ID)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+
+	// 命中缓存，且不受 json 标签影响
+	v, err := GetOrLoad(ctx, loader, "item:1", 0, load)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", v.Secret)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// 失效后重新加载
+	loader.Invalidate(ctx, "item:1")
+	_, err = GetOrLoad(ctx, loader, "item:1", 0, load)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// 加载错误不会被缓存
+	boom := errors.New("boom")
+	_, err = GetOrLoad(ctx, loader, "item:2", 0, func(context.Context) (*cachedItem, error) { return nil, boom })
+	assert.ErrorIs(t, err, boom)
+	_, err = loader.Cache().Get(ctx, "item:2")
+	assert.ErrorIs(t, err, ErrMiss)
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/util/log"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// Loader 读穿透加载器
+// 缓存未命中时调用加载函数并回填缓存，同一键的并发加载通过 singleflight 合并为一次
+type Loader struct {
+	cache Cache
+	group singleflight.Group
+	log   *log.Logger
+}
+
+// NewLoader 创建Loader实例
+func NewLoader(c Cache, logger *log.Logger) *Loader {
+	return &Loader{
+		cache: c,
+		log:   logger,
+	}
+}
+
+// Cache 返回底层缓存
+func (l *Loader) Cache() Cache {
+	return l.cache
+}
+
+// Invalidate 删除缓存键，供写操作后调用
+// 删除失败只记录日志，不影响写操作本身
+func (l *Loader) Invalidate(ctx context.Context, keys ...string) {
+	if err := l.cache.Delete(ctx, keys...); err != nil {
+		l.log.Warn("Failed to invalidate cache", zap.Strings("keys", keys), zap.Error(err))
+	}
+}
+
+// GetOrLoad 读穿透获取 key 对应的值
+// 值以 gob 编码缓存（不受 json:"-" 等标签影响，保证缓存值与数据库读取结果一致），
+// 因此密码哈希等敏感字段须由 load 在返回前清除，避免写入共享的缓存存储；
+// 缓存读写失败时降级为直接调用 load，加载错误不会被缓存；
+// load 的结果由并发调用方共享，ctx 不应携带调用方私有的资源（如事务句柄），事务内的查询应绕过缓存
+func GetOrLoad[T any](ctx context.Context, l *Loader, key string, ttl time.Duration, load func(ctx context.Context) (*T, error)) (*T, error) {
+	b, err := l.cache.Get(ctx, key)
+	if err == nil {
+		var v T
+		if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&v); err == nil {
+			return &v, nil
+		}
+		l.log.Warn("Failed to decode cached value", zap.String("key", key), zap.Error(err))
+	} else if !errors.Is(err, ErrMiss) {
+		l.log.Warn("Failed to read cache", zap.String("key", key), zap.Error(err))
+	}
+
+	// 同一键的并发加载只执行一次，其他调用方共享结果
+	// 加载使用独立的 context，避免首个调用方取消请求导致其他调用方一起失败
+	result, err, _ := l.group.Do(key, func() (any, error) {
+		loadCtx := context.WithoutCancel(ctx)
+		v, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+			l.log.Warn("Failed to encode cache value", zap.String("key", key), zap.Error(err))
+			return v, nil
+		}
+		if err := l.cache.Set(loadCtx, key, buf.Bytes(), ttl); err != nil {
+			l.log.Warn("Failed to write cache", zap.String("key", key), zap.Error(err))
+		}
+		return v, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 返回副本，避免多个调用方共享同一指针后相互修改
+	v := *result.(*T)
+	return &v, nil
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisOptions Redis 缓存配置
+type RedisOptions struct {
+	Addr      string        // 服务地址，例如 127.0.0.1:6379
+	Password  string        // 密码
+	DB        int           // 数据库编号
+	KeyPrefix string        // 键前缀，用于多个应用共享同一实例
+	TTL       time.Duration // 默认过期时间
+}
+
+// Redis 基于 Redis 协议的缓存实现，兼容 Redis、Valkey、KeyDB 等
+type Redis struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedis 创建 Redis 缓存并检查连接
+func NewRedis(opts RedisOptions) (*Redis, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     opts.Addr,
+		Password: opts.Password,
+		DB:       opts.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect redis: %w", err)
+	}
+
+	return &Redis{
+		client: client,
+		prefix: opts.KeyPrefix,
+		ttl:    opts.TTL,
+	}, nil
+}
+
+// Get 获取缓存值
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	b, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return b, err
+}
+
+// Set 写入缓存值
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = r.ttl
+	}
+	return r.client.Set(ctx, r.prefix+key, value, ttl).Err()
+}
+
+// Delete 删除缓存键
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = r.prefix + key
+	}
+	return r.client.Del(ctx, prefixed...).Err()
+}
+
+// Client 返回底层 Redis 客户端
+func (r *Redis) Client() *redis.Client {
+	return r.client
+}
+
+// Close 关闭连接
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	model "github.com/HoronLee/EchoHub/internal/model/maintenance"
+	"github.com/HoronLee/EchoHub/internal/tui"
+)
+
+// maintenance 命令支持的操作
+const (
+	MaintenanceOn     = "on"
+	MaintenanceOff    = "off"
+	MaintenanceStatus = "status"
+)
+
+// maintenancePath 维护模式管理端点
+const maintenancePath = "/api/v1/admin/maintenance"
+
+// MaintenanceOptions maintenance 命令选项
+type MaintenanceOptions struct {
+	Addr       string        // 服务地址（如 http://127.0.0.1:8080），为空时根据 server 配置推断
+	Message    string        // 开启时的提示消息
+	RetryAfter time.Duration // 开启时的 Retry-After，0 表示使用服务端默认值
+	Insecure   bool          // 跳过 TLS 证书校验（自签名证书）
+	Timeout    time.Duration // 请求超时
+}
+
+// DoMaintenance 通过管理端点切换或查询运行中服务的维护模式，使用管理令牌（ADMIN_TOKEN 或 auth.admin.token）认证
+func DoMaintenance(action string, opts MaintenanceOptions) error {
+	token := config.GetAdminToken()
+	if token == "" {
+		return errors.New("admin token is not configured: set ADMIN_TOKEN or auth.admin.token")
+	}
+
+	method, body := http.MethodGet, []byte(nil)
+	switch action {
+	case MaintenanceStatus:
+	case MaintenanceOn, MaintenanceOff:
+		req := model.UpdateRequest{Enabled: action == MaintenanceOn}
+		if req.Enabled {
+			req.Message = opts.Message
+			req.RetryAfter = int(opts.RetryAfter.Seconds())
+		}
+		method = http.MethodPut
+		body, _ = json.Marshal(req)
+	default:
+		return fmt.Errorf("unknown action %q, expected on, off or status", action)
+	}
+
+	addr := opts.Addr
+	if addr == "" {
+		addr = serverAddr(&config.Config)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(addr, "/")+maintenancePath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: opts.Timeout}
+	if opts.Insecure {
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}} // 由 --insecure 显式开启
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach server at %s: %w", addr, err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Code int         `json:"code"`
+		Msg  string      `json:"msg"`
+		Data model.State `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("unexpected response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server responded with status %d: %s (code %d)", resp.StatusCode, result.Msg, result.Code)
+	}
+
+	printMaintenanceState(result.Data)
+	return nil
+}
+
+// serverAddr 根据 server 配置推断本机服务地址，监听所有地址时使用回环地址
+func serverAddr(cfg *config.AppConfig) string {
+	scheme := "http"
+	if cfg.Server.TLS.Enabled {
+		scheme = "https"
+	}
+	host := cfg.Server.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return scheme + "://" + net.JoinHostPort(host, cfg.Server.Port)
+}
+
+func printMaintenanceState(s model.State) {
+	status := "关闭"
+	if s.Enabled {
+		status = "开启"
+	}
+	items := []tui.CLIInfoItem{{Title: "🛠️ 维护模式", Msg: status}}
+	if s.Enabled {
+		if s.Message != "" {
+			items = append(items, tui.CLIInfoItem{Title: "💬 提示消息", Msg: s.Message})
+		}
+		items = append(items, tui.CLIInfoItem{Title: "⏳ Retry-After", Msg: strconv.Itoa(s.RetryAfter) + "s"})
+	}
+	if !s.UpdatedAt.IsZero() {
+		items = append(items, tui.CLIInfoItem{Title: "🕒 切换时间", Msg: s.UpdatedAt.Local().Format(time.DateTime)})
+	}
+	if s.UpdatedBy != "" {
+		items = append(items, tui.CLIInfoItem{Title: "👤 操作者", Msg: s.UpdatedBy})
+	}
+	tui.PrintCLIWithBox(items...)
+}
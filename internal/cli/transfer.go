@@ -0,0 +1,305 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/di"
+	commonModel "github.com/HoronLee/EchoHub/internal/model/common"
+	"github.com/HoronLee/EchoHub/internal/model/helloworld"
+	"github.com/HoronLee/EchoHub/internal/model/user"
+	"github.com/HoronLee/EchoHub/internal/service"
+	"github.com/HoronLee/EchoHub/internal/transfer"
+)
+
+// 支持导入导出的数据表
+const (
+	TableUsers      = "users"
+	TableHelloWorld = "helloworld"
+)
+
+// ExportOptions export 命令选项
+type ExportOptions struct {
+	Table     string // 数据表
+	Format    string // jsonl 或 csv，为空时根据输出文件扩展名推断
+	Output    string // 输出文件，为空或 "-" 时输出到 stdout
+	BatchSize int    // 每批读取条数
+	Limit     int    // 最多导出条数，0 表示不限制
+	AfterID   uint   // 仅导出 ID 大于该值的记录
+	Since     string // 仅导出创建时间不早于该时间的记录（RFC3339 或 2006-01-02）
+	Until     string // 仅导出创建时间早于该时间的记录（RFC3339 或 2006-01-02）
+	// WithPasswordHash 导出用户时包含密码哈希，默认不导出；不含哈希的导出文件无法直接导入
+	WithPasswordHash bool
+}
+
+// ImportOptions import 命令选项
+type ImportOptions struct {
+	Table      string // 数据表
+	Format     string // jsonl 或 csv，为空时根据输入文件扩展名推断
+	Input      string // 输入文件，为空或 "-" 时从 stdin 读取
+	BatchSize  int    // 每批（每个事务）导入条数
+	OnConflict string // 冲突策略: skip, overwrite, fail
+	DryRun     bool   // 仅校验不写入
+}
+
+// DoExport 导出数据表为 JSONL/CSV
+func DoExport(opts ExportOptions) error {
+	if opts.WithPasswordHash && opts.Table != TableUsers {
+		return fmt.Errorf("--with-password-hash only applies to table %s", TableUsers)
+	}
+	filter, err := buildListFilter(opts)
+	if err != nil {
+		return err
+	}
+
+	w, closeFn, err := openOutput(opts.Output)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	format := opts.Format
+	if format == "" {
+		format = transfer.FormatFromPath(opts.Output, transfer.FormatJSONL)
+	}
+
+	svc, cleanup, err := di.InitTransfer(&config.Config)
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	start := time.Now()
+	progress := func(total int) {
+		fmt.Fprintf(os.Stderr, "\r已导出 %s: %d 条", opts.Table, total)
+	}
+
+	var total int
+	switch opts.Table {
+	case TableUsers:
+		enc, err := transfer.NewEncoder(w, format, user.TransferRecord{})
+		if err != nil {
+			return err
+		}
+		if total, err = svc.ExportUsers(ctx, filter, opts.Limit, opts.WithPasswordHash, encodeBatch[user.TransferRecord](enc, progress)); err != nil {
+			return err
+		}
+		if err := enc.Flush(); err != nil {
+			return err
+		}
+	case TableHelloWorld:
+		enc, err := transfer.NewEncoder(w, format, helloworld.TransferRecord{})
+		if err != nil {
+			return err
+		}
+		if total, err = svc.ExportHelloWorlds(ctx, filter, opts.Limit, encodeBatch[helloworld.TransferRecord](enc, progress)); err != nil {
+			return err
+		}
+		if err := enc.Flush(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported table: %s (available: %s, %s)", opts.Table, TableUsers, TableHelloWorld)
+	}
+
+	fmt.Fprintf(os.Stderr, "\r导出完成 %s: %d 条，耗时 %s\n", opts.Table, total, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// DoImport 从 JSONL/CSV 导入数据表
+func DoImport(opts ImportOptions) error {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+
+	r, closeFn, err := openInput(opts.Input)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	format := opts.Format
+	if format == "" {
+		format = transfer.FormatFromPath(opts.Input, transfer.FormatJSONL)
+	}
+	dec, err := transfer.NewDecoder(r, format)
+	if err != nil {
+		return err
+	}
+
+	svc, cleanup, err := di.InitTransfer(&config.Config)
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+	defer cleanup()
+
+	importOpts := service.ImportOptions{OnConflict: opts.OnConflict, DryRun: opts.DryRun}
+	ctx := context.Background()
+
+	var stats importStats
+	switch opts.Table {
+	case TableUsers:
+		err = importAll(dec, opts.BatchSize, &stats, func(batch []*user.TransferRecord) ([]service.ImportOutcome, error) {
+			return svc.ImportUsers(ctx, batch, importOpts)
+		})
+	case TableHelloWorld:
+		err = importAll(dec, opts.BatchSize, &stats, func(batch []*helloworld.TransferRecord) ([]service.ImportOutcome, error) {
+			return svc.ImportHelloWorlds(ctx, batch, importOpts)
+		})
+	default:
+		return fmt.Errorf("unsupported table: %s (available: %s, %s)", opts.Table, TableUsers, TableHelloWorld)
+	}
+
+	prefix := "导入完成"
+	if opts.DryRun {
+		prefix = "校验完成（dry-run，未写入）"
+	}
+	fmt.Fprintf(os.Stderr, "\r%s %s: 读取 %d，新建 %d，覆盖 %d，跳过 %d，无效 %d\n",
+		prefix, opts.Table, stats.read, stats.created, stats.overwritten, stats.skipped, stats.invalid)
+	if !opts.DryRun && opts.Table == TableUsers && stats.overwritten > 0 && isMemoryCache(config.Config.Cache.Driver) {
+		fmt.Fprintln(os.Stderr, "注意: 缓存驱动为 memory，运行中的服务在 cache.default_ttl 内仍可能返回被覆盖用户的旧数据；需要立即生效时请使用 redis 缓存或重启服务")
+	}
+
+	if err != nil {
+		return err
+	}
+	if stats.invalid > 0 {
+		return fmt.Errorf("%d invalid records were not imported", stats.invalid)
+	}
+	return nil
+}
+
+// isMemoryCache 判断是否使用进程内缓存（未配置时默认为 memory）
+func isMemoryCache(driver string) bool {
+	return driver == "" || driver == "memory"
+}
+
+// importStats 导入统计
+type importStats struct {
+	read, created, overwritten, skipped, invalid int
+}
+
+// importAll 按批读取并导入记录，无效记录输出行号与原因后继续
+func importAll[T any](dec transfer.Decoder, batchSize int, stats *importStats, importFn func([]*T) ([]service.ImportOutcome, error)) error {
+	flush := func(batch []*T, lines []int) error {
+		if len(batch) == 0 {
+			return nil
+		}
+		outcomes, err := importFn(batch)
+		if err != nil {
+			if errors.Is(err, service.ErrImportConflict) {
+				return fmt.Errorf("import aborted in batch ending at line %d: %w", lines[len(lines)-1], err)
+			}
+			return err
+		}
+		for i, o := range outcomes {
+			switch o.Action {
+			case service.ImportCreated:
+				stats.created++
+			case service.ImportOverwritten:
+				stats.overwritten++
+			case service.ImportSkipped:
+				stats.skipped++
+			case service.ImportInvalid:
+				stats.invalid++
+				fmt.Fprintf(os.Stderr, "\n第 %d 行无效: %v\n", lines[i], o.Err)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "\r已处理 %d 条", stats.read)
+		return nil
+	}
+
+	batch := make([]*T, 0, batchSize)
+	lines := make([]int, 0, batchSize)
+	for {
+		rec := new(T)
+		err := dec.Decode(rec)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		stats.read++
+		batch = append(batch, rec)
+		lines = append(lines, dec.Line())
+
+		if len(batch) == batchSize {
+			if err := flush(batch, lines); err != nil {
+				return err
+			}
+			batch, lines = batch[:0], lines[:0]
+		}
+	}
+	return flush(batch, lines)
+}
+
+// encodeBatch 返回逐条编码一批记录的回调，每批结束后输出进度
+func encodeBatch[T any](enc transfer.Encoder, progress func(int)) func([]*T) error {
+	written := 0
+	return func(batch []*T) error {
+		for _, rec := range batch {
+			if err := enc.Encode(rec); err != nil {
+				return err
+			}
+		}
+		written += len(batch)
+		progress(written)
+		return nil
+	}
+}
+
+// buildListFilter 将命令行选项转换为查询条件
+func buildListFilter(opts ExportOptions) (commonModel.ListFilter, error) {
+	filter := commonModel.ListFilter{
+		AfterID: opts.AfterID,
+		Limit:   opts.BatchSize,
+	}
+	var err error
+	if filter.Since, err = parseTimeFlag(opts.Since); err != nil {
+		return filter, fmt.Errorf("invalid --since: %w", err)
+	}
+	if filter.Until, err = parseTimeFlag(opts.Until); err != nil {
+		return filter, fmt.Errorf("invalid --until: %w", err)
+	}
+	return filter, nil
+}
+
+// parseTimeFlag 解析 RFC3339 或 2006-01-02 格式的时间
+func parseTimeFlag(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(time.DateOnly, s, time.Local)
+}
+
+func openOutput(path string) (io.Writer, func(), error) {
+	if path == "" || path == "-" {
+		return os.Stdout, func() {}, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() { f.Close() }, nil
+}
+
+func openInput(path string) (io.Reader, func(), error) {
+	if path == "" || path == "-" {
+		return os.Stdin, func() {}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() { f.Close() }, nil
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"log"
 	"os"
+	"time"
 
 	"github.com/spf13/viper"
 
@@ -22,14 +23,48 @@ var JWT_SECRET []byte
 // AppConfig 应用程序配置结构体
 type AppConfig struct {
 	Server struct {
-		Port   string `mapstructure:"port"`   // 服务器端口
-		Host   string `mapstructure:"host"`   // 服务器主机地址
-		Mode   string `mapstructure:"mode"`   // 运行模式，可能的值为 "debug" 或 "release"
-		Locale string `mapstructure:"locale"` // 语言设置，可选值: zh_CN, en_US，默认 zh_CN
+		Port           string   `mapstructure:"port"`            // 服务器端口
+		Host           string   `mapstructure:"host"`            // 服务器主机地址
+		Mode           string   `mapstructure:"mode"`            // 运行模式，可能的值为 "debug" 或 "release"
+		Locale         string   `mapstructure:"locale"`          // 语言设置，可选值: zh_CN, en_US，默认 zh_CN
+		TrustedProxies []string `mapstructure:"trusted_proxies"` // 受信任的反向代理（CIDR 或 IP），留空时以连接对端地址作为客户端 IP
+		RealIPHeader   string   `mapstructure:"real_ip_header"`  // 受信任代理传递客户端 IP 的请求头，可选值: X-Forwarded-For, X-Real-IP
+		Limits         struct {
+			ReadHeaderTimeout time.Duration    `mapstructure:"read_header_timeout"` // 读取请求头超时，防御 slowloris
+			ReadTimeout       time.Duration    `mapstructure:"read_timeout"`        // 读取整个请求（含请求体）超时
+			WriteTimeout      time.Duration    `mapstructure:"write_timeout"`       // 写出响应超时，流式接口需放宽
+			IdleTimeout       time.Duration    `mapstructure:"idle_timeout"`        // keep-alive 连接空闲超时
+			MaxHeaderBytes    int              `mapstructure:"max_header_bytes"`    // 请求头最大字节数
+			MaxBodyBytes      int64            `mapstructure:"max_body_bytes"`      // 请求体最大字节数，0 表示不限制
+			Routes            []BodyLimitRoute `mapstructure:"routes"`              // 按路由覆盖请求体上限，按顺序匹配第一条
+		} `mapstructure:"limits"`
+		TLS struct {
+			Enabled        bool          `mapstructure:"enabled"`         // 是否在应用上终止 TLS
+			CertFile       string        `mapstructure:"cert_file"`       // 证书文件（PEM，可包含中间证书链）
+			KeyFile        string        `mapstructure:"key_file"`        // 私钥文件（PEM）
+			MinVersion     string        `mapstructure:"min_version"`     // 最低 TLS 版本，可选值: 1.2, 1.3
+			CipherSuites   []string      `mapstructure:"cipher_suites"`   // TLS 1.2 允许的密码套件（Go 标准名称），留空使用 Go 默认安全套件
+			ClientAuth     string        `mapstructure:"client_auth"`     // 客户端证书校验，可选值: none, verify_if_given, require
+			ClientCAFile   string        `mapstructure:"client_ca_file"`  // 校验客户端证书的 CA 文件（PEM）
+			ReloadInterval time.Duration `mapstructure:"reload_interval"` // 检查证书文件变化的间隔，0 表示只在收到 SIGHUP 时重新加载
+			Redirect       struct {
+				Enabled bool   `mapstructure:"enabled"` // 是否启动 HTTP→HTTPS 跳转监听
+				Listen  string `mapstructure:"listen"`  // 跳转监听地址，如 :80
+			} `mapstructure:"redirect"`
+		} `mapstructure:"tls"`
 	} `mapstructure:"server"`
 	Database struct {
-		Driver string `mapstructure:"type"`   // 数据库驱动
+		Driver string `mapstructure:"type"`   // 数据库驱动，可选值: mysql, postgres, sqlite
 		Source string `mapstructure:"source"` // 数据库连接字符串
+		// ORM 日志级别: silent, error, warn, info，留空等同 info
+		LogMode       string        `mapstructure:"logmode"`
+		SlowThreshold time.Duration `mapstructure:"slow_threshold"` // 慢查询阈值，0 表示不告警
+		RedactParams  bool          `mapstructure:"redact_params"`  // 日志中的 SQL 不展开绑定参数
+		Sampling      struct {
+			Initial    int           `mapstructure:"initial"`    // 每个周期内同一语句前 N 条全部记录，0 表示不采样
+			Thereafter int           `mapstructure:"thereafter"` // 超过 N 条后每 M 条记录一条
+			Tick       time.Duration `mapstructure:"tick"`       // 采样周期
+		} `mapstructure:"sampling"`
 	} `mapstructure:"database"`
 	Auth struct {
 		Jwt struct {
@@ -38,6 +73,10 @@ type AppConfig struct {
 			Issuer   string `mapstructure:"issuer"`   // JWT的发行者
 			Audience string `mapstructure:"audience"` // JWT的受众
 		} `mapstructure:"jwt"`
+		Admin struct {
+			Token string   `mapstructure:"token"` // 管理端点令牌，供 CLI 等运维工具使用；环境变量 ADMIN_TOKEN 优先，留空时只接受管理员 JWT
+			Roles []string `mapstructure:"roles"` // 可访问管理端点的用户角色
+		} `mapstructure:"admin"`
 	} `mapstructure:"auth"`
 	Swagger struct {
 		Host         string   `mapstructure:"host"`          // Swagger文档的主机地址
@@ -53,28 +92,300 @@ type AppConfig struct {
 		LicenseURL   string   `mapstructure:"license_url"`   // 许可证URL
 	} `mapstructure:"swagger"`
 	CORS struct {
-		AllowOrigins     []string `mapstructure:"allow_origins"`     // 允许的来源列表
-		AllowMethods     []string `mapstructure:"allow_methods"`     // 允许的HTTP方法
-		AllowHeaders     []string `mapstructure:"allow_headers"`     // 允许的请求头
-		ExposeHeaders    []string `mapstructure:"expose_headers"`    // 暴露的响应头
-		AllowCredentials bool     `mapstructure:"allow_credentials"` // 是否允许发送凭证
-		MaxAge           int      `mapstructure:"max_age"`           // 预检请求缓存时间（秒）
+		AllowOrigins        []string    `mapstructure:"allow_origins"`         // 允许的来源列表，支持 "*" 与 https://*.example.com 形式的子域名通配
+		AllowOriginPatterns []string    `mapstructure:"allow_origin_patterns"` // 允许的来源正则，需完整匹配
+		AllowMethods        []string    `mapstructure:"allow_methods"`         // 允许的HTTP方法
+		AllowHeaders        []string    `mapstructure:"allow_headers"`         // 允许的请求头
+		ExposeHeaders       []string    `mapstructure:"expose_headers"`        // 暴露的响应头
+		AllowCredentials    bool        `mapstructure:"allow_credentials"`     // 是否允许发送凭证
+		MaxAge              int         `mapstructure:"max_age"`               // 预检请求缓存时间（秒）
+		Routes              []CORSRoute `mapstructure:"routes"`                // 按路由覆盖策略，按顺序匹配第一条
 	} `mapstructure:"cors"`
+	Outbox struct {
+		Enabled      bool          `mapstructure:"enabled"`       // 是否启动发件箱投递协程
+		Publisher    string        `mapstructure:"publisher"`     // 发布器类型，可选值: nats, log
+		PollInterval time.Duration `mapstructure:"poll_interval"` // 轮询间隔
+		BatchSize    int           `mapstructure:"batch_size"`    // 每次轮询最多投递的事件数
+		MaxAttempts  int           `mapstructure:"max_attempts"`  // 最大投递次数，超过后标记为失败
+		RetryBackoff time.Duration `mapstructure:"retry_backoff"` // 首次重试间隔，之后指数退避
+		MaxBackoff   time.Duration `mapstructure:"max_backoff"`   // 最大重试间隔
+		NATS         struct {
+			URL           string `mapstructure:"url"`            // NATS 服务地址
+			SubjectPrefix string `mapstructure:"subject_prefix"` // 主题前缀，最终主题为 <prefix>.<event_type>
+			Embedded      bool   `mapstructure:"embedded"`       // 是否启动内嵌 NATS 服务（本地开发替身）
+			EmbeddedHost  string `mapstructure:"embedded_host"`  // 内嵌 NATS 监听地址
+			EmbeddedPort  int    `mapstructure:"embedded_port"`  // 内嵌 NATS 监听端口，-1 表示随机端口
+		} `mapstructure:"nats"`
+	} `mapstructure:"outbox"`
+	Cache struct {
+		Driver     string        `mapstructure:"driver"`      // 缓存驱动，可选值: memory, redis, none
+		DefaultTTL time.Duration `mapstructure:"default_ttl"` // 默认过期时间
+		Memory     struct {
+			MaxEntries int `mapstructure:"max_entries"` // 最大条目数，超过后按 LRU 淘汰
+		} `mapstructure:"memory"`
+		Redis struct {
+			Addr      string `mapstructure:"addr"`       // 服务地址
+			Password  string `mapstructure:"password"`   // 密码
+			DB        int    `mapstructure:"db"`         // 数据库编号
+			KeyPrefix string `mapstructure:"key_prefix"` // 键前缀
+		} `mapstructure:"redis"`
+	} `mapstructure:"cache"`
+	Encryption struct {
+		Keys          map[string]string `mapstructure:"keys"`            // 密钥版本 -> base64 编码的 32 字节 AES-256 密钥
+		PrimaryKey    uint32            `mapstructure:"primary_key"`     // 加密新数据使用的密钥版本
+		BlindIndexKey string            `mapstructure:"blind_index_key"` // 盲索引 HMAC 密钥（base64）
+		Rotation      struct {
+			Enabled   bool          `mapstructure:"enabled"`    // 是否启动后台重新加密任务
+			Interval  time.Duration `mapstructure:"interval"`   // 扫描间隔
+			BatchSize int           `mapstructure:"batch_size"` // 每批处理的行数
+		} `mapstructure:"rotation"`
+	} `mapstructure:"encryption"`
+	RateLimit struct {
+		Enabled       bool                       `mapstructure:"enabled"`        // 是否启用限流
+		Store         string                     `mapstructure:"store"`          // 计数存储，可选值: memory, redis
+		APIKeyHeader  string                     `mapstructure:"api_key_header"` // 按 API Key 限流时读取的请求头
+		DefaultPolicy string                     `mapstructure:"default_policy"` // 未匹配任何路由规则时使用的策略，留空表示不限流
+		AdminPolicy   string                     `mapstructure:"admin_policy"`   // 管理端点使用的策略，在管理认证之前执行以限制令牌猜测，留空表示不限流
+		Policies      map[string]RateLimitPolicy `mapstructure:"policies"`       // 策略名 -> 策略
+		Routes        []RateLimitRoute           `mapstructure:"routes"`         // 路由规则，按顺序匹配第一条
+		Redis         struct {
+			Addr      string `mapstructure:"addr"`       // 服务地址
+			Password  string `mapstructure:"password"`   // 密码
+			DB        int    `mapstructure:"db"`         // 数据库编号
+			KeyPrefix string `mapstructure:"key_prefix"` // 键前缀
+		} `mapstructure:"redis"`
+	} `mapstructure:"ratelimit"`
+	Metrics struct {
+		Enabled   bool      `mapstructure:"enabled"`   // 是否暴露 Prometheus 指标
+		Path      string    `mapstructure:"path"`      // 指标路径
+		Listen    string    `mapstructure:"listen"`    // 独立管理端口监听地址（如 127.0.0.1:9090），留空时挂载在业务端口上（需开启 ip_filter）
+		Namespace string    `mapstructure:"namespace"` // 指标名前缀
+		Buckets   []float64 `mapstructure:"buckets"`   // HTTP 耗时直方图分桶（秒），留空使用默认分桶
+	} `mapstructure:"metrics"`
+	Tracing struct {
+		Enabled     bool              `mapstructure:"enabled"`      // 是否启用链路追踪
+		ServiceName string            `mapstructure:"service_name"` // 服务名（service.name 资源属性）
+		Exporter    string            `mapstructure:"exporter"`     // 导出器，可选值: otlp_grpc, otlp_http, stdout
+		Endpoint    string            `mapstructure:"endpoint"`     // OTLP 收集器地址（host:port），留空使用 OTEL_EXPORTER_OTLP_* 环境变量
+		Insecure    bool              `mapstructure:"insecure"`     // OTLP 是否使用明文连接
+		Headers     map[string]string `mapstructure:"headers"`      // OTLP 请求附加头（如鉴权）
+		SampleRatio float64           `mapstructure:"sample_ratio"` // 根 span 采样比例 0~1，上游已采样的请求始终跟随上游决定
+		DBStatement bool              `mapstructure:"db_statement"` // 是否在数据库 span 中记录 SQL（不含绑定参数）
+	} `mapstructure:"tracing"`
+	Compression struct {
+		Enabled      bool     `mapstructure:"enabled"`       // 是否压缩响应
+		Algorithms   []string `mapstructure:"algorithms"`    // 支持的编码，按服务端偏好排序，可选值: br, zstd, gzip
+		Level        string   `mapstructure:"level"`         // 压缩级别，可选值: fastest, default, best
+		MinSize      int      `mapstructure:"min_size"`      // 响应体达到该字节数才压缩
+		ContentTypes []string `mapstructure:"content_types"` // 允许压缩的 Content-Type，按前缀匹配
+		Request      struct {
+			Enabled bool  `mapstructure:"enabled"`  // 是否解压 Content-Encoding: gzip 的请求体
+			MaxSize int64 `mapstructure:"max_size"` // 解压后请求体的最大字节数，防止解压炸弹
+		} `mapstructure:"request"`
+	} `mapstructure:"compression"`
+	Idempotency struct {
+		Enabled          bool          `mapstructure:"enabled"`            // 是否启用 Idempotency-Key 支持
+		Store            string        `mapstructure:"store"`              // 记录存储，可选值: memory, redis
+		Header           string        `mapstructure:"header"`             // 幂等键请求头
+		Methods          []string      `mapstructure:"methods"`            // 需要幂等保护的 HTTP 方法
+		TTL              time.Duration `mapstructure:"ttl"`                // 已完成响应的保存时间
+		LockTTL          time.Duration `mapstructure:"lock_ttl"`           // 处理中记录的过期时间，应大于 write_timeout
+		MaxKeyLength     int           `mapstructure:"max_key_length"`     // 幂等键最大长度
+		MaxResponseBytes int           `mapstructure:"max_response_bytes"` // 可保存的最大响应体字节数，超出时不保存
+		Redis            struct {
+			Addr      string `mapstructure:"addr"`       // 服务地址
+			Password  string `mapstructure:"password"`   // 密码
+			DB        int    `mapstructure:"db"`         // 数据库编号
+			KeyPrefix string `mapstructure:"key_prefix"` // 键前缀
+		} `mapstructure:"redis"`
+	} `mapstructure:"idempotency"`
+	HTTPCache struct {
+		ETag struct {
+			Enabled bool `mapstructure:"enabled"`  // 是否为 GET 响应生成 ETag 并处理 If-None-Match
+			Weak    bool `mapstructure:"weak"`     // 是否生成弱 ETag（W/"..."）
+			MaxSize int  `mapstructure:"max_size"` // 参与计算的最大响应体字节数，超出时不生成 ETag
+		} `mapstructure:"etag"`
+		DefaultCacheControl string `mapstructure:"default_cache_control"` // GET 成功响应未设置 Cache-Control 时使用的值，留空不设置
+		ResponseCache       struct {
+			Enabled    bool          `mapstructure:"enabled"`     // 是否启用服务端响应缓存（仍需在路由上显式挂载）
+			DefaultTTL time.Duration `mapstructure:"default_ttl"` // 路由未指定时的缓存时间
+			MaxSize    int           `mapstructure:"max_size"`    // 可缓存的最大响应体字节数
+		} `mapstructure:"response_cache"`
+	} `mapstructure:"http_cache"`
+	SecurityHeaders struct {
+		Enabled bool `mapstructure:"enabled"` // 是否设置安全响应头
+		HSTS    struct {
+			Enabled           bool          `mapstructure:"enabled"`            // 是否发送 Strict-Transport-Security（仅 release 模式或启用 TLS 时生效）
+			MaxAge            time.Duration `mapstructure:"max_age"`            // max-age
+			IncludeSubdomains bool          `mapstructure:"include_subdomains"` // includeSubDomains
+			Preload           bool          `mapstructure:"preload"`            // preload
+		} `mapstructure:"hsts"`
+		ContentTypeNosniff bool   `mapstructure:"content_type_nosniff"` // X-Content-Type-Options: nosniff
+		FrameOptions       string `mapstructure:"frame_options"`        // X-Frame-Options，可选值: DENY, SAMEORIGIN，留空不设置
+		ReferrerPolicy     string `mapstructure:"referrer_policy"`      // Referrer-Policy，留空不设置
+		PermissionsPolicy  string `mapstructure:"permissions_policy"`   // Permissions-Policy，留空不设置
+		CSP                struct {
+			Enabled         bool                `mapstructure:"enabled"`          // 是否设置 Content-Security-Policy
+			ReportOnly      bool                `mapstructure:"report_only"`      // 只报告不拦截（Content-Security-Policy-Report-Only）
+			ReportPath      string              `mapstructure:"report_path"`      // 违规报告收集端点路径，留空不收集
+			NonceDirectives []string            `mapstructure:"nonce_directives"` // 附加每请求 nonce 的指令，如 script-src
+			Directives      map[string][]string `mapstructure:"directives"`       // 指令 -> 来源列表
+			Routes          []CSPRoute          `mapstructure:"routes"`           // 按路由覆盖策略，按顺序匹配第一条
+		} `mapstructure:"csp"`
+	} `mapstructure:"security_headers"`
+	AccessLog struct {
+		Enabled bool   `mapstructure:"enabled"` // 是否记录访问日志
+		Output  string `mapstructure:"output"`  // 输出位置，可选值: app（应用日志）, stdout, file
+		Format  string `mapstructure:"format"`  // 日志格式，可选值: json, combined（Apache/NCSA combined）
+		File    struct {
+			Path       string `mapstructure:"path"`        // 日志文件路径
+			MaxSize    int    `mapstructure:"max_size"`    // 单个文件最大尺寸（MB）
+			MaxBackups int    `mapstructure:"max_backups"` // 保留的旧文件数量
+			MaxAge     int    `mapstructure:"max_age"`     // 旧文件保留天数
+			Compress   bool   `mapstructure:"compress"`    // 是否压缩旧文件
+		} `mapstructure:"file"`
+		Fields            []string `mapstructure:"fields"`              // 附加字段，可选值: route, query, request_id, trace_id, user_id, referer, user_agent, bytes_in, bytes_out, headers
+		Headers           []string `mapstructure:"headers"`             // fields 包含 headers 时记录的请求头
+		RedactQueryParams []string `mapstructure:"redact_query_params"` // 需要脱敏的查询参数（不区分大小写）
+		RedactHeaders     []string `mapstructure:"redact_headers"`      // 需要脱敏的请求头
+		ExcludePaths      []string `mapstructure:"exclude_paths"`       // 不记录的路径或路由模板，以 * 结尾时按前缀匹配
+		Sampling          struct {
+			Initial    int           `mapstructure:"initial"`    // 每个周期内同一路由的成功请求前 N 条全部记录，0 表示不采样
+			Thereafter int           `mapstructure:"thereafter"` // 超过 N 条后每 M 条记录一条
+			Tick       time.Duration `mapstructure:"tick"`       // 采样周期
+		} `mapstructure:"sampling"`
+	} `mapstructure:"access_log"`
+	BodyCapture struct {
+		Enabled      bool     `mapstructure:"enabled"`       // 是否启用请求/响应体捕获；debug 模式下捕获全部请求，release 模式下只捕获 routes 或 users 命中的请求
+		Routes       []string `mapstructure:"routes"`        // 捕获的路径或路由模板，以 * 结尾时按前缀匹配
+		Users        []string `mapstructure:"users"`         // 捕获的用户（公开ID）
+		MaxBytes     int      `mapstructure:"max_bytes"`     // 每个方向最多捕获的字节数
+		RedactFields []string `mapstructure:"redact_fields"` // 需要脱敏的 JSON/表单字段，不含 . 时匹配任意层级，含 . 时按路径匹配，* 匹配任意字段名
+	} `mapstructure:"body_capture"`
+	IPFilter struct {
+		Enabled        bool              `mapstructure:"enabled"`         // 是否启用 IP 访问控制
+		ReloadInterval time.Duration     `mapstructure:"reload_interval"` // 检查配置文件变化的间隔，0 表示只在收到 SIGHUP 时重新加载
+		Allow          []string          `mapstructure:"allow"`           // 全局允许名单，作用于所有请求
+		Deny           []string          `mapstructure:"deny"`            // 全局拒绝名单，作用于所有请求
+		Groups         map[string]IPList `mapstructure:"groups"`          // 命名名单，由 IPFilter 中间件挂载到路由或分组
+	} `mapstructure:"ip_filter"`
+	Maintenance struct {
+		Enabled     bool          `mapstructure:"enabled"`      // 状态文件不存在时的初始状态，之后以状态文件为准
+		StateFile   string        `mapstructure:"state_file"`   // 维护状态持久化文件，重启后恢复
+		Message     string        `mapstructure:"message"`      // 默认提示消息，开启时可覆盖；留空使用本地化的默认消息
+		RetryAfter  time.Duration `mapstructure:"retry_after"`  // 默认 Retry-After，开启时可覆盖
+		AllowIPs    []string      `mapstructure:"allow_ips"`    // 不受维护模式影响的客户端 IP（CIDR 或 IP）
+		BypassRoles []string      `mapstructure:"bypass_roles"` // 不受维护模式影响的用户角色
+		ExemptPaths []string      `mapstructure:"exempt_paths"` // 不受维护模式影响的路径或路由模板，以 * 结尾时按前缀匹配
+	} `mapstructure:"maintenance"`
+	ErrorReporting struct {
+		Enabled     bool          `mapstructure:"enabled"`      // 是否上报 panic、未处理的 5xx 错误与后台任务失败
+		Environment string        `mapstructure:"environment"`  // 环境名，留空时使用 server.mode
+		DedupWindow time.Duration `mapstructure:"dedup_window"` // 相同指纹的错误在窗口内只上报一次，0 表示不去重
+		QueueSize   int           `mapstructure:"queue_size"`   // 待发送队列长度，队列满时丢弃新事件
+		Sentry      struct {
+			DSN     string        `mapstructure:"dsn"`     // Sentry 协议 DSN，兼容 Sentry 与 GlitchTip 等自建服务，留空时不上报
+			Timeout time.Duration `mapstructure:"timeout"` // 单次上报超时
+		} `mapstructure:"sentry"`
+		File struct {
+			Path       string `mapstructure:"path"`        // 本地 JSONL 崩溃记录文件，留空时不写入
+			MaxSize    int    `mapstructure:"max_size"`    // 单个文件最大大小（MB）
+			MaxBackups int    `mapstructure:"max_backups"` // 保留的旧文件数量
+		} `mapstructure:"file"`
+	} `mapstructure:"error_reporting"`
+	Errors struct {
+		ProblemJSON     bool   `mapstructure:"problem_json"`      // 错误响应统一使用 RFC 7807 application/problem+json，关闭时仅对 Accept 声明该类型的请求使用
+		ProblemTypeBase string `mapstructure:"problem_type_base"` // problem type 的前缀，与业务错误码拼接为错误文档地址，留空时为 about:blank
+	} `mapstructure:"errors"`
+}
+
+// IPList IP 允许/拒绝名单，条目为 CIDR 或单个 IP
+// 拒绝名单优先；允许名单非空时只放行其中的地址
+type IPList struct {
+	Allow []string `mapstructure:"allow"` // 允许名单
+	Deny  []string `mapstructure:"deny"`  // 拒绝名单
+}
+
+// CSPRoute 路由内容安全策略
+type CSPRoute struct {
+	Path       string              `mapstructure:"path"`       // 路由模板，以 * 结尾时按前缀匹配整个分组
+	Directives map[string][]string `mapstructure:"directives"` // 指令 -> 来源列表，完整替换默认策略且不附加 nonce
+}
+
+// CORSRoute 路由跨域策略，未设置的字段沿用全局配置
+type CORSRoute struct {
+	Path                string   `mapstructure:"path"`                  // 路由模板，以 * 结尾时按前缀匹配整个分组
+	AllowOrigins        []string `mapstructure:"allow_origins"`         // 允许的来源列表
+	AllowOriginPatterns []string `mapstructure:"allow_origin_patterns"` // 允许的来源正则
+	AllowMethods        []string `mapstructure:"allow_methods"`         // 允许的HTTP方法
+	AllowHeaders        []string `mapstructure:"allow_headers"`         // 允许的请求头
+	ExposeHeaders       []string `mapstructure:"expose_headers"`        // 暴露的响应头
+	AllowCredentials    *bool    `mapstructure:"allow_credentials"`     // 是否允许发送凭证
+	MaxAge              int      `mapstructure:"max_age"`               // 预检请求缓存时间（秒）
+}
+
+// BodyLimitRoute 路由请求体上限
+type BodyLimitRoute struct {
+	Method       string `mapstructure:"method"`         // HTTP 方法，留空匹配全部
+	Path         string `mapstructure:"path"`           // 路由模板，以 * 结尾时按前缀匹配整个分组
+	MaxBodyBytes int64  `mapstructure:"max_body_bytes"` // 请求体最大字节数，0 表示不限制
+}
+
+// RateLimitPolicy 限流策略
+type RateLimitPolicy struct {
+	Algorithm string        `mapstructure:"algorithm"` // 算法，可选值: token_bucket, sliding_window
+	Limit     int           `mapstructure:"limit"`     // 每个周期允许的请求数
+	Period    time.Duration `mapstructure:"period"`    // 周期
+	Burst     int           `mapstructure:"burst"`     // 令牌桶容量，默认等于 limit（仅 token_bucket）
+	Key       string        `mapstructure:"key"`       // 限流维度，可选值: ip, user, api_key；user/api_key 缺失时回退到 ip
+}
+
+// RateLimitRoute 路由限流规则
+type RateLimitRoute struct {
+	Method string `mapstructure:"method"` // HTTP 方法，留空匹配全部
+	Path   string `mapstructure:"path"`   // 路由模板（如 /api/v1/helloworld/:id），以 * 结尾时按前缀匹配整个分组
+	Policy string `mapstructure:"policy"` // 策略名
 }
 
 //go:embed config.yaml
 var configData []byte
 
+// configFile 已加载的外部配置文件路径
+var configFile string
+
 // LoadAppConfig 加载应用程序配置
 // configPath: 外部配置文件路径，如果为空则只使用嵌入式配置
 func LoadAppConfig(configPath string) {
+	cfg, err := readConfig(configPath, true)
+	if err != nil {
+		panic(model.READ_CONFIG_PANIC + ":" + err.Error())
+	}
+	Config = *cfg
+
+	// 初始化 JWT_SECRET
+	JWT_SECRET = GetJWTSecret()
+}
+
+// ConfigFile 返回已加载的外部配置文件路径，未使用外部配置时为空
+func ConfigFile() string {
+	return configFile
+}
+
+// Reload 重新读取嵌入式配置与外部配置文件，返回新的配置
+// 不修改全局 Config，由调用方决定应用哪些可热更新的配置项
+func Reload() (*AppConfig, error) {
+	return readConfig(configFile, false)
+}
+
+// readConfig 读取嵌入式配置并合并外部配置文件
+// initial 为 true 时外部配置读取失败只记录警告并回退到嵌入式配置；否则返回错误
+func readConfig(configPath string, initial bool) (*AppConfig, error) {
 	v := viper.New()
 	v.SetConfigType("yaml")
 
 	// 1. 先加载嵌入式配置作为默认配置
-	err := v.ReadConfig(bytes.NewReader(configData))
-	if err != nil {
-		panic(model.READ_CONFIG_PANIC + ":" + err.Error())
+	if err := v.ReadConfig(bytes.NewReader(configData)); err != nil {
+		return nil, err
 	}
 
 	// 2. 如果指定了外部配置文件，则用外部配置覆盖
@@ -83,23 +394,36 @@ func LoadAppConfig(configPath string) {
 			v.SetConfigFile(configPath)
 			err = v.MergeInConfig() // 使用MergeInConfig合并配置
 			if err != nil {
+				if !initial {
+					return nil, err
+				}
 				log.Printf("Warning: failed to merge external config: %v, using embedded config\n", err)
-			} else {
+			} else if initial {
+				configFile = configPath
 				log.Printf("Loaded external config from: %s\n", configPath)
 			}
 		} else {
+			if !initial {
+				return nil, err
+			}
 			log.Printf("Warning: external config file not found: %s, using embedded config\n", configPath)
 		}
 	}
 
 	// 3. 将配置反序列化到结构体
-	err = v.Unmarshal(&Config)
-	if err != nil {
-		panic(model.READ_CONFIG_PANIC + ":" + err.Error())
+	var cfg AppConfig
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, err
 	}
+	return &cfg, nil
+}
 
-	// 4. 初始化 JWT_SECRET
-	JWT_SECRET = GetJWTSecret()
+// GetAdminToken 加载管理端点令牌，环境变量 ADMIN_TOKEN 优先，未设置时为空
+func GetAdminToken() string {
+	if token := os.Getenv("ADMIN_TOKEN"); token != "" {
+		return token
+	}
+	return Config.Auth.Admin.Token
 }
 
 // GetJWTSecret 加载JWT密钥
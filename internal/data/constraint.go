@@ -0,0 +1,216 @@
+package data
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/HoronLee/EchoHub/internal/service"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// MySQL 约束相关错误号
+const (
+	mysqlErrDuplicateEntry        = 1062 // Duplicate entry 'x' for key 'users.idx_users_username'
+	mysqlErrBadNull               = 1048 // Column 'x' cannot be null
+	mysqlErrNoDefault             = 1364 // Field 'x' doesn't have a default value
+	mysqlErrNoReferencedRowLegacy = 1216 // Cannot add or update a child row（不含约束详情）
+	mysqlErrRowIsReferencedLegacy = 1217 // Cannot delete or update a parent row（不含约束详情）
+	mysqlErrRowIsReferenced       = 1451 // Cannot delete or update a parent row: a foreign key constraint fails (...)
+	mysqlErrNoReferencedRow       = 1452 // Cannot add or update a child row: a foreign key constraint fails (...)
+	mysqlErrCheckConstraint       = 3819 // Check constraint 'x' is violated.
+)
+
+// Postgres 约束相关 SQLSTATE
+const (
+	pgErrNotNull    = "23502"
+	pgErrForeignKey = "23503"
+	pgErrUnique     = "23505"
+	pgErrCheck      = "23514"
+)
+
+// registerConstraintCallbacks 在写入类语句执行后将驱动的约束冲突错误翻译为 *service.ConstraintError
+// 翻译发生在事务提交/回滚回调之前，不影响 GORM 的默认事务处理
+func registerConstraintCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("echohub:constraint", translateConstraint); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("echohub:constraint", translateConstraint); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("echohub:constraint", translateConstraint); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("echohub:constraint", translateConstraint)
+}
+
+func translateConstraint(db *gorm.DB) {
+	if db.Error == nil {
+		return
+	}
+	db.Error = translateConstraintError(db.Error, db.Statement.Schema, db.Statement.Table)
+}
+
+// translateConstraintError 识别唯一、外键、非空与检查约束冲突，其他错误原样返回
+// 驱动只给出约束或索引名称时，按模型定义查找对应的列
+func translateConstraintError(err error, s *schema.Schema, table string) error {
+	var ce *service.ConstraintError
+	if err == nil || errors.As(err, &ce) {
+		return err
+	}
+
+	var c *service.ConstraintError
+	var myErr *mysql.MySQLError
+	var sqliteErr sqlite3.Error
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.As(err, &myErr):
+		c = mysqlConstraint(myErr)
+	case errors.As(err, &sqliteErr):
+		c = sqliteConstraint(sqliteErr)
+	case errors.As(err, &pgErr):
+		c = pgConstraint(pgErr)
+	}
+	if c == nil {
+		return err
+	}
+
+	if c.Table == "" {
+		c.Table = table
+	}
+	if c.Field == "" && c.Constraint != "" {
+		c.Field = constraintColumns(s, c.Constraint)
+	}
+	c.Err = err
+	return c
+}
+
+func mysqlConstraint(e *mysql.MySQLError) *service.ConstraintError {
+	switch e.Number {
+	case mysqlErrDuplicateEntry:
+		// MySQL 8 的键名带表名前缀，如 users.idx_users_username
+		key := between(e.Message, "for key '", "'")
+		if i := strings.LastIndexByte(key, '.'); i >= 0 {
+			key = key[i+1:]
+		}
+		return &service.ConstraintError{Kind: service.ConstraintUnique, Constraint: key}
+	case mysqlErrBadNull, mysqlErrNoDefault:
+		return &service.ConstraintError{Kind: service.ConstraintNotNull, Field: between(e.Message, "'", "'")}
+	case mysqlErrRowIsReferenced, mysqlErrNoReferencedRow, mysqlErrRowIsReferencedLegacy, mysqlErrNoReferencedRowLegacy:
+		return &service.ConstraintError{
+			Kind:       service.ConstraintForeignKey,
+			Field:      strings.ReplaceAll(between(e.Message, "FOREIGN KEY (", ")"), "`", ""),
+			Constraint: between(e.Message, "CONSTRAINT `", "`"),
+		}
+	case mysqlErrCheckConstraint:
+		return &service.ConstraintError{Kind: service.ConstraintCheck, Constraint: between(e.Message, "'", "'")}
+	}
+	return nil
+}
+
+func sqliteConstraint(e sqlite3.Error) *service.ConstraintError {
+	if e.Code != sqlite3.ErrConstraint {
+		return nil
+	}
+	// 消息格式为 "<KIND> constraint failed: <detail>"，外键冲突不提供细节
+	_, detail, _ := strings.Cut(e.Error(), "constraint failed: ")
+	switch e.ExtendedCode {
+	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+		table, field := sqliteColumns(detail)
+		return &service.ConstraintError{Kind: service.ConstraintUnique, Table: table, Field: field}
+	case sqlite3.ErrConstraintNotNull:
+		table, field := sqliteColumns(detail)
+		return &service.ConstraintError{Kind: service.ConstraintNotNull, Table: table, Field: field}
+	case sqlite3.ErrConstraintForeignKey:
+		return &service.ConstraintError{Kind: service.ConstraintForeignKey}
+	case sqlite3.ErrConstraintTrigger:
+		// ON DELETE RESTRICT 以触发器错误码报告
+		if strings.HasPrefix(e.Error(), "FOREIGN KEY constraint failed") {
+			return &service.ConstraintError{Kind: service.ConstraintForeignKey}
+		}
+	case sqlite3.ErrConstraintCheck:
+		return &service.ConstraintError{Kind: service.ConstraintCheck, Constraint: detail}
+	}
+	return nil
+}
+
+// sqliteColumns 解析 "users.a, users.b" 形式的列清单
+func sqliteColumns(detail string) (table, field string) {
+	var fields []string
+	for _, col := range strings.Split(detail, ",") {
+		t, f, ok := strings.Cut(strings.TrimSpace(col), ".")
+		if !ok {
+			continue
+		}
+		table = t
+		fields = append(fields, f)
+	}
+	return table, strings.Join(fields, ",")
+}
+
+func pgConstraint(e *pgconn.PgError) *service.ConstraintError {
+	c := &service.ConstraintError{Table: e.TableName, Constraint: e.ConstraintName}
+	switch e.Code {
+	case pgErrUnique:
+		c.Kind = service.ConstraintUnique
+	case pgErrForeignKey:
+		c.Kind = service.ConstraintForeignKey
+	case pgErrNotNull:
+		c.Kind = service.ConstraintNotNull
+		c.Field = e.ColumnName
+		return c
+	case pgErrCheck:
+		c.Kind = service.ConstraintCheck
+		return c
+	default:
+		return nil
+	}
+	// Detail 形如 Key (username)=(alice) already exists.
+	if cols := between(e.Detail, "Key (", ")="); cols != "" {
+		c.Field = strings.ReplaceAll(cols, " ", "")
+	}
+	return c
+}
+
+// constraintColumns 按模型中的索引、唯一约束与检查约束定义查找约束名对应的列
+func constraintColumns(s *schema.Schema, name string) string {
+	if s == nil {
+		return ""
+	}
+	for _, idx := range s.ParseIndexes() {
+		if idx.Name != name {
+			continue
+		}
+		cols := make([]string, 0, len(idx.Fields))
+		for _, f := range idx.Fields {
+			cols = append(cols, f.DBName)
+		}
+		return strings.Join(cols, ",")
+	}
+	if chk, ok := s.ParseCheckConstraints()[name]; ok && chk.Field != nil {
+		return chk.DBName
+	}
+	for _, f := range s.Fields {
+		if f.Unique && name == "uni_"+s.Table+"_"+f.DBName {
+			return f.DBName
+		}
+	}
+	return ""
+}
+
+// between 返回 s 中 start 与其后第一个 end 之间的内容，未找到时返回空串
+func between(s, start, end string) string {
+	_, rest, ok := strings.Cut(s, start)
+	if !ok {
+		return ""
+	}
+	v, _, ok := strings.Cut(rest, end)
+	if !ok {
+		return ""
+	}
+	return v
+}
@@ -0,0 +1,203 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/cache"
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/errcode"
+	"github.com/HoronLee/EchoHub/internal/model/user"
+	"github.com/HoronLee/EchoHub/internal/service"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm/schema"
+)
+
+type constraintParent struct {
+	ID   uint
+	Code string `gorm:"type:varchar(20);uniqueIndex"`
+}
+
+type constraintChild struct {
+	ID       uint
+	ParentID uint
+	Parent   constraintParent `gorm:"constraint:OnDelete:RESTRICT"`
+	Name     string           `gorm:"not null"`
+	Age      int              `gorm:"check:chk_constraint_children_age,age >= 0"`
+}
+
+func TestConstraintTranslationSQLite(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.Database.Driver = "sqlite"
+	cfg.Database.Source = ":memory:"
+	cfg.Server.Mode = "debug"
+
+	logger := util.NewLogger(cfg)
+	db, err := NewDB(cfg, logger, noop.NewTracerProvider())
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	// 内存库每个连接相互独立，固定为单连接以便开启外键检查
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.Exec("PRAGMA foreign_keys = ON").Error)
+	require.NoError(t, db.AutoMigrate(&constraintParent{}, &constraintChild{}))
+
+	d, cleanup, err := NewData(db, logger)
+	require.NoError(t, err)
+	defer cleanup()
+	repo := NewUserRepo(d, cache.NewLoader(cache.NewMemory(0, time.Minute), logger), logger)
+	ctx := context.Background()
+
+	t.Run("唯一约束", func(t *testing.T) {
+		require.NoError(t, repo.CreateUser(ctx, &user.User{Username: "bob", Password: "hashed"}))
+		err := repo.CreateUser(ctx, &user.User{Username: "bob", Password: "hashed"})
+
+		assert.ErrorIs(t, err, service.ErrDuplicate)
+		var ce *service.ConstraintError
+		require.ErrorAs(t, err, &ce)
+		assert.Equal(t, "users", ce.Table)
+		assert.Equal(t, "username", ce.Field)
+
+		e, ok := errcode.From(err)
+		require.True(t, ok)
+		assert.Equal(t, 10002, e.Code)
+		assert.Equal(t, map[string]any{"field": "username"}, e.Details())
+	})
+
+	t.Run("外键约束", func(t *testing.T) {
+		err := db.Create(&constraintChild{ParentID: 42, Name: "orphan"}).Error
+		assert.ErrorIs(t, err, service.ErrForeignKey)
+
+		parent := &constraintParent{Code: "p1"}
+		require.NoError(t, db.Create(parent).Error)
+		require.NoError(t, db.Create(&constraintChild{ParentID: parent.ID, Name: "kid"}).Error)
+		err = db.Delete(parent).Error
+		assert.ErrorIs(t, err, service.ErrForeignKey)
+	})
+
+	t.Run("非空约束", func(t *testing.T) {
+		err := db.Exec("INSERT INTO constraint_children (parent_id, name, age) VALUES (NULL, NULL, 1)").Error
+		assert.ErrorIs(t, err, service.ErrNotNull)
+		var ce *service.ConstraintError
+		require.ErrorAs(t, err, &ce)
+		assert.Equal(t, "constraint_children", ce.Table)
+		assert.Equal(t, "name", ce.Field)
+	})
+
+	t.Run("检查约束", func(t *testing.T) {
+		parent := &constraintParent{Code: "p2"}
+		require.NoError(t, db.Create(parent).Error)
+		err := db.Create(&constraintChild{ParentID: parent.ID, Name: "kid", Age: -1}).Error
+		assert.ErrorIs(t, err, service.ErrCheckViolation)
+		var ce *service.ConstraintError
+		require.ErrorAs(t, err, &ce)
+		assert.Equal(t, "chk_constraint_children_age", ce.Constraint)
+		assert.Equal(t, "age", ce.Field)
+	})
+
+	t.Run("其他错误原样返回", func(t *testing.T) {
+		err := db.Exec("INSERT INTO missing_table (id) VALUES (1)").Error
+		require.Error(t, err)
+		var ce *service.ConstraintError
+		assert.False(t, errors.As(err, &ce))
+	})
+}
+
+func TestTranslateConstraintError(t *testing.T) {
+	users, err := schema.Parse(&user.User{}, &sync.Map{}, schema.NamingStrategy{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		err       error
+		wantKind  service.ConstraintKind
+		wantTable string
+		wantField string
+	}{
+		{
+			name:      "MySQL 唯一索引按模型定义解析列",
+			err:       &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'alice' for key 'users.idx_users_username'"},
+			wantKind:  service.ConstraintUnique,
+			wantTable: "users",
+			wantField: "username",
+		},
+		{
+			name:      "MySQL 非空",
+			err:       &mysql.MySQLError{Number: 1048, Message: "Column 'password' cannot be null"},
+			wantKind:  service.ConstraintNotNull,
+			wantTable: "users",
+			wantField: "password",
+		},
+		{
+			name: "MySQL 外键",
+			err: &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails " +
+				"(`echohub`.`orders`, CONSTRAINT `fk_orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))"},
+			wantKind:  service.ConstraintForeignKey,
+			wantTable: "users",
+			wantField: "user_id",
+		},
+		{
+			name:      "MySQL 检查约束",
+			err:       &mysql.MySQLError{Number: 3819, Message: "Check constraint 'chk_users_version' is violated."},
+			wantKind:  service.ConstraintCheck,
+			wantTable: "users",
+		},
+		{
+			name: "Postgres 唯一约束",
+			err: &pgconn.PgError{Code: "23505", TableName: "users", ConstraintName: "idx_users_username",
+				Detail: "Key (username)=(alice) already exists."},
+			wantKind:  service.ConstraintUnique,
+			wantTable: "users",
+			wantField: "username",
+		},
+		{
+			name: "Postgres 外键",
+			err: &pgconn.PgError{Code: "23503", TableName: "orders", ConstraintName: "fk_orders_user",
+				Detail: `Key (user_id)=(7) is not present in table "users".`},
+			wantKind:  service.ConstraintForeignKey,
+			wantTable: "orders",
+			wantField: "user_id",
+		},
+		{
+			name:      "Postgres 非空",
+			err:       &pgconn.PgError{Code: "23502", TableName: "users", ColumnName: "password"},
+			wantKind:  service.ConstraintNotNull,
+			wantTable: "users",
+			wantField: "password",
+		},
+		{
+			name:      "Postgres 检查约束",
+			err:       &pgconn.PgError{Code: "23514", TableName: "users", ConstraintName: "chk_users_version"},
+			wantKind:  service.ConstraintCheck,
+			wantTable: "users",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateConstraintError(tt.err, users, users.Table)
+
+			var ce *service.ConstraintError
+			require.ErrorAs(t, err, &ce)
+			assert.Equal(t, tt.wantKind, ce.Kind)
+			assert.Equal(t, tt.wantTable, ce.Table)
+			assert.Equal(t, tt.wantField, ce.Field)
+			assert.ErrorIs(t, err, tt.err, "driver error should stay in the chain")
+			assert.NotContains(t, ce.Field, "alice", "values must never be exposed")
+		})
+	}
+
+	t.Run("非约束错误原样返回", func(t *testing.T) {
+		plain := &pgconn.PgError{Code: "40001"}
+		assert.Same(t, plain, translateConstraintError(plain, users, users.Table))
+	})
+}
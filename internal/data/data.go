@@ -5,18 +5,31 @@ import (
 	"time"
 
 	"github.com/HoronLee/EchoHub/internal/config"
+	commonModel "github.com/HoronLee/EchoHub/internal/model/common"
 	"github.com/HoronLee/EchoHub/internal/model/helloworld"
+	outboxModel "github.com/HoronLee/EchoHub/internal/model/outbox"
 	"github.com/HoronLee/EchoHub/internal/model/user"
+	"github.com/HoronLee/EchoHub/internal/tracing"
+	"github.com/HoronLee/EchoHub/internal/util/crypto"
 	"github.com/HoronLee/EchoHub/internal/util/log"
 	"github.com/google/wire"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 // ProviderSet is data providers.
-var ProviderSet = wire.NewSet(NewDB, NewData, NewHelloWorldRepo, NewUserRepo)
+var ProviderSet = wire.NewSet(NewDB, NewData, NewTransaction, NewHelloWorldRepo, NewUserRepo, NewOutboxRepo, NewOutboxStore, NewKeyRotator)
+
+// models 需要自动迁移的模型，密钥轮换任务也据此查找加密字段
+var models = []any{
+	&helloworld.HelloWorld{},
+	&user.User{},
+	&outboxModel.Event{},
+}
 
 // Data 统一的数据访问层结构体
 type Data struct {
@@ -36,21 +49,33 @@ func NewData(db *gorm.DB, logger *log.Logger) (*Data, func(), error) {
 }
 
 // NewDB 创建数据库连接
-func NewDB(cfg *config.AppConfig, logger *log.Logger) (*gorm.DB, error) {
+func NewDB(cfg *config.AppConfig, logger *log.Logger, tp trace.TracerProvider) (*gorm.DB, error) {
 	var dialector gorm.Dialector
 
 	// 根据配置选择数据库驱动
 	switch cfg.Database.Driver {
 	case "mysql":
 		dialector = mysql.Open(cfg.Database.Source)
+	case "postgres":
+		dialector = postgres.Open(cfg.Database.Source)
 	case "sqlite":
 		dialector = sqlite.Open(cfg.Database.Source)
 	default:
 		return nil, fmt.Errorf("unsupported database driver: %s", cfg.Database.Driver)
 	}
 
-	// 配置GORM日志
-	gormLogger := log.NewGormLogger(logger)
+	// 加载字段级加密密钥环，供 serializer:encrypted 与盲索引使用
+	keyring, err := crypto.LoadKeyring(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load encryption keyring: %w", err)
+	}
+	if keyring != nil {
+		crypto.SetDefaultKeyring(keyring)
+	}
+
+	// 配置GORM日志，慢查询等日志的来源跳过共享的查询辅助函数，指向调用它们的仓储方法
+	gormLogger := log.NewGormLogger(cfg, logger)
+	gormLogger.SkipCallers((*Data).updateWithVersion, (*Data).idByPublicID)
 
 	// 打开数据库连接
 	db, err := gorm.Open(dialector, &gorm.Config{
@@ -60,6 +85,20 @@ func NewDB(cfg *config.AppConfig, logger *log.Logger) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect database: %w", err)
 	}
 
+	// 为每条语句创建链路追踪子 span
+	if err = db.Use(tracing.NewGormPlugin(tp, cfg.Database.Driver, cfg.Tracing.DBStatement)); err != nil {
+		return nil, fmt.Errorf("failed to register tracing plugin: %w", err)
+	}
+	if err = registerEncryptionCallbacks(db); err != nil {
+		return nil, fmt.Errorf("failed to register encryption callbacks: %w", err)
+	}
+	if err = registerPublicIDCallbacks(db); err != nil {
+		return nil, fmt.Errorf("failed to register public id callbacks: %w", err)
+	}
+	if err = registerConstraintCallbacks(db); err != nil {
+		return nil, fmt.Errorf("failed to register constraint callbacks: %w", err)
+	}
+
 	// 配置连接池
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -73,12 +112,27 @@ func NewDB(cfg *config.AppConfig, logger *log.Logger) (*gorm.DB, error) {
 	logger.Info("Database connected successfully", zap.String("driver", cfg.Database.Driver))
 
 	// 自动迁移数据库表
-	if err = db.AutoMigrate(
-		&helloworld.HelloWorld{},
-		&user.User{},
-	); err != nil {
+	if err = db.AutoMigrate(models...); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
+	if err = backfillPublicIDs(db, models...); err != nil {
+		return nil, fmt.Errorf("failed to backfill public ids: %w", err)
+	}
 
 	return db, nil
 }
+
+// applyListFilter 为批量查询附加游标分页与创建时间过滤条件
+func applyListFilter(db *gorm.DB, f commonModel.ListFilter) *gorm.DB {
+	db = db.Where("id > ?", f.AfterID)
+	if !f.Since.IsZero() {
+		db = db.Where("created_at >= ?", f.Since)
+	}
+	if !f.Until.IsZero() {
+		db = db.Where("created_at < ?", f.Until)
+	}
+	if f.Limit > 0 {
+		db = db.Limit(f.Limit)
+	}
+	return db.Order("id")
+}
@@ -0,0 +1,256 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/HoronLee/EchoHub/internal/util/crypto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// 字段级加密相关的 GORM 标签
+//
+//	Phone     string `gorm:"serializer:encrypted"`           // 落库为 v<版本>:<密文>
+//	PhoneBidx string `gorm:"size:64;index;blind_index:Phone"` // 写入时自动填充 Phone 的盲索引
+const (
+	// EncryptedSerializer 加密序列化器名称
+	EncryptedSerializer = "encrypted"
+	// tagBlindIndex 盲索引标签，值为源字段名
+	tagBlindIndex = "BLIND_INDEX"
+)
+
+func init() {
+	schema.RegisterSerializer(EncryptedSerializer, encryptedSerializer{})
+}
+
+// encryptedSerializer 使用全局密钥环对 string / []byte 字段做 AEAD 加密
+// 附加认证数据为 表名.列名，密文被复制到其他列时无法解密
+type encryptedSerializer struct{}
+
+// Scan 实现 schema.SerializerInterface
+func (encryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var ciphertext string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		ciphertext = v
+	case []byte:
+		ciphertext = string(v)
+	default:
+		return fmt.Errorf("encrypted field %s: unsupported database value %T", field.Name, dbValue)
+	}
+
+	var plaintext []byte
+	if ciphertext != "" {
+		kr, err := crypto.DefaultKeyring()
+		if err != nil {
+			return err
+		}
+		if plaintext, err = kr.Decrypt(ciphertext, encryptionAAD(field)); err != nil {
+			return fmt.Errorf("decrypt %s.%s: %w", field.Schema.Table, field.DBName, err)
+		}
+	}
+
+	fieldValue := reflect.New(field.FieldType).Elem()
+	switch field.FieldType.Kind() {
+	case reflect.String:
+		fieldValue.SetString(string(plaintext))
+	case reflect.Slice:
+		fieldValue.SetBytes(plaintext)
+	default:
+		return fmt.Errorf("encrypted field %s: unsupported type %s", field.Name, field.FieldType)
+	}
+	field.ReflectValueOf(ctx, dst).Set(fieldValue)
+	return nil
+}
+
+// Value 实现 schema.SerializerValuerInterface，空值按空字符串存储
+func (encryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	var plaintext []byte
+	switch v := fieldValue.(type) {
+	case string:
+		plaintext = []byte(v)
+	case []byte:
+		plaintext = v
+	default:
+		return nil, fmt.Errorf("encrypted field %s: unsupported type %T", field.Name, fieldValue)
+	}
+	if len(plaintext) == 0 {
+		return "", nil
+	}
+
+	kr, err := crypto.DefaultKeyring()
+	if err != nil {
+		return nil, err
+	}
+	return kr.Encrypt(plaintext, encryptionAAD(field))
+}
+
+func encryptionAAD(field *schema.Field) []byte {
+	return []byte(field.Schema.Table + "." + field.DBName)
+}
+
+// blindIndex 计算盲索引，供仓储方法构造等值查询条件
+func blindIndex(value string) (string, error) {
+	kr, err := crypto.DefaultKeyring()
+	if err != nil {
+		return "", err
+	}
+	return kr.BlindIndex(value)
+}
+
+// blindIndexField 盲索引字段及其源字段
+type blindIndexField struct {
+	index  *schema.Field
+	source *schema.Field
+}
+
+func blindIndexFields(s *schema.Schema) ([]blindIndexField, error) {
+	var fields []blindIndexField
+	for _, f := range s.Fields {
+		name, ok := f.TagSettings[tagBlindIndex]
+		if !ok {
+			continue
+		}
+		source := s.LookUpField(name)
+		if source == nil {
+			return nil, fmt.Errorf("blind index %s.%s: source field %q not found", s.Name, f.Name, name)
+		}
+		fields = append(fields, blindIndexField{index: f, source: source})
+	}
+	return fields, nil
+}
+
+// registerEncryptionCallbacks 在创建与更新前自动填充 blind_index 标签声明的盲索引列，
+// 并为 map 形式的更新加密 serializer:encrypted 字段（GORM 不会对 map 中的值调用序列化器）
+func registerEncryptionCallbacks(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("echohub:blind_index", fillBlindIndexesOnCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("echohub:blind_index", fillBlindIndexesOnUpdate); err != nil {
+		return err
+	}
+	return db.Callback().Update().After("echohub:blind_index").Before("gorm:update").Register("echohub:encrypt_map", encryptMapUpdates)
+}
+
+func fillBlindIndexesOnCreate(db *gorm.DB) {
+	fields := statementBlindIndexes(db)
+	if len(fields) == 0 {
+		return
+	}
+
+	ctx := db.Statement.Context
+	fill := func(rv reflect.Value) {
+		for _, f := range fields {
+			idx, err := blindIndexOf(sourceValue(ctx, f.source, rv))
+			if err == nil {
+				err = f.index.Set(ctx, rv, idx)
+			}
+			if err != nil {
+				db.AddError(err)
+				return
+			}
+		}
+	}
+
+	switch rv := db.Statement.ReflectValue; rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			fill(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		fill(rv)
+	}
+}
+
+func fillBlindIndexesOnUpdate(db *gorm.DB) {
+	fields := statementBlindIndexes(db)
+	if len(fields) == 0 {
+		return
+	}
+
+	for _, f := range fields {
+		var value interface{}
+		switch dest := db.Statement.Dest.(type) {
+		case map[string]interface{}:
+			v, ok := dest[f.source.Name]
+			if !ok {
+				if v, ok = dest[f.source.DBName]; !ok {
+					continue
+				}
+			}
+			value = v
+		default:
+			rv := reflect.Indirect(reflect.ValueOf(dest))
+			if rv.Kind() != reflect.Struct || rv.Type() != db.Statement.Schema.ModelType {
+				rv = db.Statement.ReflectValue
+			}
+			if rv.Kind() != reflect.Struct {
+				continue
+			}
+			value = sourceValue(db.Statement.Context, f.source, rv)
+		}
+
+		idx, err := blindIndexOf(value)
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+		db.Statement.SetColumn(f.index.DBName, idx)
+	}
+}
+
+func encryptMapUpdates(db *gorm.DB) {
+	dest, ok := db.Statement.Dest.(map[string]interface{})
+	if db.Error != nil || db.Statement.Schema == nil || !ok {
+		return
+	}
+	for key, value := range dest {
+		field := db.Statement.Schema.LookUpField(key)
+		if field == nil || field.TagSettings["SERIALIZER"] != EncryptedSerializer {
+			continue
+		}
+		encrypted, err := encryptedSerializer{}.Value(db.Statement.Context, field, db.Statement.ReflectValue, value)
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+		dest[key] = encrypted
+	}
+}
+
+func statementBlindIndexes(db *gorm.DB) []blindIndexField {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil
+	}
+	fields, err := blindIndexFields(db.Statement.Schema)
+	if err != nil {
+		db.AddError(err)
+		return nil
+	}
+	return fields
+}
+
+// sourceValue 读取源字段的明文值（带序列化器的字段 ValueOf 返回的是包装对象）
+func sourceValue(ctx context.Context, f *schema.Field, rv reflect.Value) interface{} {
+	return f.ReflectValueOf(ctx, rv).Interface()
+}
+
+func blindIndexOf(value interface{}) (string, error) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case nil:
+	default:
+		return "", fmt.Errorf("blind index: unsupported source type %T", value)
+	}
+	if s == "" {
+		return "", nil
+	}
+	return blindIndex(s)
+}
@@ -0,0 +1,122 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/util/crypto"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+type secretNote struct {
+	ID        uint
+	Phone     string `gorm:"serializer:encrypted"`
+	PhoneBidx string `gorm:"size:64;index;blind_index:Phone"`
+	Token     []byte `gorm:"serializer:encrypted"`
+}
+
+func testKeyring(t *testing.T, primary uint32) *crypto.Keyring {
+	kr, err := crypto.NewKeyring(map[uint32][]byte{
+		1: bytes.Repeat([]byte{1}, crypto.KeySize),
+		2: bytes.Repeat([]byte{2}, crypto.KeySize),
+	}, primary, []byte("index-key"))
+	require.NoError(t, err)
+	crypto.SetDefaultKeyring(kr)
+	t.Cleanup(func() { crypto.SetDefaultKeyring(nil) })
+	return kr
+}
+
+func newSQLiteTestDB(t *testing.T) (*gorm.DB, *config.AppConfig, *util.Logger) {
+	cfg := &config.AppConfig{}
+	cfg.Database.Driver = "sqlite"
+	cfg.Database.Source = ":memory:"
+	cfg.Server.Mode = "debug"
+	logger := util.NewLogger(cfg)
+
+	db, err := NewDB(cfg, logger, noop.NewTracerProvider())
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&secretNote{}))
+	return db, cfg, logger
+}
+
+func rawColumn(t *testing.T, db *gorm.DB, id uint, col string) string {
+	var v string
+	require.NoError(t, db.Table("secret_notes").Select(col).Where("id = ?", id).Scan(&v).Error)
+	return v
+}
+
+func TestEncryptedSerializerAndBlindIndex(t *testing.T) {
+	testKeyring(t, 1)
+	db, _, _ := newSQLiteTestDB(t)
+
+	notes := []secretNote{{Phone: "13800000000", Token: []byte("totp")}, {Phone: "13900000000"}}
+	require.NoError(t, db.Create(&notes).Error)
+
+	raw := rawColumn(t, db, notes[0].ID, "phone")
+	assert.True(t, strings.HasPrefix(raw, "v1:"), "stored value should carry key version")
+	assert.NotContains(t, raw, "13800000000")
+	assert.Empty(t, rawColumn(t, db, notes[1].ID, "token"), "empty values stay empty")
+
+	// 通过盲索引做等值查询
+	idx, err := blindIndex("13900000000")
+	require.NoError(t, err)
+	var found secretNote
+	require.NoError(t, db.Where("phone_bidx = ?", idx).First(&found).Error)
+	assert.Equal(t, notes[1].ID, found.ID)
+	assert.Equal(t, "13900000000", found.Phone)
+
+	// 更新时同步刷新盲索引
+	require.NoError(t, db.Model(&found).Updates(map[string]any{"phone": "13700000000"}).Error)
+	idx, _ = blindIndex("13700000000")
+	var updated secretNote
+	require.NoError(t, db.Where("phone_bidx = ?", idx).First(&updated).Error)
+	assert.Equal(t, "13700000000", updated.Phone)
+
+	var first secretNote
+	require.NoError(t, db.First(&first, notes[0].ID).Error)
+	assert.Equal(t, []byte("totp"), first.Token)
+}
+
+func TestKeyRotatorReencryptsOldVersions(t *testing.T) {
+	testKeyring(t, 1)
+	db, cfg, logger := newSQLiteTestDB(t)
+
+	notes := []secretNote{{Phone: "a", Token: []byte("x")}, {Phone: "b"}, {Phone: "c"}}
+	require.NoError(t, db.Create(&notes).Error)
+
+	// 切换主密钥后旧数据仍可读
+	kr := testKeyring(t, 2)
+	var before secretNote
+	require.NoError(t, db.First(&before, notes[0].ID).Error)
+	assert.Equal(t, "a", before.Phone)
+
+	cfg.Encryption.Rotation.BatchSize = 2
+	r := NewKeyRotator(cfg, db, logger)
+	r.models = []any{&secretNote{}}
+
+	n, err := r.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	for _, note := range notes {
+		assert.False(t, kr.NeedsRotation(rawColumn(t, db, note.ID, "phone")))
+	}
+
+	n, err = r.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n, "already rotated values are skipped")
+
+	var after secretNote
+	require.NoError(t, db.First(&after, notes[0].ID).Error)
+	assert.Equal(t, "a", after.Phone)
+	assert.Equal(t, []byte("x"), after.Token)
+}
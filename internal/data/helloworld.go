@@ -3,10 +3,12 @@ package data
 import (
 	"context"
 
+	commonModel "github.com/HoronLee/EchoHub/internal/model/common"
 	"github.com/HoronLee/EchoHub/internal/model/helloworld"
 	"github.com/HoronLee/EchoHub/internal/service"
 	"github.com/HoronLee/EchoHub/internal/util/log"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // helloworldRepo HelloWorld数据访问实现
@@ -26,7 +28,7 @@ func NewHelloWorldRepo(data *Data, logger *log.Logger) service.HelloWorldRepo {
 // CreateHelloWorld 创建HelloWorld记录
 func (r *helloworldRepo) CreateHelloWorld(ctx context.Context, hw *helloworld.HelloWorld) error {
 	r.data.log.Debug("Creating HelloWorld record", zap.String("message", hw.Message))
-	err := r.data.db.WithContext(ctx).Create(hw).Error
+	err := r.data.DB(ctx).Create(hw).Error
 	if err != nil {
 		r.data.log.Error("Failed to create HelloWorld record", zap.Error(err))
 		return err
@@ -35,6 +37,75 @@ func (r *helloworldRepo) CreateHelloWorld(ctx context.Context, hw *helloworld.He
 	return nil
 }
 
+// GetHelloWorldByID 根据ID查询HelloWorld记录
+func (r *helloworldRepo) GetHelloWorldByID(ctx context.Context, id uint) (*helloworld.HelloWorld, error) {
+	r.data.log.Debug("Getting HelloWorld record by ID", zap.Uint("id", id))
+	var hw helloworld.HelloWorld
+	err := r.data.DB(ctx).First(&hw, id).Error
+	if err != nil {
+		r.data.log.Debug("HelloWorld record not found", zap.Uint("id", id), zap.Error(err))
+		return nil, err
+	}
+	return &hw, nil
+}
+
+// GetHelloWorldByPublicID 根据公开ID查询HelloWorld记录
+func (r *helloworldRepo) GetHelloWorldByPublicID(ctx context.Context, publicID string) (*helloworld.HelloWorld, error) {
+	r.data.log.Debug("Getting HelloWorld record by public ID", zap.String("public_id", publicID))
+	var hw helloworld.HelloWorld
+	err := r.data.DB(ctx).Where("public_id = ?", publicID).First(&hw).Error
+	if err != nil {
+		r.data.log.Debug("HelloWorld record not found", zap.String("public_id", publicID), zap.Error(err))
+		return nil, err
+	}
+	return &hw, nil
+}
+
+// ResolveHelloWorldID 将公开ID解析为内部主键
+func (r *helloworldRepo) ResolveHelloWorldID(ctx context.Context, publicID string) (uint, error) {
+	return r.data.idByPublicID(ctx, &helloworld.HelloWorld{}, publicID)
+}
+
+// UpdateHelloWorld 以乐观锁方式更新HelloWorld记录
+// hw.Version 为调用方期望的版本号，更新成功后自增
+func (r *helloworldRepo) UpdateHelloWorld(ctx context.Context, hw *helloworld.HelloWorld) error {
+	r.data.log.Debug("Updating HelloWorld record", zap.Uint("id", hw.ID), zap.Uint("version", hw.Version))
+	err := r.data.updateWithVersion(ctx, &helloworld.HelloWorld{}, hw.ID, hw.Version, map[string]any{
+		"message": hw.Message,
+	})
+	if err != nil {
+		r.data.log.Warn("Failed to update HelloWorld record", zap.Error(err), zap.Uint("id", hw.ID))
+		return err
+	}
+	hw.Version++
+	r.data.log.Info("HelloWorld record updated successfully", zap.Uint("id", hw.ID), zap.Uint("version", hw.Version))
+	return nil
+}
+
+// OverwriteHelloWorld 覆盖HelloWorld消息（用于数据导入），版本号自增
+func (r *helloworldRepo) OverwriteHelloWorld(ctx context.Context, hw *helloworld.HelloWorld) error {
+	r.data.log.Debug("Overwriting HelloWorld record", zap.Uint("id", hw.ID))
+	err := r.data.DB(ctx).Model(&helloworld.HelloWorld{}).Where("id = ?", hw.ID).Updates(map[string]any{
+		"message": hw.Message,
+		"version": gorm.Expr("version + ?", 1),
+	}).Error
+	if err != nil {
+		r.data.log.Error("Failed to overwrite HelloWorld record", zap.Error(err), zap.Uint("id", hw.ID))
+		return err
+	}
+	return nil
+}
+
+// ListHelloWorlds 按ID游标分页查询HelloWorld记录
+func (r *helloworldRepo) ListHelloWorlds(ctx context.Context, filter commonModel.ListFilter) ([]*helloworld.HelloWorld, error) {
+	var records []*helloworld.HelloWorld
+	if err := applyListFilter(r.data.DB(ctx), filter).Find(&records).Error; err != nil {
+		r.data.log.Error("Failed to list HelloWorld records", zap.Error(err))
+		return nil, err
+	}
+	return records, nil
+}
+
 // GetDatabaseInfo 获取数据库连接信息
 func (r *helloworldRepo) GetDatabaseInfo(ctx context.Context) (string, error) {
 	r.data.log.Debug("Getting database info")
@@ -0,0 +1,220 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/errreport"
+	"github.com/HoronLee/EchoHub/internal/util/crypto"
+	"github.com/HoronLee/EchoHub/internal/util/log"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// KeyRotator 密钥轮换后台任务
+// 周期性扫描所有带 serializer:encrypted 字段的表，将非主密钥版本的密文重新加密为主密钥版本
+type KeyRotator struct {
+	db     *gorm.DB
+	log    *log.Logger
+	models []any
+
+	enabled   bool
+	interval  time.Duration
+	batchSize int
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewKeyRotator 创建KeyRotator实例
+func NewKeyRotator(cfg *config.AppConfig, db *gorm.DB, logger *log.Logger) *KeyRotator {
+	r := &KeyRotator{
+		db:        db,
+		log:       logger,
+		models:    models,
+		enabled:   cfg.Encryption.Rotation.Enabled,
+		interval:  cfg.Encryption.Rotation.Interval,
+		batchSize: cfg.Encryption.Rotation.BatchSize,
+	}
+	if r.interval <= 0 {
+		r.interval = time.Hour
+	}
+	if r.batchSize <= 0 {
+		r.batchSize = 500
+	}
+	return r
+}
+
+// Start 启动后台任务，未开启或未配置密钥环时直接返回
+func (r *KeyRotator) Start() {
+	if !r.enabled {
+		return
+	}
+	if _, err := crypto.DefaultKeyring(); err != nil {
+		r.log.Warn("Key rotation enabled but keyring is not configured", zap.Error(err))
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go r.loop(ctx)
+	r.log.Info("Key rotator started", zap.Duration("interval", r.interval), zap.Int("batch_size", r.batchSize))
+}
+
+// Stop 停止后台任务并等待当前批次结束
+func (r *KeyRotator) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel = nil
+	r.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	select {
+	case <-done:
+		r.log.Info("Key rotator stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *KeyRotator) loop(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		if n, err := r.RunOnce(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			r.log.Error("Key rotation failed", zap.Error(err))
+			errreport.Default().Report(ctx, errreport.NewJobEvent("key_rotation", err))
+		} else if n > 0 {
+			r.log.Info("Re-encrypted values with primary key", zap.Int("count", n))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 执行一轮完整扫描，返回重新加密的字段值数量
+// 无法解密的值会被记录并跳过，不会阻塞其余数据的轮换
+func (r *KeyRotator) RunOnce(ctx context.Context) (int, error) {
+	kr, err := crypto.DefaultKeyring()
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, model := range r.models {
+		stmt := &gorm.Statement{DB: r.db}
+		if err := stmt.Parse(model); err != nil {
+			return total, err
+		}
+		n, err := r.rotateTable(ctx, kr, stmt.Schema)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("rotate %s: %w", stmt.Schema.Table, err)
+		}
+	}
+	return total, nil
+}
+
+func (r *KeyRotator) rotateTable(ctx context.Context, kr *crypto.Keyring, s *schema.Schema) (int, error) {
+	pk := s.PrioritizedPrimaryField
+	var columns []string
+	for _, f := range s.Fields {
+		if f.TagSettings["SERIALIZER"] == EncryptedSerializer && f.DBName != "" {
+			columns = append(columns, f.DBName)
+		}
+	}
+	if len(columns) == 0 || pk == nil {
+		return 0, nil
+	}
+
+	// 仅扫描存在非主密钥版本密文的行
+	prefix := crypto.CiphertextPrefix(kr.Primary()) + "%"
+	conds := make([]string, len(columns))
+	args := make([]any, len(columns))
+	for i, col := range columns {
+		conds[i] = fmt.Sprintf("(%s <> '' AND %s NOT LIKE ?)", col, col)
+		args[i] = prefix
+	}
+	filter := strings.Join(conds, " OR ")
+
+	rotated := 0
+	var last any = 0
+	for {
+		var rows []map[string]any
+		err := r.db.WithContext(ctx).Table(s.Table).
+			Select(append([]string{pk.DBName}, columns...)).
+			Where(pk.DBName+" > ?", last).
+			Where(filter, args...).
+			Order(pk.DBName).Limit(r.batchSize).
+			Find(&rows).Error
+		if err != nil {
+			return rotated, err
+		}
+
+		for _, row := range rows {
+			id := row[pk.DBName]
+			for _, col := range columns {
+				old := columnString(row[col])
+				if !kr.NeedsRotation(old) {
+					continue
+				}
+				aad := []byte(s.Table + "." + col)
+				fresh, err := kr.Reencrypt(old, aad)
+				if err != nil {
+					r.log.Warn("Skip value that cannot be re-encrypted",
+						zap.String("table", s.Table), zap.String("column", col), zap.Any("id", id), zap.Error(err))
+					continue
+				}
+				// 以旧密文为条件更新，避免覆盖并发写入的新值
+				res := r.db.WithContext(ctx).Table(s.Table).
+					Where(pk.DBName+" = ? AND "+col+" = ?", id, old).
+					UpdateColumn(col, fresh)
+				if res.Error != nil {
+					return rotated, res.Error
+				}
+				rotated += int(res.RowsAffected)
+			}
+			last = id
+		}
+
+		if len(rows) < r.batchSize {
+			return rotated, nil
+		}
+	}
+}
+
+func columnString(v any) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case []byte:
+		return string(s)
+	}
+	return ""
+}
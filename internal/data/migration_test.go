@@ -8,6 +8,7 @@ import (
 	"github.com/HoronLee/EchoHub/internal/model/user"
 	util "github.com/HoronLee/EchoHub/internal/util/log"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 func TestUserModelMigration(t *testing.T) {
@@ -21,7 +22,7 @@ func TestUserModelMigration(t *testing.T) {
 	logger := util.NewLogger(cfg)
 
 	// 初始化数据库（包含 AutoMigrate）
-	db, err := NewDB(cfg, logger)
+	db, err := NewDB(cfg, logger, noop.NewTracerProvider())
 	assert.NoError(t, err, "Database initialization should succeed")
 	assert.NotNil(t, db, "Database instance should not be nil")
 
@@ -96,7 +97,7 @@ func TestDatabaseMigrationWithExistingData(t *testing.T) {
 	logger := util.NewLogger(cfg)
 
 	// 第一次初始化数据库
-	db1, err := NewDB(cfg, logger)
+	db1, err := NewDB(cfg, logger, noop.NewTracerProvider())
 	assert.NoError(t, err)
 
 	// 创建一些测试数据
@@ -112,7 +113,7 @@ func TestDatabaseMigrationWithExistingData(t *testing.T) {
 	sqlDB1.Close()
 
 	// 重新初始化数据库（模拟应用重启）
-	db2, err := NewDB(cfg, logger)
+	db2, err := NewDB(cfg, logger, noop.NewTracerProvider())
 	assert.NoError(t, err)
 
 	// 验证数据仍然存在且表结构正确
@@ -0,0 +1,124 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	outboxModel "github.com/HoronLee/EchoHub/internal/model/outbox"
+	"github.com/HoronLee/EchoHub/internal/outbox"
+	"github.com/HoronLee/EchoHub/internal/service"
+	"github.com/HoronLee/EchoHub/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// outboxRepo 发件箱数据访问实现
+// 同时实现 service.OutboxRepo（写入）与 outbox.Store（投递）
+type outboxRepo struct {
+	data *Data
+	log  *log.Logger
+}
+
+// NewOutboxRepo 创建OutboxRepo实例
+func NewOutboxRepo(data *Data, logger *log.Logger) service.OutboxRepo {
+	return &outboxRepo{
+		data: data,
+		log:  logger,
+	}
+}
+
+// NewOutboxStore 创建供 Relay 使用的发件箱存储
+func NewOutboxStore(data *Data, logger *log.Logger) outbox.Store {
+	return &outboxRepo{
+		data: data,
+		log:  logger,
+	}
+}
+
+// AddEvents 写入待投递事件
+// 在 Transaction.InTx 中调用时与领域数据共享同一事务
+func (r *outboxRepo) AddEvents(ctx context.Context, events ...*outboxModel.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	err := r.data.DB(ctx).Create(&events).Error
+	if err != nil {
+		r.log.Error("Failed to add outbox events", zap.Error(err))
+		return err
+	}
+	for _, e := range events {
+		r.log.Debug("Outbox event added", zap.Uint("id", e.ID), zap.String("event_type", e.EventType))
+	}
+	return nil
+}
+
+// FetchPending 按写入顺序返回到期的待投递事件
+func (r *outboxRepo) FetchPending(ctx context.Context, now time.Time, limit int) ([]*outboxModel.Event, error) {
+	var events []*outboxModel.Event
+	// 同一聚合中存在处于退避或失败状态的前序事件时，后续事件必须等待
+	err := r.data.DB(ctx).
+		Where("status = ? AND next_attempt_at <= ?", outboxModel.StatusPending, now).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM outbox AS prev
+			WHERE prev.aggregate_type = outbox.aggregate_type
+			  AND prev.aggregate_id = outbox.aggregate_id
+			  AND prev.id < outbox.id
+			  AND (prev.status = ? OR (prev.status = ? AND prev.next_attempt_at > ?))
+		)`, outboxModel.StatusFailed, outboxModel.StatusPending, now).
+		Order("id").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// MarkPublished 标记事件投递成功
+func (r *outboxRepo) MarkPublished(ctx context.Context, id uint, at time.Time) error {
+	return r.data.DB(ctx).Model(&outboxModel.Event{}).Where("id = ?", id).Updates(map[string]any{
+		"status":       outboxModel.StatusPublished,
+		"published_at": at,
+		"last_error":   "",
+	}).Error
+}
+
+// MarkRetry 记录投递失败并设置下次重试时间
+func (r *outboxRepo) MarkRetry(ctx context.Context, id uint, attempts int, next time.Time, lastErr string) error {
+	return r.data.DB(ctx).Model(&outboxModel.Event{}).Where("id = ?", id).Updates(map[string]any{
+		"attempts":        attempts,
+		"next_attempt_at": next,
+		"last_error":      lastErr,
+	}).Error
+}
+
+// MarkFailed 标记事件为失败状态，不再自动重试
+func (r *outboxRepo) MarkFailed(ctx context.Context, id uint, attempts int, lastErr string) error {
+	return r.data.DB(ctx).Model(&outboxModel.Event{}).Where("id = ?", id).Updates(map[string]any{
+		"status":     outboxModel.StatusFailed,
+		"attempts":   attempts,
+		"last_error": lastErr,
+	}).Error
+}
+
+// PendingStats 返回待投递事件数以及最早一条的写入时间
+func (r *outboxRepo) PendingStats(ctx context.Context) (int64, time.Time, error) {
+	var count int64
+	db := r.data.DB(ctx).Model(&outboxModel.Event{}).Where("status = ?", outboxModel.StatusPending)
+	if err := db.Count(&count).Error; err != nil {
+		return 0, time.Time{}, err
+	}
+	if count == 0 {
+		return 0, time.Time{}, nil
+	}
+
+	var oldest outboxModel.Event
+	err := r.data.DB(ctx).
+		Where("status = ?", outboxModel.StatusPending).
+		Order("id").
+		Select("created_at").
+		First(&oldest).Error
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return count, oldest.CreatedAt, nil
+}
@@ -0,0 +1,101 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	commonModel "github.com/HoronLee/EchoHub/internal/model/common"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// registerPublicIDCallbacks 创建记录前为声明了 PublicID 字段且未赋值的模型生成公开ID
+func registerPublicIDCallbacks(db *gorm.DB) error {
+	return db.Callback().Create().Before("gorm:create").Register("echohub:public_id", fillPublicIDs)
+}
+
+func fillPublicIDs(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	field := db.Statement.Schema.LookUpField(commonModel.PublicIDField)
+	if field == nil {
+		return
+	}
+
+	ctx := db.Statement.Context
+	fill := func(rv reflect.Value) {
+		if _, zero := field.ValueOf(ctx, rv); zero {
+			if err := field.Set(ctx, rv, commonModel.NewPublicID()); err != nil {
+				db.AddError(err)
+			}
+		}
+	}
+
+	switch rv := db.Statement.ReflectValue; rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			fill(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		fill(rv)
+	}
+}
+
+// backfillPublicIDs 为迁移前已存在、尚无公开ID的记录补齐公开ID
+// 公开ID列允许 NULL，因此新增列时不会与唯一索引冲突
+func backfillPublicIDs(db *gorm.DB, models ...any) error {
+	const batchSize = 500
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		field := stmt.Schema.LookUpField(commonModel.PublicIDField)
+		pk := stmt.Schema.PrioritizedPrimaryField
+		if field == nil || pk == nil {
+			continue
+		}
+		if err := backfillTable(db, stmt.Schema, field, pk, batchSize); err != nil {
+			return fmt.Errorf("backfill %s.%s: %w", stmt.Schema.Table, field.DBName, err)
+		}
+	}
+	return nil
+}
+
+func backfillTable(db *gorm.DB, s *schema.Schema, field, pk *schema.Field, batchSize int) error {
+	for {
+		var ids []uint
+		err := db.Table(s.Table).
+			Where(field.DBName+" IS NULL OR "+field.DBName+" = ''").
+			Order(pk.DBName).Limit(batchSize).
+			Pluck(pk.DBName, &ids).Error
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			err := db.Table(s.Table).Where(pk.DBName+" = ?", id).
+				UpdateColumn(field.DBName, commonModel.NewPublicID()).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(ids) < batchSize {
+			return nil
+		}
+	}
+}
+
+// idByPublicID 根据公开ID查询内部主键，不存在时返回 gorm.ErrRecordNotFound
+func (d *Data) idByPublicID(ctx context.Context, model any, publicID string) (uint, error) {
+	var ids []uint
+	err := d.DB(ctx).Model(model).Where("public_id = ?", publicID).Limit(1).Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return ids[0], nil
+}
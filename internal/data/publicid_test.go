@@ -0,0 +1,71 @@
+package data
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/cache"
+	commonModel "github.com/HoronLee/EchoHub/internal/model/common"
+	"github.com/HoronLee/EchoHub/internal/model/helloworld"
+	"github.com/HoronLee/EchoHub/internal/model/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestPublicIDGeneratedAndResolved(t *testing.T) {
+	db, _, logger := newSQLiteTestDB(t)
+	d, cleanup, err := NewData(db, logger)
+	require.NoError(t, err)
+	defer cleanup()
+	ctx := context.Background()
+
+	users := NewUserRepo(d, cache.NewLoader(cache.NewMemory(0, time.Minute), logger), logger)
+	u := &user.User{Username: "alice", Password: "hashedpassword"}
+	require.NoError(t, users.CreateUser(ctx, u))
+	assert.True(t, commonModel.IsPublicID(u.PublicID), "public id should be generated on create")
+
+	id, err := users.ResolveUserID(ctx, u.PublicID)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, id)
+
+	found, err := users.GetUserByPublicID(ctx, u.PublicID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", found.Username)
+	assert.Empty(t, found.Password, "password hash must not be cached")
+
+	_, err = users.ResolveUserID(ctx, commonModel.NewPublicID())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// 角色不经过缓存，其他进程修改后立即可见
+	require.NoError(t, db.Model(&user.User{}).Where("id = ?", u.ID).Update("role", user.RoleAdmin).Error)
+	role, err := users.GetUserRole(ctx, u.PublicID)
+	require.NoError(t, err)
+	assert.Equal(t, user.RoleAdmin, role)
+	_, err = users.GetUserRole(ctx, commonModel.NewPublicID())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// 导入等场景显式指定的公开ID保持不变
+	hws := NewHelloWorldRepo(d, logger)
+	given := commonModel.NewPublicID()
+	hw := &helloworld.HelloWorld{PublicID: given, Message: "hi"}
+	require.NoError(t, hws.CreateHelloWorld(ctx, hw))
+	got, err := hws.GetHelloWorldByPublicID(ctx, given)
+	require.NoError(t, err)
+	assert.Equal(t, hw.ID, got.ID)
+}
+
+func TestBackfillPublicIDs(t *testing.T) {
+	db, _, _ := newSQLiteTestDB(t)
+
+	// 模拟迁移前已存在的记录
+	require.NoError(t, db.Exec("INSERT INTO hello_worlds (message, version) VALUES ('a', 1), ('b', 1)").Error)
+	require.NoError(t, backfillPublicIDs(db, models...))
+
+	var records []helloworld.HelloWorld
+	require.NoError(t, db.Order("id").Find(&records).Error)
+	require.Len(t, records, 2)
+	assert.True(t, commonModel.IsPublicID(records[0].PublicID))
+	assert.NotEqual(t, records[0].PublicID, records[1].PublicID)
+}
@@ -0,0 +1,74 @@
+package data
+
+import (
+	"context"
+	"sync"
+
+	"github.com/HoronLee/EchoHub/internal/service"
+	"gorm.io/gorm"
+)
+
+// txKey 是在 context 中存储事务状态的键
+type txKey struct{}
+
+// txState 事务句柄以及提交后需要执行的回调
+type txState struct {
+	db          *gorm.DB
+	mu          sync.Mutex
+	afterCommit []func()
+}
+
+// DB 返回绑定 ctx 的数据库句柄
+// 如果 ctx 处于 InTx 开启的事务中，则返回该事务句柄，保证多个仓库的写入共享同一事务
+func (d *Data) DB(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*txState); ok {
+		return tx.db
+	}
+	return d.db.WithContext(ctx)
+}
+
+// InTx 在同一数据库事务中执行 fn，fn 返回错误时回滚
+// 已处于事务中时直接复用外层事务
+func (d *Data) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if inTx(ctx) {
+		return fn(ctx)
+	}
+
+	state := &txState{}
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state.db = tx
+		return fn(context.WithValue(ctx, txKey{}, state))
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range state.afterCommit {
+		hook()
+	}
+	return nil
+}
+
+// inTx 判断 ctx 是否处于 InTx 开启的事务中
+func inTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txState)
+	return ok
+}
+
+// AfterCommit 注册事务提交后执行的回调（例如缓存失效）
+// 不在事务中时立即执行；事务回滚时回调不会执行
+func (d *Data) AfterCommit(ctx context.Context, fn func()) {
+	tx, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		fn()
+		return
+	}
+	tx.mu.Lock()
+	tx.afterCommit = append(tx.afterCommit, fn)
+	tx.mu.Unlock()
+}
+
+// NewTransaction 创建事务管理器
+func NewTransaction(d *Data) service.Transaction {
+	return d
+}
@@ -2,32 +2,66 @@ package data
 
 import (
 	"context"
+	"strconv"
 
+	"github.com/HoronLee/EchoHub/internal/cache"
+	commonModel "github.com/HoronLee/EchoHub/internal/model/common"
 	"github.com/HoronLee/EchoHub/internal/model/user"
 	"github.com/HoronLee/EchoHub/internal/service"
 	"github.com/HoronLee/EchoHub/internal/util/log"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // userRepo 用户数据访问实现
 type userRepo struct {
-	data *Data
-	log  *log.Logger
+	data  *Data
+	cache *cache.Loader
+	log   *log.Logger
 }
 
 // NewUserRepo 创建UserRepo实例
 // 注意：返回的是 service.UserRepo 接口类型
-func NewUserRepo(data *Data, logger *log.Logger) service.UserRepo {
+func NewUserRepo(data *Data, loader *cache.Loader, logger *log.Logger) service.UserRepo {
 	return &userRepo{
-		data: data,
-		log:  logger,
+		data:  data,
+		cache: loader,
+		log:   logger,
 	}
 }
 
+// userCacheKey 返回按ID缓存用户的键
+func userCacheKey(id uint) string {
+	return "user:id:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// userPublicIDCacheKey 返回公开ID到内部主键映射的缓存键
+// 公开ID创建后不再变化，映射无需随更新失效；用户删除后按主键查询会返回不存在
+func userPublicIDCacheKey(publicID string) string {
+	return "user:pid:" + publicID
+}
+
+// getOrLoad 读穿透缓存加载
+// 事务内直接通过事务句柄查询：既能读到本事务未提交的写入，也避免 singleflight 加载函数
+// 在并发调用方之间共享该事务句柄，或把未提交的数据回填到共享缓存
+func getOrLoad[T any](ctx context.Context, l *cache.Loader, key string, load func(ctx context.Context) (*T, error)) (*T, error) {
+	if inTx(ctx) {
+		return load(ctx)
+	}
+	return cache.GetOrLoad(ctx, l, key, 0, load)
+}
+
+// invalidateUser 在事务提交后删除用户缓存
+func (r *userRepo) invalidateUser(ctx context.Context, id uint) {
+	r.data.AfterCommit(ctx, func() {
+		r.cache.Invalidate(context.WithoutCancel(ctx), userCacheKey(id))
+	})
+}
+
 // CreateUser 创建用户记录
 func (r *userRepo) CreateUser(ctx context.Context, u *user.User) error {
 	r.log.Debug("Creating user", zap.String("username", u.Username))
-	err := r.data.db.WithContext(ctx).Create(u).Error
+	err := r.data.DB(ctx).Create(u).Error
 	if err != nil {
 		r.log.Error("Failed to create user", zap.Error(err), zap.String("username", u.Username))
 		return err
@@ -40,7 +74,7 @@ func (r *userRepo) CreateUser(ctx context.Context, u *user.User) error {
 func (r *userRepo) GetUserByUsername(ctx context.Context, username string) (*user.User, error) {
 	r.log.Debug("Getting user by username", zap.String("username", username))
 	var u user.User
-	err := r.data.db.WithContext(ctx).Where("username = ?", username).First(&u).Error
+	err := r.data.DB(ctx).Where("username = ?", username).First(&u).Error
 	if err != nil {
 		r.log.Debug("User not found", zap.String("username", username), zap.Error(err))
 		return nil, err
@@ -49,27 +83,118 @@ func (r *userRepo) GetUserByUsername(ctx context.Context, username string) (*use
 	return &u, nil
 }
 
-// GetUserByID 根据用户ID查询用户
+// GetUserByID 根据用户ID查询用户，返回的用户不含密码哈希（需要校验密码时使用 GetUserByUsername）
+// 读穿透缓存，缓存未命中时查询数据库并回填；事务内不经过缓存
 func (r *userRepo) GetUserByID(ctx context.Context, id uint) (*user.User, error) {
 	r.log.Debug("Getting user by ID", zap.Uint("id", id))
-	var u user.User
-	err := r.data.db.WithContext(ctx).First(&u, id).Error
+	u, err := getOrLoad(ctx, r.cache, userCacheKey(id), func(ctx context.Context) (*user.User, error) {
+		var u user.User
+		if err := r.data.DB(ctx).First(&u, id).Error; err != nil {
+			return nil, err
+		}
+		// 缓存可能位于共享的外部存储（如 Redis），密码哈希不写入缓存
+		u.Password = ""
+		return &u, nil
+	})
 	if err != nil {
 		r.log.Debug("User not found", zap.Uint("id", id), zap.Error(err))
 		return nil, err
 	}
 	r.log.Debug("User found", zap.Uint("id", id), zap.String("username", u.Username))
-	return &u, nil
+	return u, nil
+}
+
+// ResolveUserID 将公开ID解析为内部主键，映射结果读穿透缓存
+func (r *userRepo) ResolveUserID(ctx context.Context, publicID string) (uint, error) {
+	id, err := getOrLoad(ctx, r.cache, userPublicIDCacheKey(publicID), func(ctx context.Context) (*uint, error) {
+		id, err := r.data.idByPublicID(ctx, &user.User{}, publicID)
+		if err != nil {
+			return nil, err
+		}
+		return &id, nil
+	})
+	if err != nil {
+		r.log.Debug("User public ID not found", zap.String("public_id", publicID), zap.Error(err))
+		return 0, err
+	}
+	return *id, nil
+}
+
+// GetUserByPublicID 根据公开ID查询用户
+func (r *userRepo) GetUserByPublicID(ctx context.Context, publicID string) (*user.User, error) {
+	id, err := r.ResolveUserID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetUserByID(ctx, id)
+}
+
+// GetUserRole 根据公开ID查询用户当前角色
+// 直接读取数据库而不经过缓存：角色可能由其他进程（如 import 命令）修改，进程内缓存无法感知
+func (r *userRepo) GetUserRole(ctx context.Context, publicID string) (string, error) {
+	var u user.User
+	if err := r.data.DB(ctx).Select("role").Where("public_id = ?", publicID).First(&u).Error; err != nil {
+		r.log.Debug("User public ID not found", zap.String("public_id", publicID), zap.Error(err))
+		return "", err
+	}
+	return u.Role, nil
+}
+
+// UpdateUser 以乐观锁方式更新用户信息
+// u.Version 为调用方期望的版本号，更新成功后自增
+func (r *userRepo) UpdateUser(ctx context.Context, u *user.User) error {
+	r.log.Debug("Updating user", zap.Uint("id", u.ID), zap.Uint("version", u.Version))
+	err := r.data.updateWithVersion(ctx, &user.User{}, u.ID, u.Version, map[string]any{
+		"username": u.Username,
+	})
+	if err != nil {
+		r.log.Warn("Failed to update user", zap.Error(err), zap.Uint("id", u.ID))
+		return err
+	}
+	u.Version++
+	r.invalidateUser(ctx, u.ID)
+	r.log.Info("User updated successfully", zap.Uint("id", u.ID), zap.Uint("version", u.Version))
+	return nil
+}
+
+// OverwriteUser 覆盖用户密码及非空的角色（用于数据导入），版本号自增
+func (r *userRepo) OverwriteUser(ctx context.Context, u *user.User) error {
+	r.log.Debug("Overwriting user", zap.Uint("id", u.ID))
+	updates := map[string]any{
+		"password": u.Password,
+		"version":  gorm.Expr("version + ?", 1),
+	}
+	if u.Role != "" {
+		updates["role"] = u.Role
+	}
+	err := r.data.DB(ctx).Model(&user.User{}).Where("id = ?", u.ID).Updates(updates).Error
+	if err != nil {
+		r.log.Error("Failed to overwrite user", zap.Error(err), zap.Uint("id", u.ID))
+		return err
+	}
+	r.invalidateUser(ctx, u.ID)
+	return nil
+}
+
+// ListUsers 按ID游标分页查询用户
+func (r *userRepo) ListUsers(ctx context.Context, filter commonModel.ListFilter) ([]*user.User, error) {
+	var users []*user.User
+	if err := applyListFilter(r.data.DB(ctx), filter).Find(&users).Error; err != nil {
+		r.log.Error("Failed to list users", zap.Error(err))
+		return nil, err
+	}
+	return users, nil
 }
 
 // DeleteUser 删除用户
 func (r *userRepo) DeleteUser(ctx context.Context, id uint) error {
 	r.log.Debug("Deleting user", zap.Uint("id", id))
-	err := r.data.db.WithContext(ctx).Delete(&user.User{}, id).Error
+	err := r.data.DB(ctx).Delete(&user.User{}, id).Error
 	if err != nil {
 		r.log.Error("Failed to delete user", zap.Error(err), zap.Uint("id", id))
 		return err
 	}
+	r.invalidateUser(ctx, id)
 	r.log.Info("User deleted successfully", zap.Uint("id", id))
 	return nil
 }
@@ -0,0 +1,39 @@
+package data
+
+import (
+	"context"
+
+	"github.com/HoronLee/EchoHub/internal/service"
+	"gorm.io/gorm"
+)
+
+// updateWithVersion 以乐观锁方式更新单条记录
+// 更新语句附加 WHERE version = ? 条件并将 version 自增；未命中任何行时，
+// 区分记录不存在（gorm.ErrRecordNotFound）与版本冲突（*service.ConflictError）
+func (d *Data) updateWithVersion(ctx context.Context, model any, id, version uint, values map[string]any) error {
+	db := d.DB(ctx)
+	values["version"] = gorm.Expr("version + ?", 1)
+
+	result := db.Model(model).Where("id = ? AND version = ?", id, version).Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	resource := "record"
+	stmt := &gorm.Statement{DB: d.db}
+	if err := stmt.Parse(model); err == nil {
+		resource = stmt.Schema.Table
+	}
+	return &service.ConflictError{Resource: resource, ID: id, Version: version}
+}
@@ -0,0 +1,117 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/cache"
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/model/user"
+	"github.com/HoronLee/EchoHub/internal/service"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+func TestUserRepoOptimisticUpdate(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.Database.Driver = "sqlite"
+	cfg.Database.Source = ":memory:"
+	cfg.Server.Mode = "debug"
+
+	logger := util.NewLogger(cfg)
+	db, err := NewDB(cfg, logger, noop.NewTracerProvider())
+	assert.NoError(t, err)
+	defer func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	d, cleanup, err := NewData(db, logger)
+	assert.NoError(t, err)
+	defer cleanup()
+	repo := NewUserRepo(d, cache.NewLoader(cache.NewMemory(0, time.Minute), logger), logger)
+	ctx := context.Background()
+
+	u := &user.User{Username: "alice", Password: "hashedpassword"}
+	assert.NoError(t, repo.CreateUser(ctx, u))
+	assert.Equal(t, uint(1), u.Version, "new rows should start at version 1")
+
+	// 预热缓存，后续更新需要使缓存失效
+	_, err = repo.GetUserByID(ctx, u.ID)
+	assert.NoError(t, err)
+
+	// 使用正确的版本号更新成功，版本号自增
+	first := &user.User{ID: u.ID, Username: "alice2", Version: 1}
+	assert.NoError(t, repo.UpdateUser(ctx, first))
+	assert.Equal(t, uint(2), first.Version)
+
+	// 使用过期的版本号更新返回冲突错误
+	stale := &user.User{ID: u.ID, Username: "alice3", Version: 1}
+	err = repo.UpdateUser(ctx, stale)
+	assert.True(t, errors.Is(err, service.ErrConflict), "stale version should conflict")
+	var conflict *service.ConflictError
+	assert.True(t, errors.As(err, &conflict))
+	assert.Equal(t, "users", conflict.Resource)
+
+	found, err := repo.GetUserByID(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice2", found.Username, "stale update must not overwrite")
+	assert.Equal(t, uint(2), found.Version)
+
+	// 不存在的记录返回 ErrRecordNotFound
+	missing := &user.User{ID: 999, Username: "ghost", Version: 1}
+	assert.ErrorIs(t, repo.UpdateUser(ctx, missing), gorm.ErrRecordNotFound)
+}
+
+func TestUserRepoCacheBypassedInTx(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.Database.Driver = "sqlite"
+	cfg.Database.Source = ":memory:"
+	cfg.Server.Mode = "debug"
+
+	logger := util.NewLogger(cfg)
+	db, err := NewDB(cfg, logger, noop.NewTracerProvider())
+	assert.NoError(t, err)
+	defer func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	d, cleanup, err := NewData(db, logger)
+	assert.NoError(t, err)
+	defer cleanup()
+	store := cache.NewMemory(0, time.Minute)
+	repo := NewUserRepo(d, cache.NewLoader(store, logger), logger)
+	ctx := context.Background()
+
+	u := &user.User{Username: "alice", Password: "hashedpassword"}
+	assert.NoError(t, repo.CreateUser(ctx, u))
+
+	// 事务内读取本事务的写入，且不回填缓存；事务回滚后缓存中不应出现未提交的数据
+	rollback := errors.New("rollback")
+	err = d.InTx(ctx, func(ctx context.Context) error {
+		assert.NoError(t, d.DB(ctx).Model(&user.User{}).Where("id = ?", u.ID).Update("username", "uncommitted").Error)
+		found, err := repo.GetUserByID(ctx, u.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "uncommitted", found.Username)
+		return rollback
+	})
+	assert.ErrorIs(t, err, rollback)
+
+	_, err = store.Get(ctx, userCacheKey(u.ID))
+	assert.ErrorIs(t, err, cache.ErrMiss, "transactional reads must not populate the cache")
+	found, err := repo.GetUserByID(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", found.Username)
+
+	// 缓存值不含密码哈希
+	raw, err := store.Get(ctx, userCacheKey(u.ID))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(raw), "hashedpassword")
+}
@@ -4,11 +4,21 @@
 package di
 
 import (
+	"github.com/HoronLee/EchoHub/internal/cache"
 	"github.com/HoronLee/EchoHub/internal/config"
 	"github.com/HoronLee/EchoHub/internal/data"
+	"github.com/HoronLee/EchoHub/internal/errreport"
 	"github.com/HoronLee/EchoHub/internal/handler"
+	"github.com/HoronLee/EchoHub/internal/httpcache"
+	"github.com/HoronLee/EchoHub/internal/idempotency"
+	"github.com/HoronLee/EchoHub/internal/ipfilter"
+	"github.com/HoronLee/EchoHub/internal/maintenance"
+	"github.com/HoronLee/EchoHub/internal/metrics"
+	"github.com/HoronLee/EchoHub/internal/outbox"
+	"github.com/HoronLee/EchoHub/internal/ratelimit"
 	"github.com/HoronLee/EchoHub/internal/server"
 	"github.com/HoronLee/EchoHub/internal/service"
+	"github.com/HoronLee/EchoHub/internal/tracing"
 	"github.com/HoronLee/EchoHub/internal/util/log"
 	"github.com/HoronLee/EchoHub/internal/validator"
 	"github.com/google/wire"
@@ -18,11 +28,36 @@ import (
 func InitServer(cfg *config.AppConfig) (*server.HTTPServer, func(), error) {
 	wire.Build(
 		log.NewLogger,
+		log.NewAccessLogger,
+		tracing.ProviderSet,
+		cache.ProviderSet,
+		httpcache.ProviderSet,
 		data.ProviderSet,
 		validator.NewValidator,
 		service.ProviderSet,
 		handler.ProviderSet,
+		outbox.ProviderSet,
+		ratelimit.ProviderSet,
+		idempotency.ProviderSet,
+		ipfilter.ProviderSet,
+		maintenance.ProviderSet,
+		metrics.ProviderSet,
+		errreport.ProviderSet,
 		server.ProviderSet,
 	)
 	return nil, nil, nil
 }
+
+// InitTransfer 初始化批量导入导出服务（供 export/import 命令使用）
+func InitTransfer(cfg *config.AppConfig) (*service.TransferService, func(), error) {
+	wire.Build(
+		log.NewCLILogger,
+		tracing.ProviderSet,
+		cache.ProviderSet,
+		httpcache.ProviderSet,
+		data.ProviderSet,
+		validator.NewValidator,
+		service.ProviderSet,
+	)
+	return nil, nil, nil
+}
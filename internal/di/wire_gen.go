@@ -7,11 +7,21 @@
 package di
 
 import (
+	"github.com/HoronLee/EchoHub/internal/cache"
 	"github.com/HoronLee/EchoHub/internal/config"
 	"github.com/HoronLee/EchoHub/internal/data"
+	"github.com/HoronLee/EchoHub/internal/errreport"
 	"github.com/HoronLee/EchoHub/internal/handler"
+	"github.com/HoronLee/EchoHub/internal/httpcache"
+	"github.com/HoronLee/EchoHub/internal/idempotency"
+	"github.com/HoronLee/EchoHub/internal/ipfilter"
+	"github.com/HoronLee/EchoHub/internal/maintenance"
+	"github.com/HoronLee/EchoHub/internal/metrics"
+	"github.com/HoronLee/EchoHub/internal/outbox"
+	"github.com/HoronLee/EchoHub/internal/ratelimit"
 	"github.com/HoronLee/EchoHub/internal/server"
 	"github.com/HoronLee/EchoHub/internal/service"
+	"github.com/HoronLee/EchoHub/internal/tracing"
 	"github.com/HoronLee/EchoHub/internal/util/log"
 	"github.com/HoronLee/EchoHub/internal/validator"
 )
@@ -21,24 +31,160 @@ import (
 // InitServer 初始化服务器
 func InitServer(cfg *config.AppConfig) (*server.HTTPServer, func(), error) {
 	logger := log.NewLogger(cfg)
-	db, err := data.NewDB(cfg, logger)
+	tracerProvider, cleanup, err := tracing.NewTracerProvider(cfg, logger)
 	if err != nil {
 		return nil, nil, err
 	}
-	dataData, cleanup, err := data.NewData(db, logger)
+	db, err := data.NewDB(cfg, logger, tracerProvider)
 	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	dataData, cleanup2, err := data.NewData(db, logger)
+	if err != nil {
+		cleanup()
 		return nil, nil, err
 	}
 	helloWorldRepo := data.NewHelloWorldRepo(dataData, logger)
-	helloWorldService := service.NewHelloWorldService(helloWorldRepo)
-	helloWorldHandler := handler.NewHelloWorldHandler(helloWorldService)
-	userRepo := data.NewUserRepo(dataData, logger)
-	userService := service.NewUserService(userRepo)
+	cacheCache, cleanup3, err := cache.NewCache(cfg, logger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	responseCache := httpcache.NewResponseCache(cfg, cacheCache, logger)
+	responseInvalidator := httpcache.NewResponseInvalidator(responseCache)
+	helloWorldService := service.NewHelloWorldService(helloWorldRepo, responseInvalidator)
 	validatorValidator := validator.NewValidator(cfg)
+	helloWorldHandler := handler.NewHelloWorldHandler(helloWorldService, validatorValidator)
+	loader := cache.NewLoader(cacheCache, logger)
+	userRepo := data.NewUserRepo(dataData, loader, logger)
+	transaction := data.NewTransaction(dataData)
+	outboxRepo := data.NewOutboxRepo(dataData, logger)
+	userService := service.NewUserService(userRepo, transaction, outboxRepo)
 	userHandler := handler.NewUserHandler(userService, validatorValidator)
-	handlers := handler.NewHandlers(helloWorldHandler, userHandler)
-	httpServer := server.NewHTTPServer(cfg, handlers, db, logger, validatorValidator)
+	manager, err := maintenance.NewManager(cfg, logger)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	maintenanceHandler := handler.NewMaintenanceHandler(manager, validatorValidator)
+	filter, err := ipfilter.NewFilter(cfg, logger)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	handlers := handler.NewHandlers(helloWorldHandler, userHandler, maintenanceHandler, responseCache, filter, userService)
+	accessLogger, cleanup4, err := log.NewAccessLogger(cfg, logger)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	store := data.NewOutboxStore(dataData, logger)
+	publisher, cleanup5, err := outbox.NewPublisher(cfg, logger)
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	relay := outbox.NewRelay(cfg, store, publisher, logger)
+	keyRotator := data.NewKeyRotator(cfg, db, logger)
+	ratelimitStore, cleanup6, err := ratelimit.NewStore(cfg, logger)
+	if err != nil {
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	limiter, err := ratelimit.NewLimiter(cfg, ratelimitStore, logger)
+	if err != nil {
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	idempotencyStore, cleanup7, err := idempotency.NewStore(cfg, logger)
+	if err != nil {
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	registry := metrics.NewRegistry(cfg)
+	reporter, cleanup8, err := errreport.NewReporter(cfg, logger)
+	if err != nil {
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	httpServer := server.NewHTTPServer(cfg, handlers, db, logger, accessLogger, validatorValidator, relay, keyRotator, limiter, idempotencyStore, filter, registry, tracerProvider, reporter, manager)
 	return httpServer, func() {
+		cleanup8()
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+	}, nil
+}
+
+// InitTransfer 初始化批量导入导出服务（供 export/import 命令使用）
+func InitTransfer(cfg *config.AppConfig) (*service.TransferService, func(), error) {
+	logger := log.NewCLILogger(cfg)
+	tracerProvider, cleanup, err := tracing.NewTracerProvider(cfg, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := data.NewDB(cfg, logger, tracerProvider)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	dataData, cleanup2, err := data.NewData(db, logger)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	cacheCache, cleanup3, err := cache.NewCache(cfg, logger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	loader := cache.NewLoader(cacheCache, logger)
+	userRepo := data.NewUserRepo(dataData, loader, logger)
+	helloWorldRepo := data.NewHelloWorldRepo(dataData, logger)
+	transaction := data.NewTransaction(dataData)
+	responseCache := httpcache.NewResponseCache(cfg, cacheCache, logger)
+	responseInvalidator := httpcache.NewResponseInvalidator(responseCache)
+	validatorValidator := validator.NewValidator(cfg)
+	transferService := service.NewTransferService(userRepo, helloWorldRepo, transaction, responseInvalidator, validatorValidator)
+	return transferService, func() {
+		cleanup3()
+		cleanup2()
 		cleanup()
 	}, nil
 }
@@ -0,0 +1,212 @@
+// Package errcode 定义带稳定业务错误码的错误类型与错误码目录
+//
+// 通用错误码与 HTTP 状态码一致（如 404、409），同时作为错误类别；业务错误码为五位数，
+// 前两位为模块、后三位为序号（如 20001 用户名已存在）。错误消息按语言本地化，
+// 只有 Details 会出现在响应中，Wrap 附加的底层错误仅用于日志。
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 支持的语言，与 validator 保持一致
+const (
+	LocaleZhCN = "zh_CN"
+	LocaleEnUS = "en_US"
+)
+
+// Messages 语言 -> 错误消息
+type Messages map[string]string
+
+// Error 业务错误
+type Error struct {
+	Code     int // 稳定的业务错误码，客户端据此判断错误类型
+	Status   int // 对应的 HTTP 状态码
+	messages Messages
+	details  map[string]any // 可公开的细节，随响应返回
+	cause    error          // 底层错误，仅用于日志
+}
+
+var (
+	mu       sync.RWMutex
+	catalog  = make(map[int]*Error)
+	fallback = LocaleZhCN
+)
+
+// New 定义错误码并登记到目录，错误码重复时 panic（应在包初始化阶段定义）
+func New(code, status int, messages Messages) *Error {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := catalog[code]; ok {
+		panic(fmt.Sprintf("errcode: duplicate code %d", code))
+	}
+	e := &Error{Code: code, Status: status, messages: messages}
+	catalog[code] = e
+	return e
+}
+
+// 通用错误码，与 HTTP 状态码一致
+var (
+	BadRequest            = New(400, http.StatusBadRequest, Messages{LocaleZhCN: "请求参数错误", LocaleEnUS: "Bad request"})
+	Unauthorized          = New(401, http.StatusUnauthorized, Messages{LocaleZhCN: "未认证", LocaleEnUS: "Unauthorized"})
+	Forbidden             = New(403, http.StatusForbidden, Messages{LocaleZhCN: "无权访问", LocaleEnUS: "Forbidden"})
+	NotFound              = New(404, http.StatusNotFound, Messages{LocaleZhCN: "资源不存在", LocaleEnUS: "Resource not found"})
+	Conflict              = New(409, http.StatusConflict, Messages{LocaleZhCN: "资源冲突", LocaleEnUS: "Resource conflict"})
+	RequestEntityTooLarge = New(413, http.StatusRequestEntityTooLarge, Messages{LocaleZhCN: "请求体过大", LocaleEnUS: "Request body too large"})
+	UnsupportedMediaType  = New(415, http.StatusUnsupportedMediaType, Messages{LocaleZhCN: "不支持的请求内容类型或编码", LocaleEnUS: "Unsupported media type"})
+	Validation            = New(422, http.StatusUnprocessableEntity, Messages{LocaleZhCN: "参数校验失败", LocaleEnUS: "Validation failed"})
+	PreconditionRequired  = New(428, http.StatusPreconditionRequired, Messages{LocaleZhCN: "缺少前置条件", LocaleEnUS: "Precondition required"})
+	TooManyRequests       = New(429, http.StatusTooManyRequests, Messages{LocaleZhCN: "请求过于频繁", LocaleEnUS: "Too many requests"})
+	Internal              = New(500, http.StatusInternalServerError, Messages{LocaleZhCN: "服务器内部错误", LocaleEnUS: "Internal server error"})
+	Unavailable           = New(503, http.StatusServiceUnavailable, Messages{LocaleZhCN: "服务暂不可用", LocaleEnUS: "Service unavailable"})
+)
+
+// Error 实现 error 接口，返回英文消息及底层错误，用于日志
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("[%d] %s", e.Code, e.Message(LocaleEnUS))
+	if e.cause != nil {
+		msg += ": " + e.cause.Error()
+	}
+	return msg
+}
+
+// Unwrap 返回底层错误
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is 错误码相同时匹配；目标为通用错误码时，相同 HTTP 状态码的业务错误也匹配，
+// 例如 errors.Is(ErrUserNotFound, errcode.NotFound) 成立
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return t.Code == e.Code || (t.Generic() && t.Status == e.Status)
+}
+
+// Generic 是否为通用错误码
+func (e *Error) Generic() bool {
+	return e.Code == e.Status
+}
+
+// Wrap 返回附加底层错误的副本，底层错误不会出现在响应中
+func (e *Error) Wrap(cause error) *Error {
+	c := *e
+	c.cause = cause
+	return &c
+}
+
+// WithDetail 返回附加公开细节的副本，细节会原样出现在响应中，不要放入内部信息
+func (e *Error) WithDetail(key string, value any) *Error {
+	c := *e
+	c.details = make(map[string]any, len(e.details)+1)
+	for k, v := range e.details {
+		c.details[k] = v
+	}
+	c.details[key] = value
+	return &c
+}
+
+// Details 返回公开细节
+func (e *Error) Details() map[string]any {
+	return e.details
+}
+
+// Message 返回指定语言的消息，缺少该语言时依次回退到默认语言与英文
+func (e *Error) Message(locale string) string {
+	if msg, ok := e.messages[locale]; ok {
+		return msg
+	}
+	if msg, ok := e.messages[DefaultLocale()]; ok {
+		return msg
+	}
+	if msg, ok := e.messages[LocaleEnUS]; ok {
+		return msg
+	}
+	return http.StatusText(e.Status)
+}
+
+// From 从错误链中取出业务错误
+func From(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// Lookup 按错误码查找目录中的定义
+func Lookup(code int) (*Error, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	e, ok := catalog[code]
+	return e, ok
+}
+
+// Catalog 返回按错误码排序的全部定义，用于生成文档
+func Catalog() []*Error {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := make([]*Error, 0, len(catalog))
+	for _, e := range catalog {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// SetDefaultLocale 设置默认语言（server.locale），请求未指定语言时使用
+func SetDefaultLocale(locale string) {
+	if locale == "" {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	fallback = locale
+}
+
+// DefaultLocale 返回默认语言
+func DefaultLocale() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return fallback
+}
+
+// NegotiateLocale 按 Accept-Language 选择支持的语言（q 值优先，相同时按出现顺序），无匹配时返回默认语言
+// 只比较主语言标签，如 zh-TW 视为 zh_CN
+func NegotiateLocale(acceptLanguage string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if k, v, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(k) == "q" {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				q = f
+			}
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		var locale string
+		switch primary {
+		case "zh":
+			locale = LocaleZhCN
+		case "en":
+			locale = LocaleEnUS
+		default:
+			continue
+		}
+		if q > bestQ {
+			best, bestQ = locale, q
+		}
+	}
+	if best == "" {
+		return DefaultLocale()
+	}
+	return best
+}
@@ -0,0 +1,87 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTestItemNotFound = New(99001, http.StatusNotFound, Messages{
+	LocaleZhCN: "条目不存在",
+	LocaleEnUS: "Item not found",
+})
+
+func TestErrorIs(t *testing.T) {
+	wrapped := fmt.Errorf("get item 7: %w", errTestItemNotFound.Wrap(errors.New("record not found")))
+
+	assert.ErrorIs(t, wrapped, errTestItemNotFound)
+	assert.ErrorIs(t, wrapped, NotFound, "business error should match the generic code with the same status")
+	assert.NotErrorIs(t, wrapped, Conflict)
+	assert.NotErrorIs(t, NotFound, errTestItemNotFound, "generic error must not match a specific business code")
+
+	e, ok := From(wrapped)
+	require.True(t, ok)
+	assert.Equal(t, 99001, e.Code)
+	assert.Equal(t, http.StatusNotFound, e.Status)
+	assert.Equal(t, "[99001] Item not found: record not found", e.Error())
+}
+
+func TestWrapAndDetailCopy(t *testing.T) {
+	a := errTestItemNotFound.WithDetail("id", "abc")
+	b := a.WithDetail("field", "name")
+
+	assert.Nil(t, errTestItemNotFound.Details(), "definition must stay untouched")
+	assert.Equal(t, map[string]any{"id": "abc"}, a.Details())
+	assert.Equal(t, map[string]any{"id": "abc", "field": "name"}, b.Details())
+
+	w := errTestItemNotFound.Wrap(errors.New("boom"))
+	assert.Nil(t, errTestItemNotFound.Unwrap())
+	assert.EqualError(t, w.Unwrap(), "boom")
+}
+
+func TestMessageFallback(t *testing.T) {
+	assert.Equal(t, "条目不存在", errTestItemNotFound.Message(LocaleZhCN))
+	assert.Equal(t, "Item not found", errTestItemNotFound.Message(LocaleEnUS))
+	assert.Equal(t, "条目不存在", errTestItemNotFound.Message("ja_JP"), "unknown locale falls back to the default locale")
+
+	onlyStatus := &Error{Code: 99002, Status: http.StatusTeapot}
+	assert.Equal(t, http.StatusText(http.StatusTeapot), onlyStatus.Message(LocaleZhCN))
+}
+
+func TestNegotiateLocale(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: LocaleZhCN},
+		{header: "en-US,en;q=0.9", want: LocaleEnUS},
+		{header: "zh-TW", want: LocaleZhCN},
+		{header: "fr-FR, en;q=0.5, zh;q=0.8", want: LocaleZhCN},
+		{header: "zh;q=0.3, EN", want: LocaleEnUS},
+		{header: "de, fr", want: LocaleZhCN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.want, NegotiateLocale(tt.header))
+		})
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	assert.PanicsWithValue(t, "errcode: duplicate code 99001", func() {
+		New(99001, http.StatusNotFound, nil)
+	})
+
+	e, ok := Lookup(404)
+	require.True(t, ok)
+	assert.Same(t, NotFound, e)
+
+	list := Catalog()
+	for i := 1; i < len(list); i++ {
+		assert.Less(t, list[i-1].Code, list[i].Code)
+	}
+}
@@ -0,0 +1,264 @@
+// Package errreport 提供错误报告：panic、未处理的 5xx 错误与后台任务失败通过统一的 Reporter 上报
+//
+// 默认实现 Client 补全版本、环境、请求ID等信息，按指纹去重后异步写入各个 Sink。
+// 内置 Sink 有 Sentry 协议客户端（兼容 Sentry 及 GlitchTip 等自建服务）与本地 JSONL 崩溃记录文件。
+package errreport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	commonModel "github.com/HoronLee/EchoHub/internal/model/common"
+	"github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/HoronLee/EchoHub/internal/util/requestid"
+	"github.com/google/uuid"
+	"github.com/google/wire"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// EnvSentryDSN 覆盖 error_reporting.sentry.dsn 的环境变量
+const EnvSentryDSN = "SENTRY_DSN"
+
+// ProviderSet is errreport providers.
+var ProviderSet = wire.NewSet(NewReporter)
+
+// Reporter 错误报告接口，Report 不阻塞调用方
+type Reporter interface {
+	Report(ctx context.Context, e *Event)
+}
+
+// Sink 事件的最终去向
+type Sink interface {
+	Send(ctx context.Context, e *Event) error
+}
+
+// Nop 不做任何事的 Reporter，未启用错误报告时使用
+var Nop Reporter = nopReporter{}
+
+type nopReporter struct{}
+
+func (nopReporter) Report(context.Context, *Event) {}
+
+var (
+	defaultReporter Reporter = Nop
+	mu              sync.RWMutex
+)
+
+// Default 获取全局 Reporter（用于 response 与后台任务等无法注入依赖的位置），未初始化时返回 Nop
+func Default() Reporter {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultReporter
+}
+
+// SetDefault 设置全局 Reporter（由 NewReporter 调用）
+func SetDefault(r Reporter) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultReporter = r
+}
+
+// NewReporter 根据配置创建 Reporter 并设置为全局实例，未启用时返回 Nop
+func NewReporter(cfg *config.AppConfig, logger *log.Logger) (Reporter, func(), error) {
+	rc := cfg.ErrorReporting
+	if !rc.Enabled {
+		SetDefault(Nop)
+		return Nop, func() {}, nil
+	}
+
+	dsn := rc.Sentry.DSN
+	if v := os.Getenv(EnvSentryDSN); v != "" {
+		dsn = v
+	}
+	var sinks []Sink
+	if dsn != "" {
+		s, err := NewSentryClient(dsn, rc.Sentry.Timeout)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error_reporting sentry: %w", err)
+		}
+		sinks = append(sinks, s)
+	}
+	if rc.File.Path != "" {
+		sinks = append(sinks, NewFileStore(rc.File.Path, rc.File.MaxSize, rc.File.MaxBackups))
+	}
+
+	env := rc.Environment
+	if env == "" {
+		env = cfg.Server.Mode
+	}
+	c := NewClient(Options{
+		Environment: env,
+		DedupWindow: rc.DedupWindow,
+		QueueSize:   rc.QueueSize,
+	}, logger, sinks...)
+	SetDefault(c)
+
+	logger.Info("Error reporting initialized",
+		zap.Bool("sentry", dsn != ""),
+		zap.String("file", rc.File.Path),
+		zap.String("environment", env))
+
+	cleanup := func() {
+		SetDefault(Nop)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := c.Close(ctx); err != nil {
+			logger.Warn("Failed to flush error reports", zap.Error(err))
+		}
+	}
+	return c, cleanup, nil
+}
+
+// Options Client 选项
+type Options struct {
+	Environment string        // 环境名
+	DedupWindow time.Duration // 相同指纹的事件在窗口内只发送一次，0 表示不去重
+	QueueSize   int           // 待发送队列长度，队列满时丢弃新事件
+}
+
+// seen 指纹的去重状态
+type seen struct {
+	last       time.Time
+	suppressed int
+}
+
+// Client 默认的 Reporter 实现
+type Client struct {
+	sinks      []Sink
+	logger     *log.Logger
+	env        string
+	serverName string
+	window     time.Duration
+
+	mu     sync.Mutex
+	seen   map[string]*seen
+	queue  chan *Event
+	closed bool
+	done   chan struct{}
+}
+
+// NewClient 创建 Client 并启动发送协程
+func NewClient(opts Options, logger *log.Logger, sinks ...Sink) *Client {
+	size := opts.QueueSize
+	if size <= 0 {
+		size = 256
+	}
+	hostname, _ := os.Hostname()
+	c := &Client{
+		sinks:      sinks,
+		logger:     logger,
+		env:        opts.Environment,
+		serverName: hostname,
+		window:     opts.DedupWindow,
+		seen:       make(map[string]*seen),
+		queue:      make(chan *Event, size),
+		done:       make(chan struct{}),
+	}
+	go c.loop()
+	return c
+}
+
+// Report 补全事件信息并加入发送队列；去重窗口内的重复事件只计数，随下一次发送一并报告
+func (c *Client) Report(ctx context.Context, e *Event) {
+	if e.ID == "" {
+		e.ID = strings.ReplaceAll(uuid.NewString(), "-", "") // Sentry 要求 32 位十六进制
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now().UTC()
+	}
+	e.Release = commonModel.Version
+	e.Commit = commonModel.GitCommit
+	e.Environment = c.env
+	e.ServerName = c.serverName
+	if e.RequestID == "" {
+		e.RequestID = requestid.FromContext(ctx)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		e.TraceID = sc.TraceID().String()
+	}
+	if e.Fingerprint == "" {
+		e.Fingerprint = fingerprint(e)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || !c.admit(e) {
+		return
+	}
+	select {
+	case c.queue <- e:
+	default:
+		c.logger.Warn("Error report queue is full, dropping event",
+			zap.String("fingerprint", e.Fingerprint), zap.String("message", e.Message))
+	}
+}
+
+// admit 去重判断，调用方持有锁
+func (c *Client) admit(e *Event) bool {
+	if c.window <= 0 {
+		return true
+	}
+	now := time.Now()
+	if s, ok := c.seen[e.Fingerprint]; ok {
+		if now.Sub(s.last) < c.window {
+			s.suppressed++
+			return false
+		}
+		e.Suppressed = s.suppressed
+	}
+	// 清理过期指纹，避免长期运行时无限增长
+	if len(c.seen) >= 1024 {
+		for fp, s := range c.seen {
+			if now.Sub(s.last) >= c.window {
+				delete(c.seen, fp)
+			}
+		}
+	}
+	c.seen[e.Fingerprint] = &seen{last: now}
+	return true
+}
+
+func (c *Client) loop() {
+	defer close(c.done)
+	for e := range c.queue {
+		for _, s := range c.sinks {
+			if err := s.Send(context.Background(), e); err != nil {
+				c.logger.Warn("Failed to send error report",
+					zap.String("sink", fmt.Sprintf("%T", s)),
+					zap.String("event_id", e.ID),
+					zap.Error(err))
+			}
+		}
+	}
+}
+
+// Close 停止接收事件，发送完队列中的事件后关闭各个 Sink
+func (c *Client) Close(ctx context.Context) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.queue)
+	c.mu.Unlock()
+
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	for _, s := range c.sinks {
+		if closer, ok := s.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package errreport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	commonModel "github.com/HoronLee/EchoHub/internal/model/common"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/HoronLee/EchoHub/internal/util/requestid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySink 记录收到的事件
+type memorySink struct {
+	mu     sync.Mutex
+	events []*Event
+}
+
+func (s *memorySink) Send(_ context.Context, e *Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+	return nil
+}
+
+func newTestLogger() *util.Logger {
+	cfg := &config.AppConfig{}
+	cfg.Server.Mode = "debug"
+	return util.NewLogger(cfg)
+}
+
+func TestClientReport(t *testing.T) {
+	sink := &memorySink{}
+	c := NewClient(Options{Environment: "test", DedupWindow: time.Hour}, newTestLogger(), sink)
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	for i := 0; i < 3; i++ {
+		c.Report(ctx, NewJobEvent("outbox_relay", fmt.Errorf("publish event %d: %w", i, errors.New("broker down"))))
+	}
+	c.Report(ctx, NewJobEvent("key_rotation", errors.New("broker down")))
+	require.NoError(t, c.Close(context.Background()))
+
+	require.Len(t, sink.events, 2, "same job and message differing only in digits should be deduplicated")
+	e := sink.events[0]
+	assert.Len(t, e.ID, 32)
+	assert.Equal(t, "req-1", e.RequestID)
+	assert.Equal(t, commonModel.Version, e.Release)
+	assert.Equal(t, commonModel.GitCommit, e.Commit)
+	assert.Equal(t, "test", e.Environment)
+	assert.Equal(t, SourceJob, e.Source)
+	assert.Equal(t, "*errors.errorString", e.Type, "type should come from the innermost error")
+	assert.Equal(t, "outbox_relay", e.Tags["job"])
+	assert.NotEmpty(t, e.Stack)
+	assert.NotEqual(t, e.Fingerprint, sink.events[1].Fingerprint)
+
+	// 关闭后不再接收事件
+	c.Report(ctx, NewJobEvent("outbox_relay", errors.New("late")))
+	assert.Len(t, sink.events, 2)
+}
+
+func TestClientSuppressedCount(t *testing.T) {
+	sink := &memorySink{}
+	c := NewClient(Options{DedupWindow: 50 * time.Millisecond}, newTestLogger(), sink)
+
+	for i := 0; i < 4; i++ {
+		c.Report(context.Background(), NewJobEvent("job", errors.New("boom")))
+	}
+	time.Sleep(60 * time.Millisecond)
+	c.Report(context.Background(), NewJobEvent("job", errors.New("boom")))
+	require.NoError(t, c.Close(context.Background()))
+
+	require.Len(t, sink.events, 2)
+	assert.Equal(t, 0, sink.events[0].Suppressed)
+	assert.Equal(t, 3, sink.events[1].Suppressed)
+}
+
+func explode(items []int) int {
+	return items[3]
+}
+
+func recoverPanic() (e *Event) {
+	defer func() {
+		e = NewPanicEvent(recover())
+	}()
+	explode(nil)
+	return nil
+}
+
+func TestPanicEvent(t *testing.T) {
+	a, b := recoverPanic(), recoverPanic()
+
+	assert.Equal(t, LevelFatal, a.Level)
+	assert.Equal(t, SourcePanic, a.Source)
+	assert.Equal(t, "runtime.boundsError", a.Type)
+	assert.Contains(t, a.Message, "index out of range")
+
+	var top Frame
+	for _, f := range a.Stack {
+		if f.InApp {
+			top = f
+			break
+		}
+	}
+	assert.True(t, strings.HasSuffix(top.Function, "errreport.explode"), "stack should start at the panic site, got %s", top.Function)
+
+	assert.Equal(t, fingerprint(a), fingerprint(b))
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crash", "crash.jsonl")
+	store := NewFileStore(path, 1, 1)
+	c := NewClient(Options{}, newTestLogger(), store)
+
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "file should be created lazily")
+
+	c.Report(context.Background(), NewJobEvent("a", errors.New("first")))
+	c.Report(context.Background(), NewJobEvent("b", errors.New("second")))
+	require.NoError(t, c.Close(context.Background()))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var messages []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		messages = append(messages, e.Message)
+	}
+	assert.Equal(t, []string{"first", "second"}, messages)
+}
+
+func TestNewReporterDisabled(t *testing.T) {
+	cfg := &config.AppConfig{}
+	r, cleanup, err := NewReporter(cfg, newTestLogger())
+	require.NoError(t, err)
+	defer cleanup()
+	assert.Equal(t, Nop, r)
+	assert.Equal(t, Nop, Default())
+}
@@ -0,0 +1,208 @@
+package errreport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// modulePrefix 本项目函数名前缀，用于区分业务代码与第三方代码的调用栈
+const modulePrefix = "github.com/HoronLee/EchoHub/"
+
+// maxFrames 单个事件最多记录的调用栈层数
+const maxFrames = 64
+
+// Level 事件级别
+type Level string
+
+const (
+	LevelError Level = "error"
+	LevelFatal Level = "fatal" // 请求处理中的 panic
+)
+
+// 事件来源
+const (
+	SourcePanic = "panic" // Recovery 捕获的 panic
+	SourceHTTP  = "http"  // 未处理的 5xx 错误响应
+	SourceJob   = "job"   // 后台任务失败
+)
+
+// Event 一次错误报告
+type Event struct {
+	ID          string            `json:"id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Level       Level             `json:"level"`
+	Source      string            `json:"source"`
+	Type        string            `json:"type"` // 错误链最内层的类型，如 *mysql.MySQLError、runtime.boundsError
+	Message     string            `json:"message"`
+	Stack       []Frame           `json:"stack,omitempty"` // 调用栈，最内层在前
+	Request     *Request          `json:"request,omitempty"`
+	RequestID   string            `json:"request_id,omitempty"`
+	TraceID     string            `json:"trace_id,omitempty"`
+	UserID      string            `json:"user_id,omitempty"`
+	Release     string            `json:"release"`
+	Commit      string            `json:"commit"`
+	Environment string            `json:"environment,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Fingerprint string            `json:"fingerprint"`
+	Suppressed  int               `json:"suppressed,omitempty"` // 上次上报后在去重窗口内被丢弃的相同错误数量
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// Frame 调用栈帧
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	InApp    bool   `json:"in_app"`
+}
+
+// Request 请求元数据；查询串与请求头可能包含凭据，不记录
+type Request struct {
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Route     string `json:"route,omitempty"`
+	IP        string `json:"ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+// NewEvent 根据错误创建事件，调用栈从调用方开始记录
+func NewEvent(err error) *Event {
+	return &Event{
+		Level:   LevelError,
+		Source:  SourceHTTP,
+		Type:    errorType(err),
+		Message: err.Error(),
+		Stack:   callers(3),
+	}
+}
+
+// NewJobEvent 创建后台任务失败事件，job 为任务名，参与指纹计算
+func NewJobEvent(job string, err error) *Event {
+	e := &Event{
+		Level:   LevelError,
+		Source:  SourceJob,
+		Type:    errorType(err),
+		Message: err.Error(),
+		Stack:   callers(3),
+	}
+	return e.WithTag("job", job)
+}
+
+// NewPanicEvent 根据 recover() 的返回值创建事件，必须在执行 recover 的 defer 函数中调用，
+// 调用栈从 panic 发生处开始记录
+func NewPanicEvent(recovered any) *Event {
+	e := &Event{Level: LevelFatal, Source: SourcePanic}
+	if err, ok := recovered.(error); ok {
+		e.Type = errorType(err)
+		e.Message = err.Error()
+	} else {
+		e.Type = fmt.Sprintf("%T", recovered)
+		e.Message = fmt.Sprint(recovered)
+	}
+
+	stack := callers(3)
+	for i, f := range stack {
+		if f.Function == "runtime.gopanic" {
+			stack = stack[i+1:]
+			break
+		}
+	}
+	e.Stack = stack
+	return e
+}
+
+// WithRequest 附加请求元数据与当前用户
+func (e *Event) WithRequest(c echo.Context) *Event {
+	req := c.Request()
+	e.Request = &Request{
+		Method:    req.Method,
+		Path:      req.URL.Path,
+		Route:     c.Path(),
+		IP:        c.RealIP(),
+		UserAgent: req.UserAgent(),
+	}
+	if userID, ok := c.Get("user_id").(string); ok {
+		e.UserID = userID
+	}
+	return e
+}
+
+// WithTag 附加标签
+func (e *Event) WithTag(key, value string) *Event {
+	if e.Tags == nil {
+		e.Tags = make(map[string]string)
+	}
+	e.Tags[key] = value
+	return e
+}
+
+// errorType 返回错误链最内层的类型，包装类型（如 *fmt.wrapError）不利于归类
+func errorType(err error) string {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return fmt.Sprintf("%T", err)
+		}
+		err = next
+	}
+}
+
+// callers 采集调用栈，skip 含义同 runtime.Callers
+func callers(skip int) []Frame {
+	pcs := make([]uintptr, maxFrames)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	stack := make([]Frame, 0, n)
+	for {
+		f, more := frames.Next()
+		stack = append(stack, Frame{
+			Function: f.Function,
+			File:     f.File,
+			Line:     f.Line,
+			InApp:    strings.HasPrefix(f.Function, modulePrefix),
+		})
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// fingerprint 计算事件指纹，相同指纹视为同一问题
+// panic 按错误类型与业务代码调用栈（不含行号）归类；其他错误没有有意义的调用栈，
+// 按来源、路由或任务名与去除数字后的消息归类，避免消息中的 ID 使同一问题分散
+func fingerprint(e *Event) string {
+	parts := []string{e.Source, e.Type}
+	if e.Source == SourcePanic {
+		n := 0
+		for _, f := range e.Stack {
+			if f.InApp && n < 5 {
+				parts = append(parts, f.Function)
+				n++
+			}
+		}
+	} else {
+		if e.Request != nil {
+			parts = append(parts, e.Request.Method+" "+e.Request.Route)
+		}
+		parts = append(parts, e.Tags["job"], stripDigits(e.Message))
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:16])
+}
+
+func stripDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return '#'
+		}
+		return r
+	}, s)
+}
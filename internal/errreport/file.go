@@ -0,0 +1,46 @@
+package errreport
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// FileStore 将事件逐行写入本地 JSONL 文件，文件在首次写入时创建并按大小滚动
+// 错误追踪服务不可用或未配置时，崩溃现场仍可在本地找到
+type FileStore struct {
+	mu     sync.Mutex
+	writer *lumberjack.Logger
+}
+
+// NewFileStore 创建本地崩溃记录，maxSize 单位为 MB
+func NewFileStore(path string, maxSize, maxBackups int) *FileStore {
+	return &FileStore{writer: &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+	}}
+}
+
+// Send 追加一行事件记录
+func (f *FileStore) Send(_ context.Context, e *Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err = f.writer.Write(line)
+	return err
+}
+
+// Close 关闭文件
+func (f *FileStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.writer.Close()
+}
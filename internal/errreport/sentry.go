@@ -0,0 +1,218 @@
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	commonModel "github.com/HoronLee/EchoHub/internal/model/common"
+)
+
+// sentryClientName 上报时使用的客户端标识
+const sentryClientName = "echohub"
+
+// SentryClient 使用 Sentry store 协议上报事件
+// DSN 格式为 {scheme}://{public_key}[:{secret_key}]@{host}[/{path}]/{project_id}，
+// 收到 429 时在 Retry-After 指定的时间内不再发送
+type SentryClient struct {
+	endpoint string
+	auth     string
+	client   *http.Client
+
+	mu           sync.Mutex
+	blockedUntil time.Time
+}
+
+// NewSentryClient 解析 DSN 并创建客户端，timeout 为 0 时使用 5 秒
+func NewSentryClient(dsn string, timeout time.Duration) (*SentryClient, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dsn: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid dsn scheme %q", u.Scheme)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("dsn requires a public key")
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	i := strings.LastIndexByte(path, '/')
+	project := path[i+1:]
+	if project == "" {
+		return nil, fmt.Errorf("dsn requires a project id")
+	}
+
+	auth := fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s/%s, sentry_key=%s",
+		sentryClientName, commonModel.Version, u.User.Username())
+	if secret, ok := u.User.Password(); ok && secret != "" {
+		auth += ", sentry_secret=" + secret
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &SentryClient{
+		endpoint: fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, path[:i], project),
+		auth:     auth,
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Send 发送事件
+func (s *SentryClient) Send(ctx context.Context, e *Event) error {
+	s.mu.Lock()
+	blocked := time.Now().Before(s.blockedUntil)
+	s.mu.Unlock()
+	if blocked {
+		return fmt.Errorf("rate limited by sentry, dropping event %s", e.ID)
+	}
+
+	body, err := json.Marshal(toSentryEvent(e))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		retry := 60 * time.Second
+		if d, err := time.ParseDuration(resp.Header.Get("Retry-After") + "s"); err == nil && d > 0 {
+			retry = d
+		}
+		s.mu.Lock()
+		s.blockedUntil = time.Now().Add(retry)
+		s.mu.Unlock()
+		return fmt.Errorf("rate limited by sentry for %s", retry)
+	case resp.StatusCode >= http.StatusBadRequest:
+		return fmt.Errorf("sentry responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sentryEvent Sentry 事件负载，只包含用到的字段
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       Level             `json:"level"`
+	Platform    string            `json:"platform"`
+	Logger      string            `json:"logger"`
+	Release     string            `json:"release"`
+	Dist        string            `json:"dist,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	Exception   sentryExceptions  `json:"exception"`
+	Request     *sentryRequest    `json:"request,omitempty"`
+	User        *sentryUser       `json:"user,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]any    `json:"extra,omitempty"`
+	Fingerprint []string          `json:"fingerprint"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type       string            `json:"type"`
+	Value      string            `json:"value"`
+	Stacktrace *sentryStacktrace `json:"stacktrace,omitempty"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	Filename string `json:"filename"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+type sentryRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+type sentryUser struct {
+	ID        string `json:"id,omitempty"`
+	IPAddress string `json:"ip_address,omitempty"`
+}
+
+func toSentryEvent(e *Event) sentryEvent {
+	se := sentryEvent{
+		EventID:     e.ID,
+		Timestamp:   e.Timestamp.UTC().Format(time.RFC3339Nano),
+		Level:       e.Level,
+		Platform:    "go",
+		Logger:      e.Source,
+		Release:     sentryClientName + "@" + e.Release,
+		Dist:        e.Commit,
+		Environment: e.Environment,
+		ServerName:  e.ServerName,
+		Fingerprint: []string{e.Fingerprint},
+		Tags:        map[string]string{"source": e.Source},
+	}
+	for k, v := range e.Tags {
+		se.Tags[k] = v
+	}
+	if e.RequestID != "" {
+		se.Tags["request_id"] = e.RequestID
+	}
+	if e.TraceID != "" {
+		se.Tags["trace_id"] = e.TraceID
+	}
+	if e.Suppressed > 0 {
+		se.Extra = map[string]any{"suppressed": e.Suppressed}
+	}
+
+	ex := sentryException{Type: e.Type, Value: e.Message}
+	if len(e.Stack) > 0 {
+		// Sentry 要求调用栈按调用顺序排列，最内层在最后
+		frames := make([]sentryFrame, len(e.Stack))
+		for i, f := range e.Stack {
+			frames[len(e.Stack)-1-i] = sentryFrame{Function: f.Function, Filename: f.File, Lineno: f.Line, InApp: f.InApp}
+		}
+		ex.Stacktrace = &sentryStacktrace{Frames: frames}
+	}
+	se.Exception.Values = []sentryException{ex}
+
+	if r := e.Request; r != nil {
+		se.Transaction = r.Method + " " + r.Route
+		se.Request = &sentryRequest{Method: r.Method, URL: r.Path}
+		if r.UserAgent != "" {
+			se.Request.Headers = map[string]string{"User-Agent": r.UserAgent}
+		}
+		if r.IP != "" {
+			se.Request.Env = map[string]string{"REMOTE_ADDR": r.IP}
+		}
+	}
+	if e.UserID != "" || (e.Request != nil && e.Request.IP != "") {
+		se.User = &sentryUser{ID: e.UserID}
+		if e.Request != nil {
+			se.User.IPAddress = e.Request.IP
+		}
+	}
+	return se
+}
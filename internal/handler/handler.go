@@ -1,20 +1,35 @@
 package handler
 
-import "github.com/google/wire"
+import (
+	"github.com/HoronLee/EchoHub/internal/httpcache"
+	"github.com/HoronLee/EchoHub/internal/ipfilter"
+	"github.com/HoronLee/EchoHub/internal/middleware"
+	"github.com/HoronLee/EchoHub/internal/service"
+	"github.com/google/wire"
+)
 
 // ProviderSet is handler providers.
-var ProviderSet = wire.NewSet(NewHandlers, NewHelloWorldHandler, NewUserHandler)
+var ProviderSet = wire.NewSet(NewHandlers, NewHelloWorldHandler, NewUserHandler, NewMaintenanceHandler)
 
 // Handlers 聚合各个模块的Handler
 type Handlers struct {
-	HelloWorldHandler *HelloWorldHandler
-	UserHandler       *UserHandler
+	HelloWorldHandler  *HelloWorldHandler
+	UserHandler        *UserHandler
+	MaintenanceHandler *MaintenanceHandler
+	ResponseCache      *httpcache.ResponseCache // 供路由按需挂载服务端响应缓存
+	IPFilter           *ipfilter.Filter         // 供路由为管理端点挂载 admin 名单
+	Roles              middleware.RoleResolver  // 管理端点按用户记录中的当前角色鉴权
 }
 
 // NewHandlers 创建Handlers实例
-func NewHandlers(hwHandler *HelloWorldHandler, userHandler *UserHandler) *Handlers {
+func NewHandlers(hwHandler *HelloWorldHandler, userHandler *UserHandler, maintenanceHandler *MaintenanceHandler,
+	responseCache *httpcache.ResponseCache, filter *ipfilter.Filter, userSvc *service.UserService) *Handlers {
 	return &Handlers{
-		HelloWorldHandler: hwHandler,
-		UserHandler:       userHandler,
+		HelloWorldHandler:  hwHandler,
+		UserHandler:        userHandler,
+		MaintenanceHandler: maintenanceHandler,
+		ResponseCache:      responseCache,
+		IPFilter:           filter,
+		Roles:              userSvc.CurrentRole,
 	}
 }
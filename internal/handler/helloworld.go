@@ -5,15 +5,17 @@ import (
 	"github.com/HoronLee/EchoHub/internal/model/helloworld"
 	res "github.com/HoronLee/EchoHub/internal/response"
 	"github.com/HoronLee/EchoHub/internal/service"
+	"github.com/HoronLee/EchoHub/internal/validator"
 	"github.com/labstack/echo/v4"
 )
 
 type HelloWorldHandler struct {
 	svc *service.HelloWorldService
+	v   *validator.Validator
 }
 
-func NewHelloWorldHandler(svc *service.HelloWorldService) *HelloWorldHandler {
-	return &HelloWorldHandler{svc: svc}
+func NewHelloWorldHandler(svc *service.HelloWorldService, v *validator.Validator) *HelloWorldHandler {
+	return &HelloWorldHandler{svc: svc, v: v}
 }
 
 // PostHelloWorld 处理POST /helloworld请求
@@ -22,9 +24,12 @@ func NewHelloWorldHandler(svc *service.HelloWorldService) *HelloWorldHandler {
 // @Tags HelloWorld
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "幂等键，重试时携带相同的值将回放首次响应"
 // @Param request body helloworld.CreateRequest true "HelloWorld创建请求参数"
 // @Success 200 {object} helloworld.CreateResponse "创建成功，返回消息和系统信息"
 // @Failure 400 {object} res.Response "请求参数错误或创建失败"
+// @Failure 409 {object} res.Response "相同幂等键的请求仍在处理中"
+// @Failure 422 {object} res.Response "幂等键已用于不同的请求"
 // @Router /v1/helloworld [post]
 func (h *HelloWorldHandler) PostHelloWorld() echo.HandlerFunc {
 	return res.Execute(func(ctx echo.Context) res.Response {
@@ -33,7 +38,8 @@ func (h *HelloWorldHandler) PostHelloWorld() echo.HandlerFunc {
 			return res.BadRequest("Invalid request body", err)
 		}
 
-		if err := h.svc.PostHelloWorld(ctx.Request().Context(), req.Message); err != nil {
+		hw, err := h.svc.PostHelloWorld(ctx.Request().Context(), req.Message)
+		if err != nil {
 			return res.InternalServerError("Failed to create hello world", err)
 		}
 
@@ -43,9 +49,85 @@ func (h *HelloWorldHandler) PostHelloWorld() echo.HandlerFunc {
 		}
 
 		return res.Success(helloworld.CreateResponse{
+			ID:       hw.PublicID,
 			Message:  req.Message,
 			Version:  commonModel.Version,
 			Database: dbInfo,
 		}, "success")
 	})
 }
+
+// GetHelloWorld 处理GET /helloworld/:id请求
+// @Summary 查询HelloWorld消息
+// @Description 根据公开ID查询HelloWorld消息，响应头 ETag 携带当前版本号
+// @Tags HelloWorld
+// @Accept json
+// @Produce json
+// @Param id path string true "HelloWorld 公开ID"
+// @Param If-None-Match header string false "已缓存响应的 ETag，未变化时返回 304"
+// @Success 200 {object} helloworld.HelloWorld "查询成功"
+// @Success 304 "内容未变化"
+// @Failure 400 {object} res.Response "请求参数错误或ID格式非法"
+// @Failure 404 {object} res.Response "记录不存在（30001）"
+// @Router /v1/helloworld/{id} [get]
+func (h *HelloWorldHandler) GetHelloWorld() echo.HandlerFunc {
+	return res.Execute(func(ctx echo.Context) res.Response {
+		id, errRes, ok := resolvePathID(ctx, "id", h.svc.ResolveHelloWorldID)
+		if !ok {
+			return errRes
+		}
+
+		hw, err := h.svc.GetHelloWorld(ctx.Request().Context(), id)
+		if err != nil {
+			return res.Fail(err)
+		}
+
+		res.SetVersionETag(ctx, hw.Version)
+		return res.Success(hw, "success")
+	})
+}
+
+// UpdateHelloWorld 处理PUT /helloworld/:id请求
+// @Summary 更新HelloWorld消息
+// @Description 以乐观锁方式更新HelloWorld消息，期望版本号通过 If-Match 请求头或请求体 version 字段传入
+// @Tags HelloWorld
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "HelloWorld 公开ID"
+// @Param If-Match header string false "期望的版本 ETag，例如 \"1\""
+// @Param request body helloworld.UpdateRequest true "HelloWorld更新请求参数"
+// @Success 200 {object} helloworld.HelloWorld "更新成功"
+// @Failure 400 {object} res.Response "请求参数错误或ID格式非法"
+// @Failure 401 {object} res.Response "未认证"
+// @Failure 404 {object} res.Response "记录不存在（30001）"
+// @Failure 409 {object} res.Response "版本冲突（10001）"
+// @Failure 422 {object} res.Response "参数校验失败"
+// @Failure 428 {object} res.Response "缺少版本号"
+// @Router /v1/helloworld/{id} [put]
+func (h *HelloWorldHandler) UpdateHelloWorld() echo.HandlerFunc {
+	return res.Execute(func(ctx echo.Context) res.Response {
+		id, errRes, ok := resolvePathID(ctx, "id", h.svc.ResolveHelloWorldID)
+		if !ok {
+			return errRes
+		}
+
+		var req helloworld.UpdateRequest
+		if ok, msg := h.v.BindAndValidate(ctx, &req); !ok {
+			return res.ValidationError(msg)
+		}
+
+		version, errRes, ok := resolveVersion(ctx, req.Version)
+		if !ok {
+			return errRes
+		}
+
+		hw, err := h.svc.UpdateHelloWorld(ctx.Request().Context(), id, req.Message, version)
+		if err != nil {
+			return res.Fail(err)
+		}
+
+		res.SetVersionETag(ctx, hw.Version)
+		return res.Success(hw, "success")
+	})
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"github.com/HoronLee/EchoHub/internal/maintenance"
+	model "github.com/HoronLee/EchoHub/internal/model/maintenance"
+	res "github.com/HoronLee/EchoHub/internal/response"
+	"github.com/HoronLee/EchoHub/internal/validator"
+	"github.com/labstack/echo/v4"
+)
+
+// MaintenanceHandler 维护模式管理处理器
+type MaintenanceHandler struct {
+	m *maintenance.Manager
+	v *validator.Validator
+}
+
+// NewMaintenanceHandler 创建MaintenanceHandler实例
+func NewMaintenanceHandler(m *maintenance.Manager, v *validator.Validator) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		m: m,
+		v: v,
+	}
+}
+
+// GetMaintenance 查询维护模式状态处理器
+// @Summary 查询维护模式状态
+// @Description 返回当前维护模式状态，需要管理令牌或管理员角色
+// @Tags 运维管理
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} maintenance.State "维护模式状态"
+// @Failure 401 {object} res.Response "未认证"
+// @Failure 403 {object} res.Response "无权访问"
+// @Router /v1/admin/maintenance [get]
+func (h *MaintenanceHandler) GetMaintenance() echo.HandlerFunc {
+	return res.Execute(func(ctx echo.Context) res.Response {
+		return res.Success(h.m.State())
+	})
+}
+
+// UpdateMaintenance 切换维护模式处理器
+// @Summary 切换维护模式
+// @Description 开启或关闭维护模式，状态写入状态文件，服务重启后保持；需要管理令牌或管理员角色
+// @Tags 运维管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body maintenance.UpdateRequest true "维护模式参数"
+// @Success 200 {object} maintenance.State "切换后的维护模式状态"
+// @Failure 401 {object} res.Response "未认证"
+// @Failure 403 {object} res.Response "无权访问"
+// @Failure 422 {object} res.Response "参数校验失败"
+// @Router /v1/admin/maintenance [put]
+func (h *MaintenanceHandler) UpdateMaintenance() echo.HandlerFunc {
+	return res.Execute(func(ctx echo.Context) res.Response {
+		var req model.UpdateRequest
+		if ok, msg := h.v.BindAndValidate(ctx, &req); !ok {
+			return res.ValidationError(msg)
+		}
+
+		state, err := h.m.Update(req, operator(ctx))
+		if err != nil {
+			return res.InternalServerError("Failed to update maintenance mode", err)
+		}
+		return res.Success(state)
+	})
+}
+
+// operator 返回管理请求的操作者：用户名，或使用管理令牌时为 admin-token
+func operator(ctx echo.Context) string {
+	if username, ok := ctx.Get("username").(string); ok && username != "" {
+		return username
+	}
+	return "admin-token"
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"context"
+
+	commonModel "github.com/HoronLee/EchoHub/internal/model/common"
+	res "github.com/HoronLee/EchoHub/internal/response"
+	"github.com/labstack/echo/v4"
+)
+
+// idResolver 将公开ID解析为内部主键，由各服务的 ResolveXxxID 方法提供
+type idResolver func(ctx context.Context, publicID string) (uint, error)
+
+// resolvePathID 读取路径参数中的公开ID并解析为内部主键
+// 格式非法返回 400，记录不存在时按服务层的业务错误返回 404
+func resolvePathID(ctx echo.Context, param string, resolve idResolver) (uint, res.Response, bool) {
+	return resolvePublicID(ctx, ctx.Param(param), resolve)
+}
+
+// resolvePublicID 解析公开ID为内部主键
+func resolvePublicID(ctx echo.Context, publicID string, resolve idResolver) (uint, res.Response, bool) {
+	if !commonModel.IsPublicID(publicID) {
+		return 0, res.BadRequest("Invalid id"), false
+	}
+	id, err := resolve(ctx.Request().Context(), publicID)
+	if err != nil {
+		return 0, res.Fail(err), false
+	}
+	return id, res.Response{}, true
+}
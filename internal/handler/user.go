@@ -30,7 +30,9 @@ func NewUserHandler(svc *service.UserService, v *validator.Validator) *UserHandl
 // @Produce json
 // @Param request body user.RegisterRequest true "注册请求参数"
 // @Success 200 {object} map[string]string "注册成功"
-// @Failure 400 {object} res.Response "请求参数错误或注册失败"
+// @Failure 400 {object} res.Response "请求参数错误"
+// @Failure 409 {object} res.Response "用户名已存在（20001）"
+// @Failure 422 {object} res.Response "参数校验失败"
 // @Router /v1/register [post]
 func (h *UserHandler) Register() echo.HandlerFunc {
 	return res.Execute(func(ctx echo.Context) res.Response {
@@ -40,7 +42,7 @@ func (h *UserHandler) Register() echo.HandlerFunc {
 		}
 
 		if err := h.svc.Register(ctx.Request().Context(), req); err != nil {
-			return res.InternalServerError("Registration failed", err)
+			return res.Fail(err)
 		}
 
 		return res.Success(map[string]any{"message": "User registered successfully"}, "success")
@@ -55,7 +57,8 @@ func (h *UserHandler) Register() echo.HandlerFunc {
 // @Produce json
 // @Param request body user.LoginRequest true "登录请求参数"
 // @Success 200 {object} user.LoginResponse "登录成功，返回JWT令牌"
-// @Failure 400 {object} res.Response "请求参数错误或登录失败"
+// @Failure 401 {object} res.Response "用户名或密码错误（20002）"
+// @Failure 422 {object} res.Response "参数校验失败"
 // @Router /v1/login [post]
 func (h *UserHandler) Login() echo.HandlerFunc {
 	return res.Execute(func(ctx echo.Context) res.Response {
@@ -66,13 +69,83 @@ func (h *UserHandler) Login() echo.HandlerFunc {
 
 		token, err := h.svc.Login(ctx.Request().Context(), req)
 		if err != nil {
-			return res.Unauthorized(err.Error(), err)
+			return res.Fail(err)
 		}
 
 		return res.Success(user.LoginResponse{Token: token}, "success")
 	})
 }
 
+// GetUser 查询当前用户处理器
+// @Summary 查询当前用户
+// @Description 查询当前登录用户的信息，响应头 ETag 携带当前版本号
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} user.User "查询成功"
+// @Failure 401 {object} res.Response "用户未认证"
+// @Failure 404 {object} res.Response "用户不存在（20003）"
+// @Router /v1/user [get]
+func (h *UserHandler) GetUser() echo.HandlerFunc {
+	return res.Execute(func(ctx echo.Context) res.Response {
+		userID, errRes, ok := h.currentUserID(ctx)
+		if !ok {
+			return errRes
+		}
+
+		u, err := h.svc.GetUser(ctx.Request().Context(), userID)
+		if err != nil {
+			return res.Fail(err)
+		}
+
+		res.SetVersionETag(ctx, u.Version)
+		return res.Success(u, "success")
+	})
+}
+
+// UpdateUser 更新当前用户处理器
+// @Summary 更新当前用户
+// @Description 以乐观锁方式更新当前登录用户的信息，期望版本号通过 If-Match 请求头或请求体 version 字段传入
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param If-Match header string false "期望的版本 ETag，例如 \"1\""
+// @Param request body user.UpdateRequest true "更新请求参数"
+// @Success 200 {object} user.User "更新成功"
+// @Failure 400 {object} res.Response "请求参数错误"
+// @Failure 401 {object} res.Response "用户未认证"
+// @Failure 409 {object} res.Response "版本冲突（10001）或用户名已存在（20001）"
+// @Failure 428 {object} res.Response "缺少版本号"
+// @Router /v1/user [put]
+func (h *UserHandler) UpdateUser() echo.HandlerFunc {
+	return res.Execute(func(ctx echo.Context) res.Response {
+		userID, errRes, ok := h.currentUserID(ctx)
+		if !ok {
+			return errRes
+		}
+
+		var req user.UpdateRequest
+		if ok, msg := h.v.BindAndValidate(ctx, &req); !ok {
+			return res.ValidationError(msg)
+		}
+
+		version, errRes, ok := resolveVersion(ctx, req.Version)
+		if !ok {
+			return errRes
+		}
+
+		u, err := h.svc.UpdateUser(ctx.Request().Context(), userID, req, version)
+		if err != nil {
+			return res.Fail(err)
+		}
+
+		res.SetVersionETag(ctx, u.Version)
+		return res.Success(u, "success")
+	})
+}
+
 // DeleteUser 删除用户处理器
 // @Summary 删除用户
 // @Description 删除当前登录的用户账户
@@ -81,25 +154,29 @@ func (h *UserHandler) Login() echo.HandlerFunc {
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} map[string]string "删除成功"
-// @Failure 400 {object} res.Response "用户未认证或删除失败"
 // @Failure 401 {object} res.Response "用户未认证"
+// @Failure 404 {object} res.Response "用户不存在（20003）"
 // @Router /v1/user [delete]
 func (h *UserHandler) DeleteUser() echo.HandlerFunc {
 	return res.Execute(func(ctx echo.Context) res.Response {
-		userIDValue := ctx.Get("user_id")
-		if userIDValue == nil {
-			return res.Unauthorized("User not authenticated")
-		}
-
-		userID, ok := userIDValue.(uint)
+		userID, errRes, ok := h.currentUserID(ctx)
 		if !ok {
-			return res.BadRequest("Invalid user ID format")
+			return errRes
 		}
 
 		if err := h.svc.DeleteUser(ctx.Request().Context(), userID); err != nil {
-			return res.InternalServerError("Failed to delete user", err)
+			return res.Fail(err)
 		}
 
 		return res.Success(map[string]any{"message": "User deleted successfully"}, "success")
 	})
 }
+
+// currentUserID 将 JWT 中的用户公开ID解析为内部主键
+func (h *UserHandler) currentUserID(ctx echo.Context) (uint, res.Response, bool) {
+	publicID, ok := ctx.Get("user_id").(string)
+	if !ok || publicID == "" {
+		return 0, res.Unauthorized("User not authenticated"), false
+	}
+	return resolvePublicID(ctx, publicID, h.svc.ResolveUserID)
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	res "github.com/HoronLee/EchoHub/internal/response"
+	"github.com/labstack/echo/v4"
+)
+
+// resolveVersion 解析更新请求期望的版本号
+// If-Match 请求头优先，其次为请求体中的 version 字段；两者都未提供时返回 428，避免无条件覆盖
+func resolveVersion(ctx echo.Context, bodyVersion uint) (uint, res.Response, bool) {
+	version, ok, err := res.IfMatchVersion(ctx)
+	if err != nil {
+		return 0, res.BadRequest("Invalid If-Match header", err), false
+	}
+	if ok {
+		return version, res.Response{}, true
+	}
+	if bodyVersion > 0 {
+		return bodyVersion, res.Response{}, true
+	}
+	return 0, res.Error(http.StatusPreconditionRequired, 428, "If-Match header or version is required"), false
+}
@@ -0,0 +1,173 @@
+// Package httpcache 提供服务端 HTTP 响应缓存
+//
+// 响应保存在 cache 包配置的存储中，按路由、查询参数与主体区分；
+// 失效基于标签版本号：缓存键包含其标签的当前版本，Invalidate 使标签版本失效后，关联的旧响应不再被命中并随 TTL 过期。
+package httpcache
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/cache"
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/service"
+	"github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/google/wire"
+	"go.uber.org/zap"
+)
+
+// ProviderSet is httpcache providers.
+var ProviderSet = wire.NewSet(NewResponseCache, NewResponseInvalidator)
+
+const (
+	keyPrefix = "httpcache:"
+	tagPrefix = "httpcache:tag:"
+	// tagTTL 标签版本的保存时间；版本丢失时会生成新版本，只导致缓存未命中
+	tagTTL = 24 * time.Hour
+)
+
+// Entry 缓存的响应
+type Entry struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// ResponseCache 服务端响应缓存
+type ResponseCache struct {
+	cache      cache.Cache
+	enabled    bool
+	defaultTTL time.Duration
+	maxSize    int
+	log        *log.Logger
+}
+
+// NewResponseInvalidator 以响应缓存实现业务层的缓存响应失效接口
+func NewResponseInvalidator(c *ResponseCache) service.ResponseInvalidator {
+	return c
+}
+
+// NewResponseCache 创建响应缓存
+func NewResponseCache(cfg *config.AppConfig, c cache.Cache, logger *log.Logger) *ResponseCache {
+	rc := cfg.HTTPCache.ResponseCache
+	ttl := rc.DefaultTTL
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	return &ResponseCache{
+		cache:      c,
+		enabled:    rc.Enabled,
+		defaultTTL: ttl,
+		maxSize:    rc.MaxSize,
+		log:        logger,
+	}
+}
+
+// Enabled 是否启用
+func (c *ResponseCache) Enabled() bool {
+	return c != nil && c.enabled
+}
+
+// DefaultTTL 路由未指定时的缓存时间
+func (c *ResponseCache) DefaultTTL() time.Duration {
+	return c.defaultTTL
+}
+
+// MaxSize 可缓存的最大响应体字节数，0 表示不限制
+func (c *ResponseCache) MaxSize() int {
+	return c.maxSize
+}
+
+// Key 根据请求标识与标签的当前版本计算缓存键
+// 标签版本不存在时生成新版本；存储不可用时返回错误，调用方应跳过缓存
+func (c *ResponseCache) Key(ctx context.Context, base string, tags []string) (string, error) {
+	h := sha256.New()
+	h.Write([]byte(base))
+	for _, tag := range tags {
+		v, err := c.tagVersion(ctx, tag)
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte{0})
+		h.Write([]byte(tag + "=" + v))
+	}
+	return keyPrefix + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Get 读取缓存的响应
+func (c *ResponseCache) Get(ctx context.Context, key string) (*Entry, bool) {
+	b, err := c.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, cache.ErrMiss) {
+			c.log.Warn("Failed to read response cache", zap.Error(err))
+		}
+		return nil, false
+	}
+	var e Entry
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&e); err != nil {
+		c.log.Warn("Failed to decode cached response", zap.Error(err))
+		return nil, false
+	}
+	return &e, true
+}
+
+// Set 保存响应，ttl <= 0 时使用默认缓存时间
+func (c *ResponseCache) Set(ctx context.Context, key string, e *Entry, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(e); err != nil {
+		c.log.Warn("Failed to encode cached response", zap.Error(err))
+		return
+	}
+	if err := c.cache.Set(ctx, key, buf.Bytes(), ttl); err != nil {
+		c.log.Warn("Failed to write response cache", zap.Error(err))
+	}
+}
+
+// Invalidate 使标签关联的缓存响应失效，供写操作后调用
+// 失败只记录日志，不影响写操作本身
+func (c *ResponseCache) Invalidate(ctx context.Context, tags ...string) {
+	if !c.Enabled() || len(tags) == 0 {
+		return
+	}
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = tagPrefix + tag
+	}
+	// 删除版本号即可：下次读取时生成新版本，旧缓存键不再被使用
+	if err := c.cache.Delete(ctx, keys...); err != nil {
+		c.log.Warn("Failed to invalidate response cache", zap.Strings("tags", tags), zap.Error(err))
+	}
+}
+
+// tagVersion 返回标签的当前版本，不存在时生成新版本
+func (c *ResponseCache) tagVersion(ctx context.Context, tag string) (string, error) {
+	key := tagPrefix + tag
+	b, err := c.cache.Get(ctx, key)
+	if err == nil {
+		return string(b), nil
+	}
+	if !errors.Is(err, cache.ErrMiss) {
+		return "", err
+	}
+	v := newVersion()
+	if err := c.cache.Set(ctx, key, []byte(v), tagTTL); err != nil {
+		return "", err
+	}
+	return v, nil
+}
+
+func newVersion() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,89 @@
+// Package idempotency 提供 Idempotency-Key 的记录存储
+//
+// 同一幂等键的第一次请求获得处理权并写入“处理中”记录，处理完成后保存响应；
+// 之后的重试直接回放已保存的响应。记录存储可选进程内（memory）或基于 Redis 协议（redis）的实现。
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/google/wire"
+	"go.uber.org/zap"
+)
+
+// ProviderSet is idempotency providers.
+var ProviderSet = wire.NewSet(NewStore)
+
+// ErrLockLost 处理中记录已过期或被其他请求取得，无法保存或释放
+var ErrLockLost = errors.New("idempotency: lock lost")
+
+// Record 幂等键对应的记录
+type Record struct {
+	Fingerprint string    // 首次请求的指纹（方法、路径与请求体摘要），用于识别幂等键被复用于不同请求
+	Completed   bool      // 是否已处理完成
+	Response    *Response // 已保存的响应，仅 Completed 时有值
+}
+
+// Response 保存的响应
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// Store 幂等记录存储，实现需保证 Acquire 在同一键上是原子的
+type Store interface {
+	// Acquire 尝试取得键的处理权：键不存在时写入处理中记录并返回 nil；
+	// 键已存在时返回已有记录。token 标识本次处理，lockTTL 为处理中记录的过期时间
+	Acquire(ctx context.Context, key, fingerprint, token string, lockTTL time.Duration) (*Record, error)
+	// Complete 保存响应，记录在 ttl 后过期；token 不匹配时返回 ErrLockLost
+	Complete(ctx context.Context, key, token string, resp *Response, ttl time.Duration) error
+	// Release 删除处理中记录，使重试可以重新执行；token 不匹配时返回 ErrLockLost
+	Release(ctx context.Context, key, token string) error
+}
+
+// NewStore 根据配置创建记录存储
+func NewStore(cfg *config.AppConfig, logger *log.Logger) (Store, func(), error) {
+	store := cfg.Idempotency.Store
+	if !cfg.Idempotency.Enabled {
+		store = "memory" // 未启用时不连接外部存储
+	}
+	switch store {
+	case "", "memory":
+		return NewMemoryStore(), func() {}, nil
+	case "redis":
+		rc := cfg.Idempotency.Redis
+		s, err := NewRedisStore(RedisOptions{
+			Addr:      rc.Addr,
+			Password:  rc.Password,
+			DB:        rc.DB,
+			KeyPrefix: rc.KeyPrefix,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		logger.Info("Idempotency store initialized", zap.String("store", "redis"), zap.String("addr", rc.Addr))
+		return s, func() {
+			if err := s.Close(); err != nil {
+				logger.Warn("Failed to close idempotency store", zap.Error(err))
+			}
+		}, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported idempotency store: %s", cfg.Idempotency.Store)
+	}
+}
+
+// NewToken 生成标识一次处理的随机令牌
+func NewToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,132 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storeFactories 返回各存储实现及推进其时钟的函数
+func storeFactories(t *testing.T) map[string]func() (Store, func(time.Duration)) {
+	return map[string]func() (Store, func(time.Duration)){
+		"memory": func() (Store, func(time.Duration)) {
+			now := time.Unix(1_700_000_000, 0)
+			s := NewMemoryStore()
+			s.now = func() time.Time { return now }
+			return s, func(d time.Duration) { now = now.Add(d) }
+		},
+		"redis": func() (Store, func(time.Duration)) {
+			mr := miniredis.RunT(t)
+			s, err := NewRedisStore(RedisOptions{Addr: mr.Addr(), KeyPrefix: "test:"})
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = s.Close() })
+			return s, mr.FastForward
+		},
+	}
+}
+
+func TestStoreLifecycle(t *testing.T) {
+	ctx := context.Background()
+	resp := &Response{
+		Status: http.StatusCreated,
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   []byte(`{"code":0}`),
+	}
+
+	for name, newStore := range storeFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			s, _ := newStore()
+
+			rec, err := s.Acquire(ctx, "k", "fp1", "t1", time.Minute)
+			require.NoError(t, err)
+			assert.Nil(t, rec, "first request acquires the key")
+
+			rec, err = s.Acquire(ctx, "k", "fp2", "t2", time.Minute)
+			require.NoError(t, err)
+			require.NotNil(t, rec)
+			assert.False(t, rec.Completed)
+			assert.Equal(t, "fp1", rec.Fingerprint)
+
+			assert.ErrorIs(t, s.Complete(ctx, "k", "t2", resp, time.Hour), ErrLockLost)
+			require.NoError(t, s.Complete(ctx, "k", "t1", resp, time.Hour))
+
+			rec, err = s.Acquire(ctx, "k", "fp1", "t3", time.Minute)
+			require.NoError(t, err)
+			require.NotNil(t, rec)
+			assert.True(t, rec.Completed)
+			assert.Equal(t, resp, rec.Response)
+
+			// 已完成的记录不能被释放
+			assert.ErrorIs(t, s.Release(ctx, "k", "t1"), ErrLockLost)
+		})
+	}
+}
+
+func TestStoreRelease(t *testing.T) {
+	ctx := context.Background()
+
+	for name, newStore := range storeFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			s, _ := newStore()
+
+			_, err := s.Acquire(ctx, "k", "fp", "t1", time.Minute)
+			require.NoError(t, err)
+			assert.ErrorIs(t, s.Release(ctx, "k", "other"), ErrLockLost)
+			require.NoError(t, s.Release(ctx, "k", "t1"))
+
+			rec, err := s.Acquire(ctx, "k", "fp", "t2", time.Minute)
+			require.NoError(t, err)
+			assert.Nil(t, rec, "released key can be acquired again")
+		})
+	}
+}
+
+func TestStoreExpiry(t *testing.T) {
+	ctx := context.Background()
+	resp := &Response{Status: http.StatusOK, Body: []byte("ok")}
+
+	for name, newStore := range storeFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			s, advance := newStore()
+
+			// 处理中记录过期后允许重试，原处理者无法再保存响应
+			_, err := s.Acquire(ctx, "k", "fp", "t1", time.Second)
+			require.NoError(t, err)
+			advance(2 * time.Second)
+			rec, err := s.Acquire(ctx, "k", "fp", "t2", time.Second)
+			require.NoError(t, err)
+			assert.Nil(t, rec)
+			assert.ErrorIs(t, s.Complete(ctx, "k", "t1", resp, time.Minute), ErrLockLost)
+
+			// 已完成的记录在 ttl 后过期
+			require.NoError(t, s.Complete(ctx, "k", "t2", resp, time.Minute))
+			advance(2 * time.Minute)
+			rec, err = s.Acquire(ctx, "k", "fp", "t3", time.Second)
+			require.NoError(t, err)
+			assert.Nil(t, rec)
+		})
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	for _, key := range []string{"a", "b", "c"} {
+		_, err := s.Acquire(ctx, key, "fp", "t", time.Second)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, s.Len())
+
+	now = now.Add(2 * sweepInterval)
+	_, err := s.Acquire(ctx, "d", "fp", "t", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.Len())
+}
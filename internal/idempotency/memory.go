@@ -0,0 +1,108 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval 进程内存储清理过期记录的最小间隔
+const sweepInterval = time.Minute
+
+// MemoryStore 进程内记录存储，仅适用于单实例部署
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memoryEntry struct {
+	token    string
+	record   Record
+	expireAt time.Time
+}
+
+// NewMemoryStore 创建进程内记录存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]*memoryEntry),
+		now:     time.Now,
+	}
+}
+
+// Acquire 尝试取得键的处理权
+func (m *MemoryStore) Acquire(_ context.Context, key, fingerprint, token string, lockTTL time.Duration) (*Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	if e, ok := m.entries[key]; ok && now.Before(e.expireAt) {
+		rec := e.record
+		return &rec, nil
+	}
+	m.entries[key] = &memoryEntry{
+		token:    token,
+		record:   Record{Fingerprint: fingerprint},
+		expireAt: now.Add(lockTTL),
+	}
+	return nil, nil
+}
+
+// Complete 保存响应
+func (m *MemoryStore) Complete(_ context.Context, key, token string, resp *Response, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, err := m.owned(key, token)
+	if err != nil {
+		return err
+	}
+	e.record.Completed = true
+	e.record.Response = resp
+	e.expireAt = m.now().Add(ttl)
+	return nil
+}
+
+// Release 删除处理中记录
+func (m *MemoryStore) Release(_ context.Context, key, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.owned(key, token); err != nil {
+		return err
+	}
+	delete(m.entries, key)
+	return nil
+}
+
+// owned 返回仍由 token 持有的处理中记录
+func (m *MemoryStore) owned(key, token string) (*memoryEntry, error) {
+	e, ok := m.entries[key]
+	if !ok || e.token != token || e.record.Completed || !m.now().Before(e.expireAt) {
+		return nil, ErrLockLost
+	}
+	return e, nil
+}
+
+// sweep 删除已过期的记录
+func (m *MemoryStore) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+	for key, e := range m.entries {
+		if !now.Before(e.expireAt) {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// Len 返回当前保存的记录数
+func (m *MemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
@@ -0,0 +1,136 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireScript 键不存在时写入处理中记录并返回空，已存在时返回指纹、完成标记与响应
+// KEYS[1]: 记录键；ARGV: 令牌、指纹、过期毫秒数
+var acquireScript = redis.NewScript(`
+local v = redis.call('HMGET', KEYS[1], 'fp', 'done', 'resp')
+if v[1] then
+  return v
+end
+redis.call('HSET', KEYS[1], 'token', ARGV[1], 'fp', ARGV[2])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return false
+`)
+
+// completeScript 令牌匹配且未完成时保存响应并重设过期时间
+// KEYS[1]: 记录键；ARGV: 令牌、响应 JSON、过期毫秒数
+var completeScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'token') ~= ARGV[1] or redis.call('HGET', KEYS[1], 'done') == '1' then
+  return 0
+end
+redis.call('HSET', KEYS[1], 'done', '1', 'resp', ARGV[2])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return 1
+`)
+
+// releaseScript 令牌匹配且未完成时删除记录
+// KEYS[1]: 记录键；ARGV: 令牌
+var releaseScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'token') ~= ARGV[1] or redis.call('HGET', KEYS[1], 'done') == '1' then
+  return 0
+end
+redis.call('DEL', KEYS[1])
+return 1
+`)
+
+// RedisOptions Redis 记录存储配置
+type RedisOptions struct {
+	Addr      string // 服务地址，例如 127.0.0.1:6379
+	Password  string // 密码
+	DB        int    // 数据库编号
+	KeyPrefix string // 键前缀
+}
+
+// RedisStore 基于 Redis 协议的记录存储，多实例共享幂等记录
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore 创建 Redis 记录存储并检查连接
+func NewRedisStore(opts RedisOptions) (*RedisStore, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     opts.Addr,
+		Password: opts.Password,
+		DB:       opts.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect redis: %w", err)
+	}
+
+	return &RedisStore{client: client, prefix: opts.KeyPrefix}, nil
+}
+
+// Acquire 尝试取得键的处理权
+func (r *RedisStore) Acquire(ctx context.Context, key, fingerprint, token string, lockTTL time.Duration) (*Record, error) {
+	vals, err := acquireScript.Run(ctx, r.client, []string{r.prefix + key},
+		token, fingerprint, max(lockTTL.Milliseconds(), 1)).Slice()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) != 3 {
+		return nil, fmt.Errorf("unexpected acquire reply: %v", vals)
+	}
+
+	fp, _ := vals[0].(string)
+	done, _ := vals[1].(string)
+	rec := &Record{Fingerprint: fp, Completed: done == "1"}
+	if rec.Completed {
+		data, _ := vals[2].(string)
+		if err := json.Unmarshal([]byte(data), &rec.Response); err != nil {
+			return nil, fmt.Errorf("decode idempotent response: %w", err)
+		}
+	}
+	return rec, nil
+}
+
+// Complete 保存响应
+func (r *RedisStore) Complete(ctx context.Context, key, token string, resp *Response, ttl time.Duration) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("encode idempotent response: %w", err)
+	}
+	ok, err := completeScript.Run(ctx, r.client, []string{r.prefix + key},
+		token, data, max(ttl.Milliseconds(), 1)).Int()
+	if err != nil {
+		return err
+	}
+	if ok != 1 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Release 删除处理中记录
+func (r *RedisStore) Release(ctx context.Context, key, token string) error {
+	ok, err := releaseScript.Run(ctx, r.client, []string{r.prefix + key}, token).Int()
+	if err != nil {
+		return err
+	}
+	if ok != 1 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Close 关闭连接
+func (r *RedisStore) Close() error {
+	return r.client.Close()
+}
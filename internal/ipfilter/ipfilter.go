@@ -0,0 +1,215 @@
+// Package ipfilter 提供基于客户端 IP 的允许/拒绝名单
+//
+// 名单分为全局名单与命名名单：全局名单作用于所有请求，命名名单由中间件挂载到具体路由或分组。
+// 规则保存在原子指针中，配置文件变化或收到 SIGHUP 时重新加载，无需重启服务。
+package ipfilter
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/HoronLee/EchoHub/internal/util/reloader"
+	"github.com/google/wire"
+	"go.uber.org/zap"
+)
+
+// ProviderSet is ipfilter providers.
+var ProviderSet = wire.NewSet(NewFilter)
+
+// List 解析后的允许/拒绝名单
+type List struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// ParseList 解析名单，条目可以是 CIDR 或单个 IP
+func ParseList(l config.IPList) (List, error) {
+	allow, err := ParsePrefixes(l.Allow)
+	if err != nil {
+		return List{}, err
+	}
+	deny, err := ParsePrefixes(l.Deny)
+	if err != nil {
+		return List{}, err
+	}
+	return List{allow: allow, deny: deny}, nil
+}
+
+// Allowed 判断 IP 是否放行：命中拒绝名单时拒绝；允许名单非空时只放行其中的地址
+func (l List) Allowed(ip netip.Addr) bool {
+	if contains(l.deny, ip) {
+		return false
+	}
+	return len(l.allow) == 0 || contains(l.allow, ip)
+}
+
+func contains(prefixes []netip.Prefix, ip netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePrefixes 将 CIDR 或单个 IP 解析为网段，单个 IP 视为 /32 或 /128
+func ParsePrefixes(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			p, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cidr %q: %w", entry, err)
+			}
+			if p.Addr().Is4In6() && p.Bits() >= 96 {
+				p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+			}
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ip %q: %w", entry, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// rules 一次加载得到的全部名单
+type rules struct {
+	enabled bool
+	global  List
+	groups  map[string]List
+}
+
+// Filter IP 访问控制，规则可在运行时整体替换
+type Filter struct {
+	logger *log.Logger
+
+	current  atomic.Pointer[rules]
+	digest   [sha256.Size]byte // 最近一次加载的配置文件摘要，用于判断是否发生变化
+	reloader *reloader.Reloader
+}
+
+// NewFilter 根据配置创建访问控制，名单格式错误时返回错误
+func NewFilter(cfg *config.AppConfig, logger *log.Logger) (*Filter, error) {
+	f := &Filter{logger: logger}
+	// SIGHUP 总是重新加载，定时检查只在配置文件内容变化时重新加载
+	f.reloader = reloader.New(cfg.IPFilter.ReloadInterval, func(trigger string) {
+		f.tryReload(trigger, trigger == reloader.TriggerSignal)
+	})
+	if err := f.Update(cfg); err != nil {
+		return nil, err
+	}
+	if path := config.ConfigFile(); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			f.digest = sha256.Sum256(data)
+		}
+	}
+	return f, nil
+}
+
+// Update 用新配置替换名单；解析失败时保留原有名单并返回错误
+func (f *Filter) Update(cfg *config.AppConfig) error {
+	fc := cfg.IPFilter
+	global, err := ParseList(config.IPList{Allow: fc.Allow, Deny: fc.Deny})
+	if err != nil {
+		return fmt.Errorf("ip_filter: %w", err)
+	}
+	groups := make(map[string]List, len(fc.Groups))
+	for name, l := range fc.Groups {
+		if groups[name], err = ParseList(l); err != nil {
+			return fmt.Errorf("ip_filter group %s: %w", name, err)
+		}
+	}
+	f.current.Store(&rules{enabled: fc.Enabled, global: global, groups: groups})
+	return nil
+}
+
+// Enabled 是否启用访问控制
+func (f *Filter) Enabled() bool {
+	return f.current.Load().enabled
+}
+
+// Allowed 判断 IP 是否允许访问指定名单保护的资源
+// 先检查全局名单，group 非空时再检查对应的命名名单；未配置的命名名单不做额外限制
+func (f *Filter) Allowed(group string, ip netip.Addr) bool {
+	r := f.current.Load()
+	if !r.enabled {
+		return true
+	}
+	ip = ip.Unmap()
+	if !r.global.Allowed(ip) {
+		return false
+	}
+	if group == "" {
+		return true
+	}
+	l, ok := r.groups[group]
+	return !ok || l.Allowed(ip)
+}
+
+// reload 配置文件变化时重新加载名单，内容未变化时返回 false
+// force 为 true 时忽略摘要，总是重新加载
+func (f *Filter) reload(force bool) (bool, error) {
+	path := config.ConfigFile()
+	if path == "" {
+		return false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("read config: %w", err)
+	}
+	digest := sha256.Sum256(data)
+	if !force && digest == f.digest {
+		return false, nil
+	}
+
+	cfg, err := config.Reload()
+	if err != nil {
+		return false, fmt.Errorf("reload config: %w", err)
+	}
+	if err := f.Update(cfg); err != nil {
+		return false, err
+	}
+	f.digest = digest
+	return true, nil
+}
+
+// tryReload 重新加载名单并记录结果
+func (f *Filter) tryReload(trigger string, force bool) {
+	changed, err := f.reload(force)
+	switch {
+	case err != nil:
+		f.logger.Error("Failed to reload IP filter, keeping the previous lists",
+			zap.String("trigger", trigger), zap.Error(err))
+	case changed:
+		f.logger.Info("IP filter reloaded", zap.String("trigger", trigger), zap.Bool("enabled", f.Enabled()))
+	}
+}
+
+// Start 监听 SIGHUP 并按间隔检查配置文件变化，未使用外部配置文件时不执行任何操作
+func (f *Filter) Start() {
+	if config.ConfigFile() == "" {
+		return
+	}
+	f.reloader.Start()
+}
+
+// Stop 停止监听
+func (f *Filter) Stop(ctx context.Context) error {
+	return f.reloader.Stop(ctx)
+}
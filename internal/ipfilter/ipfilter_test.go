@@ -0,0 +1,110 @@
+package ipfilter
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFilter(t *testing.T, cfg *config.AppConfig) *Filter {
+	t.Helper()
+	cfg.Server.Mode = "debug"
+	f, err := NewFilter(cfg, log.NewLogger(cfg))
+	require.NoError(t, err)
+	return f
+}
+
+func TestParsePrefixes(t *testing.T) {
+	prefixes, err := ParsePrefixes([]string{"10.1.2.3", " 192.168.0.0/16 ", "2001:db8::1", "::ffff:172.16.0.0/108", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.1.2.3/32"),
+		netip.MustParsePrefix("192.168.0.0/16"),
+		netip.MustParsePrefix("2001:db8::1/128"),
+		netip.MustParsePrefix("172.16.0.0/12"),
+	}, prefixes)
+
+	_, err = ParsePrefixes([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+	_, err = ParsePrefixes([]string{"example.com"})
+	assert.Error(t, err)
+}
+
+func TestFilterAllowed(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.IPFilter.Enabled = true
+	cfg.IPFilter.Deny = []string{"203.0.113.0/24"}
+	cfg.IPFilter.Groups = map[string]config.IPList{
+		"admin": {Allow: []string{"10.0.0.0/8"}, Deny: []string{"10.9.9.9"}},
+	}
+	f := newTestFilter(t, cfg)
+
+	ip := netip.MustParseAddr
+	t.Run("全局名单作用于所有请求", func(t *testing.T) {
+		assert.True(t, f.Allowed("", ip("198.51.100.1")))
+		assert.False(t, f.Allowed("", ip("203.0.113.7")))
+		assert.False(t, f.Allowed("admin", ip("203.0.113.7")))
+	})
+
+	t.Run("命名名单在全局名单之外额外生效", func(t *testing.T) {
+		assert.True(t, f.Allowed("admin", ip("10.1.2.3")))
+		assert.True(t, f.Allowed("admin", ip("::ffff:10.1.2.3")), "IPv4-mapped addresses match IPv4 entries")
+		assert.False(t, f.Allowed("admin", ip("198.51.100.1")), "allow list restricts to its entries")
+		assert.False(t, f.Allowed("admin", ip("10.9.9.9")), "deny wins over allow")
+	})
+
+	t.Run("未配置的命名名单不做额外限制", func(t *testing.T) {
+		assert.True(t, f.Allowed("unknown", ip("198.51.100.1")))
+	})
+
+	t.Run("未启用时全部放行", func(t *testing.T) {
+		cfg.IPFilter.Enabled = false
+		require.NoError(t, f.Update(cfg))
+		assert.True(t, f.Allowed("admin", ip("203.0.113.7")))
+	})
+
+	t.Run("名单格式错误时保留原有名单", func(t *testing.T) {
+		cfg.IPFilter.Enabled = true
+		cfg.IPFilter.Deny = []string{"not-an-ip"}
+		assert.Error(t, f.Update(cfg))
+		assert.False(t, f.Enabled())
+	})
+}
+
+func TestFilterReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(body string) {
+		require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+	}
+	write("ip_filter:\n  enabled: true\n  deny: [\"192.0.2.1\"]\n")
+	config.LoadAppConfig(path)
+	require.Equal(t, path, config.ConfigFile())
+
+	cfg := config.Config
+	f := newTestFilter(t, &cfg)
+	blocked := netip.MustParseAddr("192.0.2.1")
+	assert.False(t, f.Allowed("", blocked))
+
+	changed, err := f.reload(false)
+	require.NoError(t, err)
+	assert.False(t, changed, "unchanged file is not reloaded")
+
+	write("ip_filter:\n  enabled: true\n  deny: [\"192.0.2.2\"]\n")
+	changed, err = f.reload(false)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.True(t, f.Allowed("", blocked))
+	assert.False(t, f.Allowed("", netip.MustParseAddr("192.0.2.2")))
+
+	// 新配置无效时保留当前名单
+	write("ip_filter:\n  enabled: true\n  deny: [\"192.0.2.300\"]\n")
+	_, err = f.reload(false)
+	assert.Error(t, err)
+	assert.False(t, f.Allowed("", netip.MustParseAddr("192.0.2.2")))
+}
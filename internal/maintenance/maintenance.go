@@ -0,0 +1,185 @@
+// Package maintenance 提供运行时可切换的维护模式
+//
+// 开启后非豁免路由返回 503 与 Retry-After，名单内的客户端 IP 与用户角色不受影响。
+// 状态保存在原子指针中，切换时先写入状态文件再生效，服务重启后从状态文件恢复。
+package maintenance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/ipfilter"
+	model "github.com/HoronLee/EchoHub/internal/model/maintenance"
+	"github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/google/wire"
+	"go.uber.org/zap"
+)
+
+// ProviderSet is maintenance providers.
+var ProviderSet = wire.NewSet(NewManager)
+
+// Manager 维护模式状态管理
+type Manager struct {
+	path        string
+	message     string
+	retryAfter  time.Duration
+	allow       []netip.Prefix
+	bypassRoles []string
+	logger      *log.Logger
+
+	state atomic.Pointer[model.State]
+	mu    sync.Mutex // 串行化切换与写文件
+}
+
+// NewManager 根据配置创建维护模式管理，状态文件存在时从中恢复状态；状态文件损坏时返回错误，避免误开放流量
+func NewManager(cfg *config.AppConfig, logger *log.Logger) (*Manager, error) {
+	mc := cfg.Maintenance
+	allow, err := ipfilter.ParsePrefixes(mc.AllowIPs)
+	if err != nil {
+		return nil, fmt.Errorf("maintenance allow_ips: %w", err)
+	}
+	m := &Manager{
+		path:        mc.StateFile,
+		message:     mc.Message,
+		retryAfter:  mc.RetryAfter,
+		allow:       allow,
+		bypassRoles: mc.BypassRoles,
+		logger:      logger,
+	}
+
+	state, err := m.load()
+	if err != nil {
+		return nil, fmt.Errorf("maintenance state_file: %w", err)
+	}
+	if state == nil {
+		state = &model.State{Enabled: mc.Enabled}
+	}
+	m.state.Store(state)
+	if state.Enabled {
+		logger.Warn("Maintenance mode is enabled",
+			zap.String("message", state.Message),
+			zap.Time("since", state.UpdatedAt))
+	}
+	return m, nil
+}
+
+// State 返回当前状态，RetryAfter 为 0 时补全为默认值
+func (m *Manager) State() model.State {
+	s := *m.state.Load()
+	if s.RetryAfter <= 0 {
+		s.RetryAfter = int(m.retryAfter.Seconds())
+	}
+	return s
+}
+
+// Message 返回当前提示消息，未设置时返回空串（由调用方使用本地化的默认消息）
+func (m *Manager) Message() string {
+	if msg := m.state.Load().Message; msg != "" {
+		return msg
+	}
+	return m.message
+}
+
+// Bypass 判断客户端 IP 或用户角色是否不受维护模式影响
+func (m *Manager) Bypass(ip netip.Addr, role string) bool {
+	if role != "" && slices.Contains(m.bypassRoles, role) {
+		return true
+	}
+	if !ip.IsValid() {
+		return false
+	}
+	ip = ip.Unmap()
+	for _, p := range m.allow {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Update 切换维护模式：先写入状态文件，成功后再生效；by 为操作者，用于审计
+func (m *Manager) Update(req model.UpdateRequest, by string) (model.State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := &model.State{
+		Enabled:   req.Enabled,
+		UpdatedAt: time.Now().UTC(),
+		UpdatedBy: by,
+	}
+	if req.Enabled {
+		state.Message = req.Message
+		state.RetryAfter = req.RetryAfter
+	}
+	if err := m.save(state); err != nil {
+		return model.State{}, err
+	}
+	m.state.Store(state)
+
+	m.logger.Warn("Maintenance mode switched",
+		zap.Bool("enabled", state.Enabled),
+		zap.String("message", state.Message),
+		zap.Int("retry_after", state.RetryAfter),
+		zap.String("by", by))
+	return m.State(), nil
+}
+
+// load 读取状态文件，文件不存在时返回 nil
+func (m *Manager) load() (*model.State, error) {
+	if m.path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state model.State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", m.path, err)
+	}
+	return &state, nil
+}
+
+// save 写入临时文件后重命名，避免进程中断留下不完整的状态文件；未配置状态文件时只在内存中生效
+func (m *Manager) save(state *model.State) error {
+	if m.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(m.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".maintenance-*.json")
+	if err != nil {
+		return fmt.Errorf("write state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), m.path); err != nil {
+		return fmt.Errorf("write state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package maintenance
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	model "github.com/HoronLee/EchoHub/internal/model/maintenance"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConfig(stateFile string) *config.AppConfig {
+	cfg := &config.AppConfig{}
+	cfg.Server.Mode = "debug"
+	cfg.Maintenance.StateFile = stateFile
+	cfg.Maintenance.RetryAfter = 5 * time.Minute
+	cfg.Maintenance.AllowIPs = []string{"10.0.0.0/8", "::1"}
+	cfg.Maintenance.BypassRoles = []string{"admin"}
+	return cfg
+}
+
+func TestManagerPersistsState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "maintenance.json")
+	cfg := newTestConfig(path)
+	logger := util.NewLogger(cfg)
+
+	m, err := NewManager(cfg, logger)
+	require.NoError(t, err)
+	assert.False(t, m.State().Enabled)
+	assert.Equal(t, 300, m.State().RetryAfter, "retry_after should default to the configured value")
+
+	state, err := m.Update(model.UpdateRequest{Enabled: true, Message: "migrating", RetryAfter: 60}, "alice")
+	require.NoError(t, err)
+	assert.True(t, state.Enabled)
+	assert.Equal(t, "migrating", m.Message())
+
+	// 重启后从状态文件恢复，忽略配置中的初始状态
+	restarted, err := NewManager(cfg, logger)
+	require.NoError(t, err)
+	state = restarted.State()
+	assert.True(t, state.Enabled)
+	assert.Equal(t, 60, state.RetryAfter)
+	assert.Equal(t, "alice", state.UpdatedBy)
+
+	_, err = restarted.Update(model.UpdateRequest{Enabled: false, Message: "ignored"}, "bob")
+	require.NoError(t, err)
+	cfg.Maintenance.Enabled = true
+	restarted, err = NewManager(cfg, logger)
+	require.NoError(t, err)
+	assert.False(t, restarted.State().Enabled)
+	assert.Empty(t, restarted.State().Message)
+}
+
+func TestManagerInitialState(t *testing.T) {
+	cfg := newTestConfig(filepath.Join(t.TempDir(), "maintenance.json"))
+	cfg.Maintenance.Enabled = true
+	cfg.Maintenance.Message = "default message"
+
+	m, err := NewManager(cfg, util.NewLogger(cfg))
+	require.NoError(t, err)
+	assert.True(t, m.State().Enabled)
+	assert.Equal(t, "default message", m.Message())
+}
+
+func TestManagerRejectsCorruptStateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "maintenance.json")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o644))
+
+	cfg := newTestConfig(path)
+	_, err := NewManager(cfg, util.NewLogger(cfg))
+	assert.Error(t, err)
+}
+
+func TestManagerBypass(t *testing.T) {
+	cfg := newTestConfig("")
+	m, err := NewManager(cfg, util.NewLogger(cfg))
+	require.NoError(t, err)
+
+	assert.True(t, m.Bypass(netip.MustParseAddr("10.1.2.3"), ""))
+	assert.True(t, m.Bypass(netip.MustParseAddr("::ffff:10.1.2.3"), ""), "IPv4-mapped addresses should match IPv4 ranges")
+	assert.True(t, m.Bypass(netip.MustParseAddr("::1"), ""))
+	assert.True(t, m.Bypass(netip.Addr{}, "admin"))
+	assert.False(t, m.Bypass(netip.MustParseAddr("192.0.2.1"), "user"))
+	assert.False(t, m.Bypass(netip.Addr{}, ""))
+}
@@ -0,0 +1,161 @@
+// Package metrics 提供 Prometheus 指标注册表
+//
+// 内置 HTTP 请求、业务错误码、数据库连接池、发件箱投递与 Go 运行时指标，
+// 业务代码可通过 Registry 的 NewCounterVec / NewGaugeVec / NewHistogramVec 注册自定义指标。
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/outbox"
+	"github.com/google/wire"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// ProviderSet is metrics providers.
+var ProviderSet = wire.NewSet(NewRegistry)
+
+// Registry 应用指标注册表
+type Registry struct {
+	reg       *prometheus.Registry
+	namespace string
+
+	httpRequests   *prometheus.CounterVec
+	httpDuration   *prometheus.HistogramVec
+	businessErrors *prometheus.CounterVec
+}
+
+var (
+	defaultRegistry *Registry
+	mu              sync.RWMutex
+)
+
+// Default 获取全局注册表（用于 response 等无法注入依赖的位置），未初始化时返回 nil
+func Default() *Registry {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultRegistry
+}
+
+// SetDefault 设置全局注册表（由 NewRegistry 调用）
+func SetDefault(r *Registry) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultRegistry = r
+}
+
+// NewRegistry 创建指标注册表，注册内置指标并设置为全局注册表
+func NewRegistry(cfg *config.AppConfig) *Registry {
+	r := &Registry{
+		reg:       prometheus.NewRegistry(),
+		namespace: cfg.Metrics.Namespace,
+	}
+
+	r.reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{Namespace: r.namespace}),
+	)
+
+	buckets := cfg.Metrics.Buckets
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+	r.httpRequests = r.NewCounterVec("http_requests_total",
+		"Total number of HTTP requests by route template, method and status.",
+		"method", "route", "status")
+	r.httpDuration = r.NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency by route template, method and status.",
+		buckets, "method", "route", "status")
+	r.businessErrors = r.NewCounterVec("business_errors_total",
+		"Total number of business error responses by business code.",
+		"code")
+
+	SetDefault(r)
+	return r
+}
+
+// NewCounterVec 注册计数器，名称自动加上命名空间前缀
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{Namespace: r.namespace, Name: name, Help: help}, labels)
+	r.reg.MustRegister(c)
+	return c
+}
+
+// NewGaugeVec 注册仪表盘，名称自动加上命名空间前缀
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *prometheus.GaugeVec {
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: r.namespace, Name: name, Help: help}, labels)
+	r.reg.MustRegister(g)
+	return g
+}
+
+// NewHistogramVec 注册直方图，buckets 为空时使用默认分桶
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	h := prometheus.NewHistogramVec(prometheus.HistogramOpts{Namespace: r.namespace, Name: name, Help: help, Buckets: buckets}, labels)
+	r.reg.MustRegister(h)
+	return h
+}
+
+// MustRegister 注册自定义采集器
+func (r *Registry) MustRegister(cs ...prometheus.Collector) {
+	r.reg.MustRegister(cs...)
+}
+
+// RegisterDBStats 导出数据库连接池统计（sql.DBStats）
+func (r *Registry) RegisterDBStats(db *sql.DB, name string) {
+	r.reg.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterOutbox 导出发件箱投递指标，stats 在每次抓取时调用（通常为 outbox.Relay.Metrics）
+func (r *Registry) RegisterOutbox(stats func() outbox.Metrics) {
+	gauge := func(name, help string, value func(outbox.Metrics) float64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{Namespace: r.namespace, Name: name, Help: help},
+			func() float64 { return value(stats()) })
+	}
+	counter := func(name, help string, value func(outbox.Metrics) float64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{Namespace: r.namespace, Name: name, Help: help},
+			func() float64 { return value(stats()) })
+	}
+	r.reg.MustRegister(
+		gauge("outbox_pending_events", "Number of outbox events waiting to be delivered.",
+			func(m outbox.Metrics) float64 { return float64(m.Pending) }),
+		gauge("outbox_lag_seconds", "Age of the oldest outbox event waiting to be delivered.",
+			func(m outbox.Metrics) float64 { return m.Lag.Seconds() }),
+		gauge("outbox_delivery_lag_seconds", "End-to-end latency of the most recently delivered outbox event.",
+			func(m outbox.Metrics) float64 { return m.DeliveryLag.Seconds() }),
+		counter("outbox_published_total", "Total number of outbox events delivered.",
+			func(m outbox.Metrics) float64 { return float64(m.Published) }),
+		counter("outbox_retried_total", "Total number of failed outbox deliveries scheduled for retry.",
+			func(m outbox.Metrics) float64 { return float64(m.Retried) }),
+		counter("outbox_failed_total", "Total number of outbox events that exceeded the maximum attempts.",
+			func(m outbox.Metrics) float64 { return float64(m.Failed) }),
+	)
+}
+
+// ObserveHTTP 记录一次 HTTP 请求，route 必须是路由模板而不是原始 URL，避免标签基数失控
+func (r *Registry) ObserveHTTP(method, route string, status int, d time.Duration) {
+	s := strconv.Itoa(status)
+	r.httpRequests.WithLabelValues(method, route, s).Inc()
+	r.httpDuration.WithLabelValues(method, route, s).Observe(d.Seconds())
+}
+
+// ObserveBusinessError 记录一次业务错误响应
+func (r *Registry) ObserveBusinessError(code int) {
+	r.businessErrors.WithLabelValues(strconv.Itoa(code)).Inc()
+}
+
+// Handler 返回指标抓取处理器
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.reg, promhttp.HandlerOpts{Registry: r.reg})
+}
+
+// Gatherer 返回底层采集器，便于测试读取指标
+func (r *Registry) Gatherer() prometheus.Gatherer {
+	return r.reg
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/outbox"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestRegistry() *Registry {
+	cfg := &config.AppConfig{}
+	cfg.Metrics.Namespace = "test"
+	return NewRegistry(cfg)
+}
+
+func TestRegistry(t *testing.T) {
+	r := newTestRegistry()
+	assert.Same(t, r, Default())
+
+	r.ObserveHTTP("GET", "/api/v1/helloworld/:id", 200, 20*time.Millisecond)
+	r.ObserveHTTP("GET", "/api/v1/helloworld/:id", 200, 30*time.Millisecond)
+	r.ObserveBusinessError(404)
+	assert.Equal(t, 2.0, testutil.ToFloat64(r.httpRequests.WithLabelValues("GET", "/api/v1/helloworld/:id", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(r.businessErrors.WithLabelValues("404")))
+
+	// 业务自定义指标带命名空间前缀
+	orders := r.NewCounterVec("orders_created_total", "Orders created.", "channel")
+	orders.WithLabelValues("web").Add(3)
+	n, err := testutil.GatherAndCount(r.Gatherer(), "test_orders_created_total")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestRegistryHandler(t *testing.T) {
+	r := newTestRegistry()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	r.RegisterDBStats(sqlDB, "sqlite")
+	r.ObserveBusinessError(409)
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	for _, name := range []string{
+		`test_business_errors_total{code="409"} 1`,
+		`go_sql_open_connections{db_name="sqlite"}`,
+		"go_goroutines",
+	} {
+		assert.True(t, strings.Contains(string(body), name), "missing %s", name)
+	}
+}
+
+func TestRegistryOutbox(t *testing.T) {
+	r := newTestRegistry()
+	r.RegisterOutbox(func() outbox.Metrics {
+		return outbox.Metrics{Published: 5, Pending: 3, Lag: 2 * time.Second, DeliveryLag: 250 * time.Millisecond}
+	})
+
+	expected := `
+# HELP test_outbox_lag_seconds Age of the oldest outbox event waiting to be delivered.
+# TYPE test_outbox_lag_seconds gauge
+test_outbox_lag_seconds 2
+# HELP test_outbox_delivery_lag_seconds End-to-end latency of the most recently delivered outbox event.
+# TYPE test_outbox_delivery_lag_seconds gauge
+test_outbox_delivery_lag_seconds 0.25
+# HELP test_outbox_pending_events Number of outbox events waiting to be delivered.
+# TYPE test_outbox_pending_events gauge
+test_outbox_pending_events 3
+# HELP test_outbox_published_total Total number of outbox events delivered.
+# TYPE test_outbox_published_total counter
+test_outbox_published_total 5
+`
+	require.NoError(t, testutil.GatherAndCompare(r.Gatherer(), strings.NewReader(expected),
+		"test_outbox_pending_events", "test_outbox_lag_seconds", "test_outbox_delivery_lag_seconds", "test_outbox_published_total"))
+}
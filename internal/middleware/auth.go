@@ -1,11 +1,16 @@
 package middleware
 
 import (
+	"context"
+	"crypto/subtle"
+	"errors"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/HoronLee/EchoHub/internal/config"
 	"github.com/HoronLee/EchoHub/internal/model/user"
+	"github.com/HoronLee/EchoHub/internal/service"
 	jwtUtil "github.com/HoronLee/EchoHub/internal/util/jwt"
 	"github.com/labstack/echo/v4"
 )
@@ -21,34 +26,95 @@ func JwtAuth() echo.MiddlewareFunc {
 				return next(ctx)
 			}
 
-			authHeader := ctx.Request().Header.Get("Authorization")
-			if authHeader == "" {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Token not found")
+			tokenString, err := bearerToken(ctx)
+			if err != nil {
+				return err
 			}
-
-			parts := strings.SplitN(authHeader, " ", 2)
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Token format invalid")
+			claims, err := parseClaims(tokenString)
+			if err != nil {
+				return err
 			}
 
-			tokenString := parts[1]
-			if tokenString == "" {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Token not found")
-			}
+			// user_id 为用户公开ID，处理器按需解析为内部主键
+			ctx.Set("user_id", claims.UserID)
+			ctx.Set("username", claims.Username)
+			ctx.Set("role", claims.Role)
 
-			jwtService := jwtUtil.NewJWT[user.Claims](&jwtUtil.Config{
-				SecretKey: string(config.JWT_SECRET),
-			})
+			return next(ctx)
+		}
+	}
+}
+
+// RoleResolver 按用户公开ID查询当前角色，用户不存在时返回 service.ErrUserNotFound
+type RoleResolver func(ctx context.Context, userID string) (string, error)
 
-			claims, err := jwtService.ParseToken(tokenString)
+// RequireAdmin 管理端点认证中间件
+// 接受 Authorization: Bearer <管理令牌>（ADMIN_TOKEN 或 auth.admin.token，供 CLI 等运维工具使用），
+// 或当前角色属于 auth.admin.roles 的用户 JWT；管理令牌未配置时只接受 JWT。
+// 角色通过 resolve 从用户记录读取而不信任令牌中的 role 声明，被降级或删除的管理员立即失去权限
+func RequireAdmin(resolve RoleResolver) echo.MiddlewareFunc {
+	adminToken := config.GetAdminToken()
+	roles := config.Config.Auth.Admin.Roles
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			tokenString, err := bearerToken(ctx)
 			if err != nil {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Token invalid or expired")
+				return err
+			}
+			if adminToken != "" && subtle.ConstantTimeCompare([]byte(tokenString), []byte(adminToken)) == 1 {
+				ctx.Set("role", user.RoleAdmin)
+				return next(ctx)
 			}
 
+			claims, err := parseClaims(tokenString)
+			if err != nil {
+				return err
+			}
+			role, err := resolve(ctx.Request().Context(), claims.UserID)
+			if errors.Is(err, service.ErrUserNotFound) {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Token user no longer exists")
+			}
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(roles, role) {
+				return echo.NewHTTPError(http.StatusForbidden, "Admin role required")
+			}
 			ctx.Set("user_id", claims.UserID)
 			ctx.Set("username", claims.Username)
-
+			ctx.Set("role", role)
 			return next(ctx)
 		}
 	}
 }
+
+// bearerToken 从 Authorization 请求头取出 Bearer 令牌
+func bearerToken(ctx echo.Context) (string, error) {
+	authHeader := ctx.Request().Header.Get("Authorization")
+	if authHeader == "" {
+		return "", echo.NewHTTPError(http.StatusUnauthorized, "Token not found")
+	}
+
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return "", echo.NewHTTPError(http.StatusUnauthorized, "Token format invalid")
+	}
+
+	if parts[1] == "" {
+		return "", echo.NewHTTPError(http.StatusUnauthorized, "Token not found")
+	}
+	return parts[1], nil
+}
+
+// parseClaims 校验 JWT 并返回其中的用户信息
+func parseClaims(tokenString string) (*user.Claims, error) {
+	jwtService := jwtUtil.NewJWT[user.Claims](&jwtUtil.Config{
+		SecretKey: string(config.JWT_SECRET),
+	})
+
+	claims, err := jwtService.ParseToken(tokenString)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Token invalid or expired")
+	}
+	return claims, nil
+}
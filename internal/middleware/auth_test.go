@@ -1,14 +1,45 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/model/user"
+	"github.com/HoronLee/EchoHub/internal/service"
+	jwtUtil "github.com/HoronLee/EchoHub/internal/util/jwt"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// testRoles 模拟用户记录中的当前角色，u-demoted 的令牌签发时为管理员，之后被降级
+var testRoles = map[string]string{"u-admin": user.RoleAdmin, "u-user": user.RoleUser, "u-demoted": user.RoleUser}
+
+func resolveTestRole(_ context.Context, userID string) (string, error) {
+	role, ok := testRoles[userID]
+	if !ok {
+		return "", service.ErrUserNotFound
+	}
+	return role, nil
+}
+
+func signTestToken(t *testing.T, userID, role string) string {
+	t.Helper()
+	token, err := jwtUtil.NewJWT[user.Claims](&jwtUtil.Config{SecretKey: string(config.JWT_SECRET)}).GenerateToken(&user.Claims{
+		UserID:           userID,
+		Username:         "alice",
+		Role:             role,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	})
+	require.NoError(t, err)
+	return token
+}
+
 // TestJwtAuth_NotFoundRoute 测试访问不存在的路由时返回404而非401
 func TestJwtAuth_NotFoundRoute(t *testing.T) {
 	e := echo.New()
@@ -115,3 +146,44 @@ func TestJwtAuth_WildcardRoute(t *testing.T) {
 		})
 	}
 }
+
+func TestRequireAdmin(t *testing.T) {
+	config.JWT_SECRET = []byte("test-secret")
+	config.Config.Auth.Admin.Token = "ops-token"
+	config.Config.Auth.Admin.Roles = []string{user.RoleAdmin}
+	t.Cleanup(func() {
+		config.Config.Auth.Admin.Token = ""
+		config.Config.Auth.Admin.Roles = nil
+	})
+
+	e := echo.New()
+	e.HTTPErrorHandler = CustomHTTPErrorHandler
+	e.GET("/admin", func(c echo.Context) error {
+		return c.String(http.StatusOK, c.Get("role").(string))
+	}, RequireAdmin(resolveTestRole))
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"管理令牌", "ops-token", http.StatusOK},
+		{"管理员 JWT", signTestToken(t, "u-admin", user.RoleAdmin), http.StatusOK},
+		{"普通用户 JWT", signTestToken(t, "u-user", user.RoleUser), http.StatusForbidden},
+		{"已降级的管理员 JWT", signTestToken(t, "u-demoted", user.RoleAdmin), http.StatusForbidden},
+		{"已删除的管理员 JWT", signTestToken(t, "u-deleted", user.RoleAdmin), http.StatusUnauthorized},
+		{"无效令牌", "wrong", http.StatusUnauthorized},
+		{"未携带令牌", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			if tt.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/util/pathmatch"
+	"github.com/labstack/echo/v4"
+)
+
+// capturedBodiesKey 捕获结果在 echo.Context 中的键，由 Logger 写入访问日志
+const capturedBodiesKey = "captured_bodies"
+
+// capturedBodies 脱敏后的请求体与响应体
+type capturedBodies struct {
+	request  *bodyBuffer
+	response *bodyCaptureWriter
+	redact   *bodyRedactor
+}
+
+// BodyCapture 请求/响应体捕获中间件，捕获结果随访问日志（json 格式）记录为 request_body、response_body 字段
+// debug 模式下捕获全部请求，release 模式下只捕获 routes 命中的路由或 users 中的用户；
+// 每个方向最多保留 max_bytes 字节，JSON 按字段路径脱敏，超出上限的 JSON 无法可靠脱敏因此不记录；
+// multipart 请求、二进制内容与流式响应只记录占位说明，不缓冲内容
+func BodyCapture(cfg *config.AppConfig) echo.MiddlewareFunc {
+	bc := cfg.BodyCapture
+	maxBytes := bc.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = 16 << 10
+	}
+	captureAll := cfg.Server.Mode == "debug"
+	routes := pathmatch.ParseList(bc.Routes)
+	users := make(map[string]bool, len(bc.Users))
+	for _, u := range bc.Users {
+		users[u] = true
+	}
+	redact := newBodyRedactor(bc.RedactFields, cfg.AccessLog.RedactQueryParams)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !bc.Enabled || (!captureAll && len(bc.Routes) == 0 && len(users) == 0) {
+			return next
+		}
+		return func(ctx echo.Context) (err error) {
+			req := ctx.Request()
+			// 用户ID由路由级认证中间件设置，此处只能先按路由判断，用户在处理完成后再判断
+			if !captureAll && !routes.Match(req.URL.Path, ctx.Path()) && len(users) == 0 {
+				return next(ctx)
+			}
+
+			captured := &capturedBodies{redact: redact}
+			if req.Body != nil && req.Body != http.NoBody {
+				captured.request = &bodyBuffer{limit: maxBytes, contentType: req.Header.Get(echo.HeaderContentType)}
+				req.Body = &teeReadCloser{ReadCloser: req.Body, buf: captured.request}
+			}
+			resp := ctx.Response()
+			captured.response = &bodyCaptureWriter{ResponseWriter: resp.Writer, resp: resp, buf: bodyBuffer{limit: maxBytes}}
+			resp.Writer = captured.response
+			defer func() {
+				resp.Writer = captured.response.ResponseWriter
+			}()
+
+			// 处理器返回的错误不在此处生成响应，否则错误响应会经过内层的压缩、ETag 等中间件，
+			// 与未开启捕获时不同；错误响应由 Logger 在外层生成并捕获，见 writeError
+			err = next(ctx)
+
+			userID, _ := ctx.Get("user_id").(string)
+			if captureAll || routes.Match(req.URL.Path, ctx.Path()) || users[userID] {
+				ctx.Set(capturedBodiesKey, captured)
+			}
+			return err
+		}
+	}
+}
+
+// writeError 生成错误响应并捕获响应体
+// 由 Logger 在记录访问日志前调用：此时内层中间件已恢复各自的写入器，错误响应与未开启捕获时
+// 由 Echo 在中间件链之外生成的响应相同，随后 Echo 的错误处理器因响应已提交而跳过
+func (c *capturedBodies) writeError(ctx echo.Context, err error) {
+	resp := ctx.Response()
+	c.response.ResponseWriter = resp.Writer
+	resp.Writer = c.response
+	defer func() {
+		resp.Writer = c.response.ResponseWriter
+	}()
+	ctx.Error(err)
+}
+
+// requestBody 返回脱敏后的请求体，未捕获时返回空串
+func (c *capturedBodies) requestBody() string {
+	if c.request == nil {
+		return ""
+	}
+	return c.redact.render(c.request)
+}
+
+// responseBody 返回脱敏后的响应体，未捕获时返回空串
+func (c *capturedBodies) responseBody() string {
+	w := c.response
+	if w.streaming || isStreamingType(w.resp.Header().Get(echo.HeaderContentType)) {
+		return fmt.Sprintf("[streaming response omitted, %d bytes]", w.buf.total)
+	}
+	w.buf.contentType = w.resp.Header().Get(echo.HeaderContentType)
+	return c.redact.render(&w.buf)
+}
+
+// bodyBuffer 保留前 limit 字节并统计总字节数
+type bodyBuffer struct {
+	bytes.Buffer
+	limit       int
+	total       int64
+	contentType string
+}
+
+func (b *bodyBuffer) capture(p []byte) {
+	b.total += int64(len(p))
+	if room := b.limit - b.Len(); room > 0 {
+		if len(p) > room {
+			p = p[:room]
+		}
+		b.Write(p)
+	}
+}
+
+func (b *bodyBuffer) truncated() bool {
+	return b.total > int64(b.Len())
+}
+
+// teeReadCloser 在处理器读取请求体时同步保留内容，不提前读取，请求体限制与解压等行为不受影响
+type teeReadCloser struct {
+	io.ReadCloser
+	buf *bodyBuffer
+}
+
+func (r *teeReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 && !isMultipart(r.buf.contentType) {
+		r.buf.capture(p[:n])
+	} else {
+		r.buf.total += int64(n)
+	}
+	return n, err
+}
+
+// bodyCaptureWriter 在写出响应的同时保留内容；调用 Flush 的响应视为流式响应
+type bodyCaptureWriter struct {
+	http.ResponseWriter
+	resp      *echo.Response
+	buf       bodyBuffer
+	streaming bool
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	if !w.streaming {
+		w.buf.capture(b[:n])
+	} else {
+		w.buf.total += int64(n)
+	}
+	return n, err
+}
+
+// Flush 实现 http.Flusher
+func (w *bodyCaptureWriter) Flush() {
+	w.streaming = true
+	w.buf.Reset()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack 实现 http.Hijacker，用于 WebSocket 等协议升级
+func (w *bodyCaptureWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		w.streaming = true
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
+
+// Unwrap 供 http.ResponseController 访问底层写入器
+func (w *bodyCaptureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func isMultipart(contentType string) bool {
+	return strings.HasPrefix(strings.ToLower(contentType), "multipart/")
+}
+
+func isStreamingType(contentType string) bool {
+	return strings.HasPrefix(strings.ToLower(contentType), "text/event-stream")
+}
+
+// bodyRedactor 按配置脱敏请求体与响应体
+// JSON 字段规则不含 . 时匹配任意层级的同名字段，含 . 时按从根开始的路径匹配，* 匹配任意字段名，数组对路径透明；
+// 表单请求体按字段名脱敏（同时使用 access_log.redact_query_params）
+type bodyRedactor struct {
+	names      map[string]bool
+	paths      [][]string
+	formParams map[string]bool
+}
+
+func newBodyRedactor(fields, queryParams []string) *bodyRedactor {
+	r := &bodyRedactor{names: make(map[string]bool), formParams: make(map[string]bool)}
+	for _, f := range fields {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" {
+			continue
+		}
+		if strings.Contains(f, ".") {
+			r.paths = append(r.paths, strings.Split(f, "."))
+			continue
+		}
+		r.names[f] = true
+		r.formParams[f] = true
+	}
+	for _, p := range queryParams {
+		r.formParams[strings.ToLower(p)] = true
+	}
+	return r
+}
+
+// render 按内容类型生成日志中的请求体/响应体
+func (r *bodyRedactor) render(b *bodyBuffer) string {
+	if b.total == 0 {
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(b.contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		return fmt.Sprintf("[multipart body omitted, %d bytes]", b.total)
+	case mediaType == echo.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json"):
+		if b.truncated() {
+			return fmt.Sprintf("[json body omitted, %d bytes exceeds capture limit]", b.total)
+		}
+		return r.redactJSON(b.Bytes())
+	case mediaType == echo.MIMEApplicationForm:
+		if b.truncated() {
+			return fmt.Sprintf("[form body omitted, %d bytes exceeds capture limit]", b.total)
+		}
+		return redactQuery(b.String(), r.formParams)
+	case strings.HasPrefix(mediaType, "text/"), mediaType == echo.MIMEApplicationXML, strings.HasSuffix(mediaType, "+xml"):
+		if b.truncated() {
+			return b.String() + fmt.Sprintf("...[truncated, %d bytes]", b.total)
+		}
+		return b.String()
+	case mediaType == "":
+		return fmt.Sprintf("[untyped body omitted, %d bytes]", b.total)
+	default:
+		return fmt.Sprintf("[%s body omitted, %d bytes]", mediaType, b.total)
+	}
+}
+
+// redactJSON 解析并脱敏 JSON，无法解析时不记录内容
+func (r *bodyRedactor) redactJSON(data []byte) string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Sprintf("[invalid json body omitted, %d bytes]", len(data))
+	}
+	v = r.walk(v, nil)
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("[json body omitted: %v]", err)
+	}
+	return string(out)
+}
+
+func (r *bodyRedactor) walk(v any, path []string) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			p := append(path[:len(path):len(path)], strings.ToLower(k))
+			if r.match(p) {
+				t[k] = redacted
+				continue
+			}
+			t[k] = r.walk(child, p)
+		}
+	case []any:
+		for i, child := range t {
+			t[i] = r.walk(child, path)
+		}
+	}
+	return v
+}
+
+func (r *bodyRedactor) match(path []string) bool {
+	if r.names[path[len(path)-1]] {
+		return true
+	}
+	for _, rule := range r.paths {
+		if len(rule) != len(path) {
+			continue
+		}
+		ok := true
+		for i, seg := range rule {
+			if seg != "*" && seg != path[i] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
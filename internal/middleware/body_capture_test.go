@@ -0,0 +1,200 @@
+package middleware
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newBodyCaptureEcho(cfg *config.AppConfig) (*echo.Echo, *observer.ObservedLogs) {
+	cfg.AccessLog.Enabled = true
+	cfg.BodyCapture.Enabled = true
+	if cfg.Server.Mode == "" {
+		cfg.Server.Mode = "debug"
+	}
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := &util.AccessLogger{Logger: zap.New(core), Format: util.AccessFormatJSON}
+
+	e := echo.New()
+	e.HTTPErrorHandler = CustomHTTPErrorHandler
+	e.Use(Logger(cfg, logger), BodyCapture(cfg))
+	e.POST("/echo", func(c echo.Context) error {
+		var body map[string]any
+		if err := c.Bind(&body); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, body)
+	})
+	e.POST("/users/:id/login", func(c echo.Context) error {
+		c.Set("user_id", c.Param("id")) // 模拟路由级认证中间件
+		return c.JSON(http.StatusOK, map[string]string{"access_token": "t0ken", "name": "alice"})
+	})
+	e.POST("/upload", func(c echo.Context) error {
+		if _, err := c.FormFile("file"); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+	e.GET("/stream", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
+		c.Response().WriteHeader(http.StatusOK)
+		for i := 0; i < 3; i++ {
+			_, _ = c.Response().Write([]byte("data: tick\n\n"))
+			c.Response().Flush()
+		}
+		return nil
+	})
+	e.GET("/fail", func(c echo.Context) error { return echo.NewHTTPError(http.StatusBadGateway, "upstream") })
+	return e, logs
+}
+
+func capturedFields(t *testing.T, logs *observer.ObservedLogs) map[string]any {
+	t.Helper()
+	entries := logs.TakeAll()
+	require.Len(t, entries, 1)
+	return entries[0].ContextMap()
+}
+
+func TestBodyCaptureRedactsJSON(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.BodyCapture.RedactFields = []string{"password", "card.number", "*.secret"}
+	e, logs := newBodyCaptureEcho(cfg)
+
+	body := `{"user":{"name":"alice","Password":"p@ss"},"card":{"number":"4111","brand":"visa"},` +
+		`"number":"keep","items":[{"secret":"s1","id":1},{"secret":"s2","id":2}]}`
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "p@ss", "capture must not alter the response sent to the client")
+
+	fields := capturedFields(t, logs)
+	for _, key := range []string{"request_body", "response_body"} {
+		logged := fields[key].(string)
+		assert.NotContains(t, logged, "p@ss", key)
+		assert.NotContains(t, logged, "4111", key)
+		assert.NotContains(t, logged, `"s1"`, key)
+		assert.Contains(t, logged, `"number":"keep"`, "dotted rules match from the root only")
+		assert.Contains(t, logged, `"brand":"visa"`, key)
+		assert.Contains(t, logged, `"id":2`, key)
+	}
+}
+
+func TestBodyCaptureLimits(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.BodyCapture.MaxBytes = 16
+	e, logs := newBodyCaptureEcho(cfg)
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(`{"password":"a-very-long-secret"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	fields := capturedFields(t, logs)
+	assert.Equal(t, "[json body omitted, 33 bytes exceeds capture limit]", fields["request_body"])
+	assert.NotContains(t, fields["response_body"], "secret")
+}
+
+func TestBodyCaptureMultipartAndStreaming(t *testing.T) {
+	e, logs := newBodyCaptureEcho(&config.AppConfig{})
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile("file", "secret.bin")
+	require.NoError(t, err)
+	_, _ = part.Write(bytes.Repeat([]byte{0xff}, 1024))
+	require.NoError(t, w.Close())
+	size := buf.Len()
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &buf)
+	req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	fields := capturedFields(t, logs)
+	assert.Regexp(t, `^\[multipart body omitted, \d+ bytes\]$`, fields["request_body"])
+	assert.LessOrEqual(t, len(fields["request_body"].(string)), 64, "multipart content must not be logged (%d bytes)", size)
+	assert.NotContains(t, fields, "response_body")
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	assert.Equal(t, strings.Repeat("data: tick\n\n", 3), rec.Body.String())
+	assert.True(t, rec.Flushed)
+	fields = capturedFields(t, logs)
+	assert.Equal(t, "[streaming response omitted, 36 bytes]", fields["response_body"])
+}
+
+func TestBodyCaptureErrorResponse(t *testing.T) {
+	e, logs := newBodyCaptureEcho(&config.AppConfig{})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+
+	fields := capturedFields(t, logs)
+	assert.Contains(t, fields["response_body"], "upstream", "error responses should be captured")
+	assert.EqualValues(t, http.StatusBadGateway, fields["status"])
+}
+
+func TestBodyCaptureErrorResponseUnchanged(t *testing.T) {
+	// 开启捕获不应改变错误响应的写出方式：错误响应在压缩等内层中间件之外生成，与未开启捕获时一致
+	serve := func(capture bool) *httptest.ResponseRecorder {
+		cfg := &config.AppConfig{}
+		cfg.Server.Mode = "debug"
+		cfg.AccessLog.Enabled = true
+		cfg.BodyCapture.Enabled = capture
+		cfg.Compression.Algorithms = []string{EncodingGzip}
+		cfg.Compression.MinSize = 1
+		cfg.Compression.ContentTypes = []string{"application/json"}
+		logger := &util.AccessLogger{Logger: zap.NewNop(), Format: util.AccessFormatJSON}
+
+		e := echo.New()
+		e.HTTPErrorHandler = CustomHTTPErrorHandler
+		e.Use(Logger(cfg, logger), Compress(cfg), BodyCapture(cfg))
+		e.GET("/fail", func(c echo.Context) error { return echo.NewHTTPError(http.StatusBadGateway, "upstream") })
+
+		req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+		req.Header.Set(echo.HeaderAcceptEncoding, EncodingGzip)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	plain, captured := serve(false), serve(true)
+	assert.Equal(t, http.StatusBadGateway, captured.Code)
+	assert.Equal(t, plain.Header().Get(echo.HeaderContentEncoding), captured.Header().Get(echo.HeaderContentEncoding))
+	assert.Equal(t, plain.Body.String(), captured.Body.String())
+}
+
+func TestBodyCaptureReleaseMode(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.Server.Mode = "release"
+	cfg.BodyCapture.Routes = []string{"/echo"}
+	cfg.BodyCapture.Users = []string{"u-7"}
+	cfg.BodyCapture.RedactFields = []string{"access_token"}
+	e, logs := newBodyCaptureEcho(cfg)
+
+	send := func(path string) map[string]any {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"name":"alice"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		e.ServeHTTP(httptest.NewRecorder(), req)
+		return capturedFields(t, logs)
+	}
+
+	assert.Equal(t, `{"name":"alice"}`, send("/echo")["request_body"], "configured route")
+	fields := send("/users/u-7/login")
+	assert.Equal(t, `{"access_token":"[REDACTED]","name":"alice"}`, fields["response_body"], "configured user")
+	assert.NotContains(t, send("/users/u-8/login"), "request_body", "other users are not captured")
+}
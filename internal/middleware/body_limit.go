@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/errcode"
+	res "github.com/HoronLee/EchoHub/internal/response"
+	"github.com/HoronLee/EchoHub/internal/util/pathmatch"
+	"github.com/labstack/echo/v4"
+)
+
+// bodyLimitRule 路由请求体上限规则
+type bodyLimitRule struct {
+	method string
+	path   pathmatch.Pattern
+	limit  int64
+}
+
+// BodyLimit 请求体大小限制中间件，超出上限返回 413
+// 上限取第一条匹配的 server.limits.routes 规则（按路由模板匹配），未命中时使用 server.limits.max_body_bytes；
+// 声明了 Content-Length 的请求直接按长度判断，分块传输的请求最多读取上限字节后判断
+func BodyLimit(cfg *config.AppConfig) echo.MiddlewareFunc {
+	limits := cfg.Server.Limits
+	rules := make([]bodyLimitRule, 0, len(limits.Routes))
+	for _, r := range limits.Routes {
+		rules = append(rules, bodyLimitRule{
+			method: strings.ToUpper(r.Method),
+			path:   pathmatch.Parse(r.Path),
+			limit:  r.MaxBodyBytes,
+		})
+	}
+
+	limitFor := func(method, route string) int64 {
+		for _, r := range rules {
+			if r.method != "" && r.method != method {
+				continue
+			}
+			if r.path.Match(route) {
+				return r.limit
+			}
+		}
+		return limits.MaxBodyBytes
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			if req.Body == nil || req.Body == http.NoBody {
+				return next(ctx)
+			}
+			limit := limitFor(req.Method, ctx.Path())
+			if limit <= 0 {
+				return next(ctx)
+			}
+
+			if req.ContentLength > limit {
+				return res.Write(ctx, res.Fail(errcode.RequestEntityTooLarge))
+			}
+			if req.ContentLength < 0 {
+				// 长度未知时多读一个字节用于判断是否超限
+				body, err := io.ReadAll(io.LimitReader(req.Body, limit+1))
+				if err != nil {
+					return res.Write(ctx, res.BadRequest("Failed to read request body", err))
+				}
+				if int64(len(body)) > limit {
+					return res.Write(ctx, res.Fail(errcode.RequestEntityTooLarge))
+				}
+				_ = req.Body.Close()
+				req.Body = io.NopCloser(bytes.NewReader(body))
+				req.ContentLength = int64(len(body))
+			}
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	res "github.com/HoronLee/EchoHub/internal/response"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyLimit(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.Server.Limits.MaxBodyBytes = 16
+	cfg.Server.Limits.Routes = []config.BodyLimitRoute{
+		{Method: http.MethodPost, Path: "/login", MaxBodyBytes: 4},
+		{Path: "/upload/*", MaxBodyBytes: 0},
+	}
+
+	e := echo.New()
+	e.HTTPErrorHandler = CustomHTTPErrorHandler
+	e.Use(RequestID(), BodyLimit(cfg))
+	echoBody := func(c echo.Context) error {
+		b, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, string(b))
+	}
+	e.POST("/login", echoBody)
+	e.POST("/items", echoBody)
+	e.POST("/upload/:name", echoBody)
+
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		chunked bool
+		status  int
+	}{
+		{name: "默认上限内", path: "/items", body: strings.Repeat("a", 16), status: http.StatusOK},
+		{name: "超过默认上限", path: "/items", body: strings.Repeat("a", 17), status: http.StatusRequestEntityTooLarge},
+		{name: "分块传输超限", path: "/items", body: strings.Repeat("a", 17), chunked: true, status: http.StatusRequestEntityTooLarge},
+		{name: "分块传输未超限", path: "/items", body: "abc", chunked: true, status: http.StatusOK},
+		{name: "路由覆盖更严格", path: "/login", body: "12345", status: http.StatusRequestEntityTooLarge},
+		{name: "分组取消限制", path: "/upload/a.bin", body: strings.Repeat("a", 1024), status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, tt.body, rec.Body.String())
+				return
+			}
+			var body res.Response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, 413, body.Code)
+			assert.NotEmpty(t, body.RequestID)
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/errcode"
+	res "github.com/HoronLee/EchoHub/internal/response"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/labstack/echo/v4"
+)
+
+// 支持的内容编码
+const (
+	EncodingBrotli = "br"
+	EncodingZstd   = "zstd"
+	EncodingGzip   = "gzip"
+)
+
+// encoder 可复用的压缩写入器，gzip、brotli、zstd 均满足该接口
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// encoderPool 按编码复用压缩写入器
+type encoderPool struct {
+	name string
+	pool sync.Pool
+}
+
+func newEncoderPool(name, level string) (*encoderPool, error) {
+	p := &encoderPool{name: name}
+	switch name {
+	case EncodingGzip:
+		lvl := pickLevel(level, gzip.BestSpeed, gzip.DefaultCompression, gzip.BestCompression)
+		p.pool.New = func() any {
+			w, _ := gzip.NewWriterLevel(io.Discard, lvl)
+			return w
+		}
+	case EncodingBrotli:
+		lvl := pickLevel(level, brotli.BestSpeed, brotli.DefaultCompression, brotli.BestCompression)
+		p.pool.New = func() any { return brotli.NewWriterLevel(io.Discard, lvl) }
+	case EncodingZstd:
+		lvl := pickLevel(level, zstd.SpeedFastest, zstd.SpeedDefault, zstd.SpeedBestCompression)
+		p.pool.New = func() any {
+			w, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderLevel(lvl), zstd.WithEncoderConcurrency(1))
+			return w
+		}
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm: %s", name)
+	}
+	return p, nil
+}
+
+// pickLevel 将配置中的压缩级别映射为具体算法的级别
+func pickLevel[T any](level string, fastest, def, best T) T {
+	switch level {
+	case "fastest":
+		return fastest
+	case "best":
+		return best
+	default:
+		return def
+	}
+}
+
+func (p *encoderPool) get(w io.Writer) encoder {
+	enc := p.pool.Get().(encoder)
+	enc.Reset(w)
+	return enc
+}
+
+func (p *encoderPool) put(enc encoder) {
+	enc.Reset(io.Discard)
+	p.pool.Put(enc)
+}
+
+// Compress 响应压缩中间件
+// 根据 Accept-Encoding 协商编码（q 值优先，相同时按配置顺序），响应体达到 min_size 且 Content-Type 在允许列表中时才压缩；
+// SSE、已设置 Content-Encoding 的响应、HEAD 与 Range 请求不压缩
+func Compress(cfg *config.AppConfig) echo.MiddlewareFunc {
+	cc := cfg.Compression
+	algorithms := cc.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = []string{EncodingGzip}
+	}
+	pools := make(map[string]*encoderPool, len(algorithms))
+	for _, name := range algorithms {
+		p, err := newEncoderPool(name, cc.Level)
+		if err != nil {
+			panic(err) // 配置错误在启动阶段暴露
+		}
+		pools[name] = p
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			if req.Method == http.MethodHead || req.Header.Get("Range") != "" {
+				return next(ctx)
+			}
+			name := negotiateEncoding(req.Header.Get(echo.HeaderAcceptEncoding), algorithms)
+			if name == "" {
+				return next(ctx)
+			}
+
+			resp := ctx.Response()
+			cw := &compressWriter{
+				ResponseWriter: resp.Writer,
+				pool:           pools[name],
+				minSize:        cc.MinSize,
+				contentTypes:   cc.ContentTypes,
+			}
+			resp.Writer = cw
+			defer func() {
+				cw.close()
+				resp.Writer = cw.ResponseWriter
+			}()
+			return next(ctx)
+		}
+	}
+}
+
+// negotiateEncoding 按 Accept-Encoding 选择编码，没有可用编码时返回空字符串
+func negotiateEncoding(accept string, supported []string) string {
+	if accept == "" {
+		return ""
+	}
+	qs := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.TrimSpace(k) == "q" {
+				if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					q = f
+				}
+			}
+		}
+		if name == "*" {
+			wildcard = q
+		} else if name != "" {
+			qs[name] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, name := range supported {
+		q, ok := qs[name]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// compressWriter 缓冲响应体直到可以判断是否压缩
+type compressWriter struct {
+	http.ResponseWriter
+	pool         *encoderPool
+	minSize      int
+	contentTypes []string
+
+	buf      bytes.Buffer
+	status   int
+	decided  bool
+	compress bool
+	enc      encoder
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		if !w.eligible() {
+			if err := w.decide(false); err != nil {
+				return 0, err
+			}
+		} else {
+			w.buf.Write(b)
+			if w.buf.Len() < w.minSize {
+				return len(b), nil
+			}
+			if err := w.decide(true); err != nil {
+				return 0, err
+			}
+			return len(b), nil
+		}
+	}
+	if w.compress {
+		return w.enc.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// eligible 判断当前响应是否可能被压缩
+func (w *compressWriter) eligible() bool {
+	h := w.Header()
+	if h.Get(echo.HeaderContentEncoding) != "" {
+		return false
+	}
+	if w.status == http.StatusNoContent || w.status == http.StatusNotModified || w.status == http.StatusPartialContent {
+		return false
+	}
+	ct := strings.ToLower(h.Get(echo.HeaderContentType))
+	if ct == "" || strings.HasPrefix(ct, "text/event-stream") {
+		return false
+	}
+	for _, allowed := range w.contentTypes {
+		if strings.HasPrefix(ct, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// decide 确定是否压缩，写出响应头与已缓冲的数据
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	w.compress = compress
+	h := w.Header()
+	if w.eligible() {
+		h.Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+	}
+	if compress {
+		h.Set(echo.HeaderContentEncoding, w.pool.name)
+		h.Del(echo.HeaderContentLength)
+		// 压缩后的字节与原响应不同，强 ETag 降级为弱 ETag
+		if tag := h.Get(res.HeaderETag); tag != "" && !strings.HasPrefix(tag, "W/") {
+			h.Set(res.HeaderETag, "W/"+tag)
+		}
+		w.enc = w.pool.get(w.ResponseWriter)
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if compress {
+		_, err = w.enc.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
+
+// close 在处理器返回后写出剩余数据并结束压缩流
+func (w *compressWriter) close() {
+	if !w.decided {
+		if w.status == 0 && w.buf.Len() == 0 {
+			return // 未写出任何内容，交给错误处理器
+		}
+		_ = w.decide(false)
+	}
+	if w.enc != nil {
+		_ = w.enc.Close()
+		w.pool.put(w.enc)
+		w.enc = nil
+	}
+}
+
+// Flush 实现 http.Flusher，流式响应在首次 Flush 时按已缓冲的数据决定是否压缩
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(w.eligible() && w.buf.Len() >= w.minSize)
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack 实现 http.Hijacker，用于 WebSocket 等协议升级
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
+
+// Unwrap 供 http.ResponseController 访问底层写入器
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Decompress 请求体解压中间件，支持 Content-Encoding: gzip
+// 解压后超过 max_size 返回 413，数据损坏返回 400，其他编码返回 415
+func Decompress(cfg *config.AppConfig) echo.MiddlewareFunc {
+	maxSize := cfg.Compression.Request.MaxSize
+	if maxSize <= 0 {
+		maxSize = 10 << 20
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			encoding := strings.ToLower(strings.TrimSpace(req.Header.Get(echo.HeaderContentEncoding)))
+			if encoding == "" || encoding == "identity" || req.Body == nil || req.Body == http.NoBody {
+				return next(ctx)
+			}
+			if encoding != EncodingGzip {
+				return res.Write(ctx, res.Fail(errcode.UnsupportedMediaType.WithDetail("content_encoding", encoding)))
+			}
+
+			zr, err := gzip.NewReader(req.Body)
+			if err != nil {
+				return res.Write(ctx, res.BadRequest("Malformed gzip body", err))
+			}
+			defer zr.Close()
+
+			// 多读一个字节用于判断是否超限，避免把整个炸弹解压进内存
+			body, err := io.ReadAll(io.LimitReader(zr, maxSize+1))
+			if err != nil {
+				return res.Write(ctx, res.BadRequest("Malformed gzip body", err))
+			}
+			if int64(len(body)) > maxSize {
+				return res.Write(ctx, res.Fail(errcode.RequestEntityTooLarge))
+			}
+			_ = req.Body.Close()
+
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			req.ContentLength = int64(len(body))
+			req.Header.Del(echo.HeaderContentEncoding)
+			req.Header.Set(echo.HeaderContentLength, strconv.Itoa(len(body)))
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCompressEcho() *echo.Echo {
+	cfg := &config.AppConfig{}
+	cfg.Compression.Algorithms = []string{EncodingBrotli, EncodingZstd, EncodingGzip}
+	cfg.Compression.MinSize = 100
+	cfg.Compression.ContentTypes = []string{"application/json", "text/"}
+	cfg.Compression.Request.MaxSize = 1024
+
+	big := strings.Repeat("a", 1000)
+	e := echo.New()
+	e.HTTPErrorHandler = CustomHTTPErrorHandler
+	e.Use(Compress(cfg), Decompress(cfg))
+	e.GET("/big", func(c echo.Context) error { return c.JSON(http.StatusOK, map[string]string{"v": big}) })
+	e.GET("/small", func(c echo.Context) error { return c.JSON(http.StatusOK, map[string]string{"v": "a"}) })
+	e.GET("/png", func(c echo.Context) error { return c.Blob(http.StatusOK, "image/png", []byte(big)) })
+	e.GET("/sse", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
+		_, err := c.Response().Write([]byte("data: " + big + "\n\n"))
+		return err
+	})
+	e.POST("/echo", func(c echo.Context) error {
+		b, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		return c.Blob(http.StatusOK, "text/plain", b)
+	})
+	return e
+}
+
+func decode(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+	var r io.Reader
+	switch encoding {
+	case EncodingGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		r = zr
+	case EncodingBrotli:
+		r = brotli.NewReader(bytes.NewReader(body))
+	case EncodingZstd:
+		zr, err := zstd.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		defer zr.Close()
+		r = zr
+	default:
+		return string(body)
+	}
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestCompress(t *testing.T) {
+	e := newCompressEcho()
+
+	tests := []struct {
+		name     string
+		path     string
+		accept   string
+		encoding string
+		want     string
+	}{
+		{name: "按服务端偏好选择 br", path: "/big", accept: "gzip, br, zstd", encoding: EncodingBrotli, want: `{"v":"aaaa`},
+		{name: "q 值优先", path: "/big", accept: "br;q=0.5, zstd;q=0.8, gzip", encoding: EncodingGzip, want: `{"v":"aaaa`},
+		{name: "zstd", path: "/big", accept: "zstd", encoding: EncodingZstd, want: `{"v":"aaaa`},
+		{name: "通配符", path: "/big", accept: "*;q=0.5, br;q=0", encoding: EncodingZstd, want: `{"v":"aaaa`},
+		{name: "不支持的编码", path: "/big", accept: "deflate", encoding: "", want: `{"v":"aaaa`},
+		{name: "低于最小长度", path: "/small", accept: "gzip", encoding: "", want: `{"v":"a"}`},
+		{name: "不在允许列表中的类型", path: "/png", accept: "gzip", encoding: "", want: `aaaa`},
+		{name: "SSE 不压缩", path: "/sse", accept: "gzip", encoding: "", want: `data: aaaa`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(echo.HeaderAcceptEncoding, tt.accept)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.encoding, rec.Header().Get(echo.HeaderContentEncoding))
+			body := decode(t, tt.encoding, rec.Body.Bytes())
+			assert.True(t, strings.HasPrefix(body, tt.want), "unexpected body %.40q", body)
+			if tt.encoding != "" {
+				assert.Contains(t, rec.Header().Values(echo.HeaderVary), echo.HeaderAcceptEncoding)
+				assert.Less(t, rec.Body.Len(), len(body))
+			}
+		})
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	e := newCompressEcho()
+
+	gz := func(s string) *bytes.Buffer {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write([]byte(s))
+		_ = zw.Close()
+		return &buf
+	}
+	post := func(body io.Reader, encoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/echo", body)
+		req.Header.Set(echo.HeaderContentEncoding, encoding)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(gz("hello"), "gzip")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "hello", rec.Body.String())
+
+	// 解压后超过上限（压缩前很小）
+	rec = post(gz(strings.Repeat("x", 1<<20)), "gzip")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = post(strings.NewReader("not gzip"), "gzip")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = post(strings.NewReader("x"), "compress")
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":415`)
+	assert.Contains(t, rec.Body.String(), `"content_encoding":"compress"`)
+}
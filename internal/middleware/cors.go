@@ -1,88 +1,360 @@
 package middleware
 
 import (
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
 	"github.com/HoronLee/EchoHub/internal/config"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/HoronLee/EchoHub/internal/util/pathmatch"
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
+	"go.uber.org/zap"
+)
+
+// 配置文件未设置时使用的默认值
+var (
+	defaultCORSMethods = []string{
+		echo.GET,
+		echo.POST,
+		echo.PUT,
+		echo.PATCH,
+		echo.DELETE,
+		echo.OPTIONS,
+		echo.HEAD,
+	}
+	defaultCORSHeaders = []string{
+		echo.HeaderOrigin,
+		echo.HeaderContentType,
+		echo.HeaderContentEncoding,
+		echo.HeaderAccept,
+		echo.HeaderAuthorization,
+		echo.HeaderXRequestedWith,
+		"X-CSRF-Token",
+		"If-Match",
+		echo.HeaderXRequestID,
+		"traceparent",
+		"tracestate",
+	}
+	// 暴露的响应头 (浏览器可以访问的响应头)
+	defaultCORSExposeHeaders = []string{
+		echo.HeaderContentLength,
+		echo.HeaderContentEncoding,
+		echo.HeaderContentType,
+		echo.HeaderAuthorization,
+		"ETag",
+		echo.HeaderXRequestID,
+		HeaderRateLimitLimit,
+		HeaderRateLimitRemaining,
+		HeaderRateLimitReset,
+		HeaderRateLimitPolicy,
+		echo.HeaderRetryAfter,
+	}
 )
 
+// defaultCORSMaxAge 预检请求的默认缓存时间 (秒)
+const defaultCORSMaxAge = 86400
+
+// OriginValidator 自定义来源校验，返回 true 表示允许；配置的来源均未命中时调用，可通过 ctx.Path() 区分路由
+type OriginValidator func(ctx echo.Context, origin string) bool
+
+// CORSOption CORS 中间件选项
+type CORSOption func(*corsOptions)
+
+type corsOptions struct {
+	validator OriginValidator
+}
+
+// WithOriginValidator 设置自定义来源校验，如按租户域名查库
+func WithOriginValidator(fn OriginValidator) CORSOption {
+	return func(o *corsOptions) { o.validator = fn }
+}
+
+// corsPolicy 解析后的跨域策略
+type corsPolicy struct {
+	path pathmatch.Pattern // 路由模板，以 * 结尾时按前缀匹配；默认策略不参与匹配
+
+	anyOrigin   bool                // 允许任意来源
+	origins     map[string]struct{} // 精确来源（小写）
+	wildcards   []originWildcard    // 子域名通配
+	patterns    []*regexp.Regexp    // 来源正则
+	credentials bool
+
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+// originWildcard 形如 https://*.example.com[:port] 的来源
+type originWildcard struct {
+	scheme string
+	suffix string // 含前导点，如 .example.com
+	port   string
+}
+
 // CORS 跨域中间件
-// 使用 Echo 官方的 CORS 中件，支持可配置的跨域策略
-func CORS(cfg *config.AppConfig) echo.MiddlewareFunc {
-	// 默认配置
-	corsConfig := middleware.CORSConfig{
-		// 允许所有来源 (开发环境默认)
-		// 生产环境建议通过配置文件限制允许的来源
-		AllowOrigins: []string{"*"},
-		// 允许的 HTTP 方法
-		AllowMethods: []string{
-			echo.GET,
-			echo.POST,
-			echo.PUT,
-			echo.PATCH,
-			echo.DELETE,
-			echo.OPTIONS,
-			echo.HEAD,
-		},
-		// 允许的请求头
-		AllowHeaders: []string{
-			echo.HeaderOrigin,
-			echo.HeaderContentType,
-			echo.HeaderAccept,
-			echo.HeaderAuthorization,
-			echo.HeaderXRequestedWith,
-			"X-CSRF-Token",
-		},
-		// 暴露的响应头 (浏览器可以访问的响应头)
-		ExposeHeaders: []string{
-			echo.HeaderContentLength,
-			echo.HeaderContentEncoding,
-			echo.HeaderContentType,
-			echo.HeaderAuthorization,
-		},
-		// 允许发送凭证 (Cookie、Authorization 等)
-		// 如果设置为 true，AllowOrigins 不能使用 "*"
-		AllowCredentials: false,
-		// 预检请求的缓存时间 (秒)
-		MaxAge: 86400, // 24 小时
-	}
-
-	// 如果配置文件中设置了允许的来源，则使用配置的值
-	if len(cfg.CORS.AllowOrigins) > 0 {
-		corsConfig.AllowOrigins = cfg.CORS.AllowOrigins
-	}
-
-	// 如果配置文件中设置了允许的方法，则使用配置的值
-	if len(cfg.CORS.AllowMethods) > 0 {
-		corsConfig.AllowMethods = cfg.CORS.AllowMethods
-	}
-
-	// 如果配置文件中设置了允许的请求头，则使用配置的值
-	if len(cfg.CORS.AllowHeaders) > 0 {
-		corsConfig.AllowHeaders = cfg.CORS.AllowHeaders
-	}
-
-	// 如果配置文件中设置了暴露的响应头，则使用配置的值
-	if len(cfg.CORS.ExposeHeaders) > 0 {
-		corsConfig.ExposeHeaders = cfg.CORS.ExposeHeaders
-	}
-
-	// 如果配置文件中设置了是否允许凭证，则使用配置的值
-	// 注意：当 AllowCredentials 为 true 时，AllowOrigins 不能为 "*"
-	if cfg.CORS.AllowCredentials {
-		corsConfig.AllowCredentials = true
-		// 如果 AllowOrigins 为 "*" 且 AllowCredentials 为 true，需要将 AllowOrigins 设置为具体域名
-		if len(corsConfig.AllowOrigins) == 1 && corsConfig.AllowOrigins[0] == "*" {
-			// 警告：AllowCredentials 为 true 时，AllowOrigins 不能为 "*"
-			// 这里保持默认，实际使用时应该配置具体的域名
-			corsConfig.AllowOrigins = []string{"*"} // 实际生产环境应配置具体域名
+// 来源支持精确匹配、"*"、子域名通配（https://*.example.com）、正则以及 WithOriginValidator 自定义校验；
+// cors.routes 按路由模板覆盖全局策略。release 模式下 "*" 与 allow_credentials 同时配置时拒绝启动，
+// debug 模式下改为回显请求来源并记录告警。预检响应携带 Access-Control-Max-Age 供浏览器缓存
+func CORS(cfg *config.AppConfig, logger *util.Logger, opts ...CORSOption) echo.MiddlewareFunc {
+	var o corsOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	policies, err := newCORSPolicies(cfg, logger)
+	if err != nil {
+		panic(err) // 配置错误在启动阶段暴露
+	}
+	defaultPolicy := policies[len(policies)-1]
+	policyFor := func(route string) *corsPolicy {
+		for _, p := range policies[:len(policies)-1] {
+			if p.path.Match(route) {
+				return p
+			}
 		}
+		return defaultPolicy
 	}
 
-	// 如果配置文件中设置了预检请求缓存时间，则使用配置的值
-	if cfg.CORS.MaxAge > 0 {
-		corsConfig.MaxAge = cfg.CORS.MaxAge
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			h := ctx.Response().Header()
+			h.Add(echo.HeaderVary, echo.HeaderOrigin)
+
+			origin := req.Header.Get(echo.HeaderOrigin)
+			if origin == "" {
+				return next(ctx)
+			}
+			preflight := req.Method == http.MethodOptions && req.Header.Get(echo.HeaderAccessControlRequestMethod) != ""
+
+			p := policyFor(ctx.Path())
+			allowOrigin, ok := p.allow(ctx, origin, o.validator)
+			if !ok {
+				if preflight {
+					return ctx.NoContent(http.StatusNoContent) // 不附加跨域响应头，由浏览器拒绝
+				}
+				return next(ctx)
+			}
+
+			h.Set(echo.HeaderAccessControlAllowOrigin, allowOrigin)
+			if p.credentials {
+				h.Set(echo.HeaderAccessControlAllowCredentials, "true")
+			}
+			if !preflight {
+				if p.exposeHeaders != "" {
+					h.Set(echo.HeaderAccessControlExposeHeaders, p.exposeHeaders)
+				}
+				return next(ctx)
+			}
+
+			h.Add(echo.HeaderVary, echo.HeaderAccessControlRequestMethod)
+			h.Add(echo.HeaderVary, echo.HeaderAccessControlRequestHeaders)
+			h.Set(echo.HeaderAccessControlAllowMethods, p.allowMethods)
+			if p.allowHeaders != "" {
+				h.Set(echo.HeaderAccessControlAllowHeaders, p.allowHeaders)
+			} else if requested := req.Header.Get(echo.HeaderAccessControlRequestHeaders); requested != "" {
+				h.Set(echo.HeaderAccessControlAllowHeaders, requested)
+			}
+			if p.maxAge != "" {
+				h.Set(echo.HeaderAccessControlMaxAge, p.maxAge)
+			}
+			return ctx.NoContent(http.StatusNoContent)
+		}
+	}
+}
+
+// newCORSPolicies 解析全局与路由策略，默认策略位于最后
+func newCORSPolicies(cfg *config.AppConfig, logger *util.Logger) ([]*corsPolicy, error) {
+	cc := cfg.CORS
+	release := cfg.Server.Mode == "release"
+
+	global := config.CORSRoute{
+		AllowOrigins:        cc.AllowOrigins,
+		AllowOriginPatterns: cc.AllowOriginPatterns,
+		AllowMethods:        orDefault(cc.AllowMethods, defaultCORSMethods),
+		AllowHeaders:        orDefault(cc.AllowHeaders, defaultCORSHeaders),
+		ExposeHeaders:       orDefault(cc.ExposeHeaders, defaultCORSExposeHeaders),
+		AllowCredentials:    &cc.AllowCredentials,
+		MaxAge:              cc.MaxAge,
+	}
+	if len(global.AllowOrigins) == 0 && len(global.AllowOriginPatterns) == 0 {
+		global.AllowOrigins = []string{"*"} // 开发环境默认允许所有来源
+	}
+	if global.MaxAge == 0 {
+		global.MaxAge = defaultCORSMaxAge
 	}
 
-	return middleware.CORSWithConfig(corsConfig)
+	policies := make([]*corsPolicy, 0, len(cc.Routes)+1)
+	for _, r := range cc.Routes {
+		if len(r.AllowOrigins) == 0 && len(r.AllowOriginPatterns) == 0 {
+			r.AllowOrigins, r.AllowOriginPatterns = global.AllowOrigins, global.AllowOriginPatterns
+		}
+		r.AllowMethods = orDefault(r.AllowMethods, global.AllowMethods)
+		r.AllowHeaders = orDefault(r.AllowHeaders, global.AllowHeaders)
+		r.ExposeHeaders = orDefault(r.ExposeHeaders, global.ExposeHeaders)
+		if r.AllowCredentials == nil {
+			r.AllowCredentials = global.AllowCredentials
+		}
+		if r.MaxAge == 0 {
+			r.MaxAge = global.MaxAge
+		}
+		p, err := newCORSPolicy(r, release, logger)
+		if err != nil {
+			return nil, fmt.Errorf("cors route %s: %w", r.Path, err)
+		}
+		policies = append(policies, p)
+	}
+
+	p, err := newCORSPolicy(global, release, logger)
+	if err != nil {
+		return nil, fmt.Errorf("cors: %w", err)
+	}
+	return append(policies, p), nil
+}
+
+func newCORSPolicy(r config.CORSRoute, release bool, logger *util.Logger) (*corsPolicy, error) {
+	p := &corsPolicy{
+		path:          pathmatch.Parse(r.Path),
+		origins:       make(map[string]struct{}),
+		credentials:   *r.AllowCredentials,
+		allowMethods:  strings.Join(r.AllowMethods, ","),
+		allowHeaders:  strings.Join(r.AllowHeaders, ","),
+		exposeHeaders: strings.Join(r.ExposeHeaders, ","),
+	}
+	if r.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(r.MaxAge)
+	}
+
+	for _, origin := range r.AllowOrigins {
+		origin = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(origin)), "/")
+		switch {
+		case origin == "*":
+			p.anyOrigin = true
+		case strings.Contains(origin, "*"):
+			w, err := parseOriginWildcard(origin)
+			if err != nil {
+				return nil, err
+			}
+			p.wildcards = append(p.wildcards, w)
+		case origin != "":
+			p.origins[origin] = struct{}{}
+		}
+	}
+	for _, pattern := range r.AllowOriginPatterns {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid allow_origin_patterns %q: %w", pattern, err)
+		}
+		p.patterns = append(p.patterns, re)
+	}
+
+	if p.anyOrigin && p.credentials {
+		// 回显任意来源并允许凭证等同于关闭同源策略
+		if release {
+			return nil, fmt.Errorf(`allow_origins "*" cannot be combined with allow_credentials in release mode`)
+		}
+		logger.Warn(`CORS allows credentials from any origin, requests from every site will be reflected; configure explicit origins before release`,
+			zap.String("path", r.Path))
+	}
+	return p, nil
+}
+
+// parseOriginWildcard 解析子域名通配来源，* 只能作为主机名最左侧的标签
+func parseOriginWildcard(origin string) (originWildcard, error) {
+	scheme, host, ok := strings.Cut(origin, "://")
+	if !ok || scheme == "" {
+		return originWildcard{}, fmt.Errorf("invalid origin wildcard %q: missing scheme", origin)
+	}
+	var port string
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		host, port = h, p
+	}
+	suffix, ok := strings.CutPrefix(host, "*")
+	if !ok || !strings.HasPrefix(suffix, ".") || strings.Contains(suffix, "*") || strings.Count(suffix, ".") < 2 {
+		return originWildcard{}, fmt.Errorf("invalid origin wildcard %q: use scheme://*.domain.tld", origin)
+	}
+	return originWildcard{scheme: scheme, suffix: suffix, port: port}, nil
+}
+
+// match 判断来源是否为通配域名的子域名（不含域名本身）
+func (w originWildcard) match(scheme, host, port string) bool {
+	if scheme != w.scheme || port != w.port {
+		return false
+	}
+	sub, ok := strings.CutSuffix(host, w.suffix)
+	return ok && validSubdomain(sub)
+}
+
+// validSubdomain 校验子域名部分只包含合法的主机名标签，防止 evil.com/.example.com 之类的构造
+func validSubdomain(sub string) bool {
+	if sub == "" {
+		return false
+	}
+	for _, label := range strings.Split(sub, ".") {
+		if label == "" || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// allow 判断来源是否允许，返回 Access-Control-Allow-Origin 的值
+func (p *corsPolicy) allow(ctx echo.Context, origin string, validator OriginValidator) (string, bool) {
+	if p.anyOrigin {
+		if p.credentials {
+			return origin, true // 凭证请求不接受 "*"，仅 debug 模式可达
+		}
+		return "*", true
+	}
+
+	normalized := strings.ToLower(origin)
+	if _, ok := p.origins[normalized]; ok {
+		return origin, true
+	}
+	if len(p.wildcards) > 0 {
+		if scheme, host, port, ok := splitOrigin(normalized); ok {
+			for _, w := range p.wildcards {
+				if w.match(scheme, host, port) {
+					return origin, true
+				}
+			}
+		}
+	}
+	for _, re := range p.patterns {
+		if re.MatchString(origin) {
+			return origin, true
+		}
+	}
+	if validator != nil && validator(ctx, origin) {
+		return origin, true
+	}
+	return "", false
+}
+
+// splitOrigin 将 scheme://host[:port] 形式的来源拆分，带路径等其他内容时返回 false
+func splitOrigin(origin string) (scheme, host, port string, ok bool) {
+	scheme, host, ok = strings.Cut(origin, "://")
+	if !ok || strings.ContainsAny(host, "/?#@") {
+		return "", "", "", false
+	}
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		host, port = h, p
+	}
+	return scheme, host, port, true
+}
+
+func orDefault(values, def []string) []string {
+	if len(values) > 0 {
+		return values
+	}
+	return def
 }
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCORSEcho(t *testing.T, cfg *config.AppConfig, opts ...CORSOption) *echo.Echo {
+	t.Helper()
+	cfg.Server.Mode = "debug"
+	e := echo.New()
+	e.Use(CORS(cfg, util.NewLogger(cfg), opts...))
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.GET("/api/data", ok)
+	e.GET("/api/public/feed", ok)
+	return e
+}
+
+func corsRequest(e *echo.Echo, method, path, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set(echo.HeaderOrigin, origin)
+	if method == http.MethodOptions {
+		req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodGet)
+		req.Header.Set(echo.HeaderAccessControlRequestHeaders, "X-Custom")
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORSOrigins(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.CORS.AllowOrigins = []string{"https://app.example.com", "https://*.tenant.example.com"}
+	cfg.CORS.AllowOriginPatterns = []string{`https://pr-\d+\.preview\.example\.com`}
+	cfg.CORS.AllowCredentials = true
+	e := newCORSEcho(t, cfg, WithOriginValidator(func(_ echo.Context, origin string) bool {
+		return origin == "https://partner.example.net"
+	}))
+
+	for origin, allowed := range map[string]bool{
+		"https://app.example.com":               true,
+		"https://a.tenant.example.com":          true,
+		"https://a.b.tenant.example.com":        true,
+		"https://pr-42.preview.example.com":     true,
+		"https://partner.example.net":           true,
+		"https://tenant.example.com":            false, // 通配不包含域名本身
+		"http://a.tenant.example.com":           false, // 协议不同
+		"https://a.tenant.example.com:8443":     false, // 端口不同
+		"https://evil.com/.tenant.example.com":  false,
+		"https://a.tenant.example.com.evil.com": false,
+		"https://pr-x.preview.example.com":      false,
+		"https://pr-1.preview.example.com.evil": false,
+		"null":                                  false,
+		"https://app.example.com.attacker.test": false,
+	} {
+		rec := corsRequest(e, http.MethodGet, "/api/data", origin)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		if allowed {
+			assert.Equal(t, origin, rec.Header().Get(echo.HeaderAccessControlAllowOrigin), origin)
+			assert.Equal(t, "true", rec.Header().Get(echo.HeaderAccessControlAllowCredentials), origin)
+		} else {
+			assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin), origin)
+		}
+		assert.Contains(t, rec.Header().Values(echo.HeaderVary), echo.HeaderOrigin)
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.CORS.AllowOrigins = []string{"https://app.example.com"}
+	cfg.CORS.MaxAge = 600
+	e := newCORSEcho(t, cfg)
+
+	rec := corsRequest(e, http.MethodOptions, "/api/data", "https://app.example.com")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "600", rec.Header().Get(echo.HeaderAccessControlMaxAge))
+	assert.Contains(t, rec.Header().Get(echo.HeaderAccessControlAllowMethods), http.MethodPut)
+	assert.Contains(t, rec.Header().Get(echo.HeaderAccessControlAllowHeaders), echo.HeaderAuthorization)
+	assert.Contains(t, rec.Header().Values(echo.HeaderVary), echo.HeaderAccessControlRequestHeaders)
+
+	rec = corsRequest(e, http.MethodOptions, "/api/data", "https://evil.test")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlMaxAge))
+}
+
+func TestCORSRoutes(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.CORS.AllowOrigins = []string{"https://app.example.com"}
+	cfg.CORS.AllowCredentials = true
+	noCredentials := false
+	cfg.CORS.Routes = []config.CORSRoute{
+		{Path: "/api/public/*", AllowOrigins: []string{"*"}, AllowCredentials: &noCredentials, MaxAge: 60},
+	}
+	e := newCORSEcho(t, cfg)
+
+	rec := corsRequest(e, http.MethodGet, "/api/public/feed", "https://anyone.test")
+	assert.Equal(t, "*", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowCredentials))
+	assert.NotEmpty(t, rec.Header().Get(echo.HeaderAccessControlExposeHeaders), "unset fields inherit the global policy")
+
+	rec = corsRequest(e, http.MethodOptions, "/api/public/feed", "https://anyone.test")
+	assert.Equal(t, "60", rec.Header().Get(echo.HeaderAccessControlMaxAge))
+
+	rec = corsRequest(e, http.MethodGet, "/api/data", "https://anyone.test")
+	assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+}
+
+func TestCORSConfigValidation(t *testing.T) {
+	newPolicies := func(mode string, mutate func(cfg *config.AppConfig)) error {
+		cfg := &config.AppConfig{}
+		cfg.Server.Mode = mode
+		mutate(cfg)
+		logCfg := &config.AppConfig{}
+		logCfg.Server.Mode = "debug"
+		_, err := newCORSPolicies(cfg, util.NewLogger(logCfg))
+		return err
+	}
+
+	t.Run("release模式拒绝通配来源与凭证同时使用", func(t *testing.T) {
+		withCredentials := func(cfg *config.AppConfig) { cfg.CORS.AllowCredentials = true }
+		err := newPolicies("release", withCredentials)
+		require.Error(t, err)
+		assert.True(t, strings.Contains(err.Error(), "allow_credentials"))
+
+		credentials := true
+		err = newPolicies("release", func(cfg *config.AppConfig) {
+			cfg.CORS.AllowOrigins = []string{"https://app.example.com"}
+			cfg.CORS.Routes = []config.CORSRoute{{Path: "/x", AllowOrigins: []string{"*"}, AllowCredentials: &credentials}}
+		})
+		assert.Error(t, err)
+
+		assert.NoError(t, newPolicies("debug", withCredentials))
+	})
+
+	t.Run("debug模式回显来源", func(t *testing.T) {
+		cfg := &config.AppConfig{}
+		cfg.CORS.AllowCredentials = true
+		e := newCORSEcho(t, cfg)
+		rec := corsRequest(e, http.MethodGet, "/api/data", "http://localhost:3000")
+		assert.Equal(t, "http://localhost:3000", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	})
+
+	t.Run("无效的通配与正则", func(t *testing.T) {
+		for _, origin := range []string{"https://*", "https://*.com", "https://api.*.example.com", "*.example.com"} {
+			assert.Error(t, newPolicies("debug", func(cfg *config.AppConfig) { cfg.CORS.AllowOrigins = []string{origin} }), origin)
+		}
+		assert.Error(t, newPolicies("debug", func(cfg *config.AppConfig) { cfg.CORS.AllowOriginPatterns = []string{"("} }))
+	})
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/HoronLee/EchoHub/internal/response"
@@ -10,31 +11,38 @@ import (
 )
 
 // CustomHTTPErrorHandler 自定义HTTP错误处理器
-// 将所有错误（包括404、405、500等）统一转换为项目响应格式
+// 将所有错误（包括404、405、500等）统一转换为项目响应格式：
+// *echo.HTTPError 沿用其状态码与字符串消息，业务错误（*errcode.Error）按错误码映射，其他错误返回 500 且不暴露错误内容
 func CustomHTTPErrorHandler(err error, c echo.Context) {
 	if c.Response().Committed {
 		return
 	}
 
-	code := http.StatusInternalServerError
-	message := "Internal Server Error"
-
-	if he, ok := err.(*echo.HTTPError); ok {
-		code = he.Code
-		if he.Message != nil {
-			message = he.Message.(string)
+	var r response.Response
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		message, ok := he.Message.(string)
+		if !ok {
+			// 非字符串消息（如 error、结构体）可能包含内部信息，使用状态码描述
+			message = http.StatusText(he.Code)
 		}
+		r = response.Error(he.Code, he.Code, message, err)
 	} else {
-		message = err.Error()
+		r = response.Fail(err)
 	}
 
-	log.GetLogger().Error("HTTP error",
+	logger := log.GetLogger().WithContext(c.Request().Context())
+	logFn := logger.Error
+	if r.HTTPStatus < http.StatusInternalServerError {
+		logFn = logger.Warn
+	}
+	logFn("HTTP error",
 		zap.String("path", c.Path()),
 		zap.String("method", c.Request().Method),
-		zap.Int("http_status", code),
-		zap.String("message", message),
+		zap.Int("http_status", r.HTTPStatus),
+		zap.Int("business_code", r.Code),
 		zap.Error(err),
 	)
 
-	c.JSON(code, response.Error(code, code, message))
+	_ = response.Write(c, r)
 }
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HoronLee/EchoHub/internal/errcode"
+	res "github.com/HoronLee/EchoHub/internal/response"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomHTTPErrorHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   int
+		wantMsg    string
+	}{
+		{
+			name:       "字符串消息沿用",
+			err:        echo.NewHTTPError(http.StatusMethodNotAllowed, "Method Not Allowed"),
+			wantStatus: http.StatusMethodNotAllowed,
+			wantCode:   http.StatusMethodNotAllowed,
+			wantMsg:    "Method Not Allowed",
+		},
+		{
+			name:       "非字符串消息不 panic 且不暴露内容",
+			err:        echo.NewHTTPError(http.StatusBadRequest, errors.New("secret internal detail")),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   http.StatusBadRequest,
+			wantMsg:    http.StatusText(http.StatusBadRequest),
+		},
+		{
+			name:       "业务错误按错误码映射",
+			err:        fmt.Errorf("lookup: %w", errcode.NotFound),
+			wantStatus: http.StatusNotFound,
+			wantCode:   404,
+			wantMsg:    "资源不存在",
+		},
+		{
+			name:       "普通错误返回 500",
+			err:        errors.New("secret internal detail"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   500,
+			wantMsg:    "服务器内部错误",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = CustomHTTPErrorHandler
+			e.GET("/err", func(c echo.Context) error { return tt.err })
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/err", nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.NotContains(t, rec.Body.String(), "secret")
+			var body res.Response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.wantCode, body.Code)
+			assert.Equal(t, tt.wantMsg, body.Msg)
+		})
+	}
+}
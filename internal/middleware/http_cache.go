@@ -0,0 +1,288 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/httpcache"
+	res "github.com/HoronLee/EchoHub/internal/response"
+	"github.com/labstack/echo/v4"
+)
+
+// HeaderXCache 服务端响应缓存命中情况，取值 HIT 或 MISS
+const HeaderXCache = "X-Cache"
+
+// ETag 条件 GET 中间件
+// 为 GET/HEAD 的 200 响应生成 ETag（处理器已设置时沿用，例如版本号 ETag），
+// 请求携带匹配的 If-None-Match 时改为返回 304；未设置 Cache-Control 时写入 http_cache.default_cache_control。
+// 需位于 Compress 之内，ETag 按未压缩的响应体计算
+func ETag(cfg *config.AppConfig) echo.MiddlewareFunc {
+	hc := cfg.HTTPCache
+	maxSize := hc.ETag.MaxSize
+	if maxSize <= 0 {
+		maxSize = 1 << 20
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !hc.ETag.Enabled && hc.DefaultCacheControl == "" {
+			return next
+		}
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			if req.Method != http.MethodGet && req.Method != http.MethodHead {
+				return next(ctx)
+			}
+
+			resp := ctx.Response()
+			w := &etagWriter{
+				ResponseWriter: resp.Writer,
+				ifNoneMatch:    req.Header.Get(res.HeaderIfNoneMatch),
+				etag:           hc.ETag.Enabled,
+				weak:           hc.ETag.Weak,
+				cacheControl:   hc.DefaultCacheControl,
+				maxSize:        maxSize,
+			}
+			resp.Writer = w
+			defer func() { resp.Writer = w.ResponseWriter }()
+
+			err := next(ctx)
+			w.finish()
+			return err
+		}
+	}
+}
+
+// etagWriter 缓冲 200 响应体以计算 ETag，其他状态码直接写出
+type etagWriter struct {
+	http.ResponseWriter
+	ifNoneMatch  string
+	etag         bool
+	weak         bool
+	cacheControl string
+	maxSize      int
+
+	buf         bytes.Buffer
+	buffering   bool // 已收到 200 状态码，正在缓冲
+	passthrough bool // 已决定直接写出
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	if w.buffering || w.passthrough {
+		return
+	}
+	if code != http.StatusOK {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.cacheControl != "" && w.Header().Get(res.HeaderCacheControl) == "" {
+		w.Header().Set(res.HeaderCacheControl, w.cacheControl)
+	}
+	if !w.etag {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.buffering = true
+}
+
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if !w.buffering && !w.passthrough {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.buf.Len()+len(b) > w.maxSize {
+		if err := w.flushBuffer(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+// flushBuffer 放弃生成 ETag，写出状态码与已缓冲的数据
+func (w *etagWriter) flushBuffer() error {
+	w.buffering, w.passthrough = false, true
+	w.ResponseWriter.WriteHeader(http.StatusOK)
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// finish 在处理器返回后生成 ETag 并写出响应或 304
+func (w *etagWriter) finish() {
+	if !w.buffering {
+		return
+	}
+	w.buffering, w.passthrough = false, true
+
+	h := w.Header()
+	tag := h.Get(res.HeaderETag)
+	if tag == "" {
+		tag = bodyETag(w.buf.Bytes(), w.weak)
+		h.Set(res.HeaderETag, tag)
+	}
+	if w.ifNoneMatch != "" && etagMatch(w.ifNoneMatch, tag) {
+		h.Del(echo.HeaderContentType)
+		h.Del(echo.HeaderContentLength)
+		w.ResponseWriter.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.ResponseWriter.WriteHeader(http.StatusOK)
+	_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+}
+
+// Flush 实现 http.Flusher，流式响应不生成 ETag
+func (w *etagWriter) Flush() {
+	if w.buffering {
+		_ = w.flushBuffer()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack 实现 http.Hijacker
+func (w *etagWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
+
+// Unwrap 供 http.ResponseController 访问底层写入器
+func (w *etagWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// bodyETag 根据响应体摘要生成 ETag
+func bodyETag(body []byte, weak bool) string {
+	sum := sha256.Sum256(body)
+	tag := `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+	if weak {
+		return "W/" + tag
+	}
+	return tag
+}
+
+// etagMatch 按弱比较判断 If-None-Match 是否与 ETag 匹配（RFC 9110 13.1.2）
+func etagMatch(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// CacheTagFunc 根据请求返回缓存标签，返回空字符串时忽略
+type CacheTagFunc func(ctx echo.Context) string
+
+// CacheTag 固定的缓存标签
+func CacheTag(tag string) CacheTagFunc {
+	return func(echo.Context) string { return tag }
+}
+
+// CacheParamTag 由前缀与路径参数组成的缓存标签，例如 CacheParamTag("helloworld", "id") 得到 helloworld:<id>
+func CacheParamTag(prefix, param string) CacheTagFunc {
+	return func(ctx echo.Context) string {
+		if v := ctx.Param(param); v != "" {
+			return prefix + ":" + v
+		}
+		return ""
+	}
+}
+
+// ResponseCache 服务端响应缓存中间件，按路由显式挂载
+// 缓存 GET 的 200 响应，键由路由、路径、排序后的查询参数与主体（用户或匿名）组成；
+// ttl 为 0 时使用 http_cache.response_cache.default_ttl。写操作通过 httpcache.ResponseCache.Invalidate 按标签失效。
+// 设置了 Cache-Control: no-store 或 Set-Cookie 的响应不缓存
+func ResponseCache(rc *httpcache.ResponseCache, ttl time.Duration, tags ...CacheTagFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !rc.Enabled() {
+			return next
+		}
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			if req.Method != http.MethodGet {
+				return next(ctx)
+			}
+
+			names := make([]string, 0, len(tags))
+			for _, tag := range tags {
+				if name := tag(ctx); name != "" {
+					names = append(names, name)
+				}
+			}
+			key, err := rc.Key(req.Context(), responseCacheBase(ctx), names)
+			if err != nil {
+				return next(ctx) // 存储不可用时跳过缓存
+			}
+
+			resp := ctx.Response()
+			if entry, ok := rc.Get(req.Context(), key); ok {
+				h := resp.Header()
+				for k, v := range entry.Header {
+					h[k] = slices.Clone(v)
+				}
+				h.Set(HeaderXCache, "HIT")
+				resp.WriteHeader(entry.Status)
+				_, err := resp.Write(entry.Body)
+				return err
+			}
+
+			resp.Header().Set(HeaderXCache, "MISS")
+			w := &captureWriter{
+				ResponseWriter: resp.Writer,
+				base:           resp.Header().Clone(),
+				limit:          rc.MaxSize(),
+			}
+			resp.Writer = w
+			err = next(ctx)
+			resp.Writer = w.ResponseWriter
+
+			if err != nil || resp.Status != http.StatusOK || !resp.Committed || w.overflow || !cacheable(w.header) {
+				return err
+			}
+			rc.Set(context.WithoutCancel(req.Context()), key,
+				&httpcache.Entry{Status: resp.Status, Header: w.header, Body: w.buf.Bytes()}, ttl)
+			return nil
+		}
+	}
+}
+
+// responseCacheBase 返回区分缓存响应的请求标识
+func responseCacheBase(ctx echo.Context) string {
+	principal := "anon"
+	if userID, ok := ctx.Get("user_id").(string); ok && userID != "" {
+		principal = "user:" + userID
+	}
+	req := ctx.Request()
+	// Encode 按键排序，查询参数顺序不同的请求共享缓存
+	return strings.Join([]string{ctx.Path(), req.URL.Path, req.URL.Query().Encode(), principal}, "\n")
+}
+
+// cacheable 判断处理器设置的响应头是否允许服务端缓存
+func cacheable(h http.Header) bool {
+	if h.Get("Set-Cookie") != "" {
+		return false
+	}
+	return !strings.Contains(strings.ToLower(h.Get(res.HeaderCacheControl)), "no-store")
+}
@@ -0,0 +1,204 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/cache"
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/httpcache"
+	res "github.com/HoronLee/EchoHub/internal/response"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHTTPCacheConfig() *config.AppConfig {
+	cfg := &config.AppConfig{}
+	cfg.Server.Mode = "debug"
+	cfg.HTTPCache.ETag.Enabled = true
+	cfg.HTTPCache.ETag.MaxSize = 64
+	cfg.HTTPCache.DefaultCacheControl = "no-cache"
+	cfg.HTTPCache.ResponseCache.Enabled = true
+	cfg.HTTPCache.ResponseCache.DefaultTTL = time.Minute
+	return cfg
+}
+
+func TestETag(t *testing.T) {
+	cfg := newHTTPCacheConfig()
+	e := echo.New()
+	e.Use(ETag(cfg))
+	e.GET("/body", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"msg": "hello"})
+	})
+	e.GET("/version", func(c echo.Context) error {
+		res.SetVersionETag(c, 3)
+		res.SetCacheControl(c, res.CacheControl{Private: true, MaxAge: time.Minute})
+		return c.String(http.StatusOK, "v3")
+	})
+	e.GET("/large", func(c echo.Context) error {
+		return c.String(http.StatusOK, strings.Repeat("x", 100))
+	})
+	e.GET("/missing", func(c echo.Context) error {
+		return c.String(http.StatusNotFound, "missing")
+	})
+	e.POST("/body", func(c echo.Context) error {
+		return c.String(http.StatusOK, "created")
+	})
+
+	do := func(method, path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set(res.HeaderIfNoneMatch, ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("按响应体生成ETag并返回304", func(t *testing.T) {
+		first := do(http.MethodGet, "/body", "")
+		require.Equal(t, http.StatusOK, first.Code)
+		tag := first.Header().Get(res.HeaderETag)
+		assert.Regexp(t, `^"[A-Za-z0-9_-]+"$`, tag)
+		assert.Equal(t, "no-cache", first.Header().Get(res.HeaderCacheControl))
+		assert.Contains(t, first.Body.String(), "hello")
+
+		rec := do(http.MethodGet, "/body", `"other", W/`+tag)
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+		assert.Equal(t, tag, rec.Header().Get(res.HeaderETag))
+
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/body", `"other"`).Code)
+	})
+
+	t.Run("沿用处理器设置的ETag与Cache-Control", func(t *testing.T) {
+		rec := do(http.MethodGet, "/version", `"3"`)
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Equal(t, `"3"`, rec.Header().Get(res.HeaderETag))
+		assert.Equal(t, "private, max-age=60", rec.Header().Get(res.HeaderCacheControl))
+	})
+
+	t.Run("超出上限、非200与非GET不处理", func(t *testing.T) {
+		rec := do(http.MethodGet, "/large", "*")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, rec.Body.String(), 100)
+		assert.Empty(t, rec.Header().Get(res.HeaderETag))
+
+		rec = do(http.MethodGet, "/missing", "*")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Empty(t, rec.Header().Get(res.HeaderETag))
+
+		rec = do(http.MethodPost, "/body", "*")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(res.HeaderETag))
+	})
+}
+
+func TestResponseCache(t *testing.T) {
+	cfg := newHTTPCacheConfig()
+	logger := util.NewLogger(cfg)
+	rc := httpcache.NewResponseCache(cfg, cache.NewMemory(0, time.Minute), logger)
+
+	var calls atomic.Int32
+	e := echo.New()
+	e.Use(ETag(cfg))
+	fakeAuth := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if u := c.Request().Header.Get("X-Test-User"); u != "" {
+				c.Set("user_id", u)
+			}
+			return next(c)
+		}
+	}
+	e.GET("/items/:id", func(c echo.Context) error {
+		n := calls.Add(1)
+		if c.QueryParam("nostore") != "" {
+			res.SetCacheControl(c, res.CacheControl{NoStore: true})
+		}
+		c.Response().Header().Set("X-Item", c.Param("id"))
+		return c.String(http.StatusOK, fmt.Sprintf("item %s #%d", c.Param("id"), n))
+	}, fakeAuth, ResponseCache(rc, 0, CacheTag("items"), CacheParamTag("item", "id")))
+
+	do := func(path, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if user != "" {
+			req.Header.Set("X-Test-User", user)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := do("/items/1?a=1&b=2", "")
+	assert.Equal(t, "MISS", first.Header().Get(HeaderXCache))
+	assert.Equal(t, "item 1 #1", first.Body.String())
+
+	hit := do("/items/1?b=2&a=1", "")
+	assert.Equal(t, "HIT", hit.Header().Get(HeaderXCache))
+	assert.Equal(t, "item 1 #1", hit.Body.String(), "query order does not matter")
+	assert.Equal(t, "1", hit.Header().Get("X-Item"))
+	assert.Equal(t, first.Header().Get(res.HeaderETag), hit.Header().Get(res.HeaderETag))
+
+	t.Run("按主体与查询参数区分", func(t *testing.T) {
+		assert.Equal(t, "MISS", do("/items/1?a=1&b=2", "alice").Header().Get(HeaderXCache))
+		assert.Equal(t, "HIT", do("/items/1?a=1&b=2", "alice").Header().Get(HeaderXCache))
+		assert.Equal(t, "MISS", do("/items/1?a=2&b=2", "").Header().Get(HeaderXCache))
+	})
+
+	t.Run("按标签失效", func(t *testing.T) {
+		assert.Equal(t, "MISS", do("/items/2", "").Header().Get(HeaderXCache))
+		assert.Equal(t, "HIT", do("/items/2", "").Header().Get(HeaderXCache))
+
+		rc.Invalidate(t.Context(), "item:1")
+		assert.Equal(t, "MISS", do("/items/1?a=1&b=2", "").Header().Get(HeaderXCache))
+		assert.Equal(t, "HIT", do("/items/2", "").Header().Get(HeaderXCache), "other items stay cached")
+
+		rc.Invalidate(t.Context(), "items")
+		assert.Equal(t, "MISS", do("/items/2", "").Header().Get(HeaderXCache))
+	})
+
+	t.Run("no-store响应不缓存", func(t *testing.T) {
+		do("/items/3?nostore=1", "")
+		assert.Equal(t, "MISS", do("/items/3?nostore=1", "").Header().Get(HeaderXCache))
+	})
+}
+
+func TestETagWithCompression(t *testing.T) {
+	cfg := newHTTPCacheConfig()
+	cfg.HTTPCache.ETag.MaxSize = 4096
+	cfg.Compression.Algorithms = []string{EncodingGzip}
+	cfg.Compression.MinSize = 100
+	cfg.Compression.ContentTypes = []string{"text/"}
+
+	e := echo.New()
+	e.Use(Compress(cfg), ETag(cfg))
+	e.GET("/big", func(c echo.Context) error { return c.String(http.StatusOK, strings.Repeat("a", 1000)) })
+
+	do := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/big", nil)
+		req.Header.Set(echo.HeaderAcceptEncoding, EncodingGzip)
+		if ifNoneMatch != "" {
+			req.Header.Set(res.HeaderIfNoneMatch, ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 压缩后的表示与原响应字节不同，强 ETag 降级为弱 ETag，且仍可用于条件请求
+	rec := do("")
+	assert.Equal(t, EncodingGzip, rec.Header().Get(echo.HeaderContentEncoding))
+	tag := rec.Header().Get(res.HeaderETag)
+	assert.True(t, strings.HasPrefix(tag, `W/"`), tag)
+
+	rec = do(tag)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.Bytes())
+}
@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/idempotency"
+	res "github.com/HoronLee/EchoHub/internal/response"
+	"github.com/HoronLee/EchoHub/internal/service"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// HeaderIdempotentReplayed 回放已保存响应时附加的响应头
+const HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+// idempotencySkipHeaders 不随响应保存的头，由回放时的外层中间件重新生成
+var idempotencySkipHeaders = []string{
+	echo.HeaderContentLength,
+	echo.HeaderContentEncoding,
+	echo.HeaderVary,
+	"Date",
+}
+
+// Idempotency Idempotency-Key 中间件
+// 携带幂等键的 idempotency.methods 请求按“主体 + 幂等键”去重：首次请求的响应被保存，重试时直接回放；
+// 相同幂等键的请求仍在处理中返回 409，幂等键被用于不同的请求（方法、路径或请求体不同）返回 422。
+// 5xx 响应与处理器返回的错误不保存，客户端可使用同一幂等键重试。
+// 按用户区分主体需挂载在 JwtAuth 之后；未认证的请求只按幂等键区分，见 idempotencySubject
+func Idempotency(cfg *config.AppConfig, store idempotency.Store, logger *util.Logger) echo.MiddlewareFunc {
+	ic := cfg.Idempotency
+	header := ic.Header
+	if header == "" {
+		header = "Idempotency-Key"
+	}
+	methods := make([]string, 0, len(ic.Methods))
+	for _, m := range ic.Methods {
+		methods = append(methods, strings.ToUpper(m))
+	}
+	maxKeyLength := ic.MaxKeyLength
+	if maxKeyLength <= 0 {
+		maxKeyLength = 255
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !ic.Enabled {
+			return next
+		}
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			key := req.Header.Get(header)
+			path := ctx.Path()
+			if key == "" || !slices.Contains(methods, req.Method) || path == "" || path == "/api/v1/*" {
+				return next(ctx)
+			}
+			if len(key) > maxKeyLength {
+				return res.Write(ctx, res.BadRequest(header+" is too long"))
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return err // 由 BodyLimit 等中间件返回的读取错误交给错误处理器
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			log := logger.WithContext(req.Context())
+			storeKey := idempotencySubject(ctx) + ":" + key
+			fingerprint := requestFingerprint(req.Method, req.URL.RequestURI(), body)
+			token := idempotency.NewToken()
+
+			rec, err := store.Acquire(req.Context(), storeKey, fingerprint, token, ic.LockTTL)
+			if err != nil {
+				// 存储不可用时直接处理，避免幂等组件故障导致写接口不可用
+				log.Warn("Idempotency store unavailable", zap.Error(err))
+				return next(ctx)
+			}
+			if rec != nil {
+				switch {
+				case rec.Fingerprint != fingerprint:
+					return res.Write(ctx, res.Fail(service.ErrIdempotencyKeyReused))
+				case !rec.Completed:
+					return res.Write(ctx, res.Fail(service.ErrIdempotencyInProgress))
+				default:
+					return replayResponse(ctx, rec.Response)
+				}
+			}
+
+			// 保存与释放不受客户端断开影响
+			storeCtx := context.WithoutCancel(req.Context())
+			finished := false
+			defer func() {
+				if finished {
+					return
+				}
+				// 处理器 panic 时释放处理中记录，由外层 Recovery 处理 panic
+				if err := store.Release(storeCtx, storeKey, token); err != nil {
+					log.Warn("Failed to release idempotency key", zap.Error(err))
+				}
+			}()
+
+			w := &captureWriter{
+				ResponseWriter: ctx.Response().Writer,
+				base:           ctx.Response().Header().Clone(),
+				limit:          ic.MaxResponseBytes,
+			}
+			ctx.Response().Writer = w
+			err = next(ctx)
+			ctx.Response().Writer = w.ResponseWriter
+			finished = true
+
+			status := ctx.Response().Status
+			if err != nil || !ctx.Response().Committed || status >= http.StatusInternalServerError || w.overflow {
+				if w.overflow {
+					log.Warn("Idempotent response too large to store", zap.Int("max_response_bytes", ic.MaxResponseBytes))
+				}
+				if rerr := store.Release(storeCtx, storeKey, token); rerr != nil {
+					log.Warn("Failed to release idempotency key", zap.Error(rerr))
+				}
+				return err
+			}
+
+			resp := &idempotency.Response{Status: status, Header: w.header, Body: w.buf.Bytes()}
+			if serr := store.Complete(storeCtx, storeKey, token, resp, ic.TTL); serr != nil {
+				log.Warn("Failed to store idempotent response", zap.Error(serr))
+			}
+			return nil
+		}
+	}
+}
+
+// idempotencySubject 返回幂等键所属的主体，已认证请求按用户区分
+// 未认证的请求不按客户端 IP 区分：移动网络切换等情况下重试的 IP 可能变化，按 IP 区分会导致重复创建。
+// 因此所有未认证请求共享同一命名空间，客户端须使用不可猜测的随机幂等键（如 UUID v4）；
+// 不同客户端的键即使碰撞，请求指纹不同时也只会返回 422 而不会回放他人的响应
+func idempotencySubject(ctx echo.Context) string {
+	if userID, ok := ctx.Get("user_id").(string); ok && userID != "" {
+		return "user:" + userID
+	}
+	return "anonymous"
+}
+
+// requestFingerprint 计算请求指纹，用于识别幂等键被复用于不同的请求
+func requestFingerprint(method, uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + uri + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// replayResponse 回放已保存的响应
+func replayResponse(ctx echo.Context, saved *idempotency.Response) error {
+	h := ctx.Response().Header()
+	for k, v := range saved.Header {
+		h[k] = slices.Clone(v)
+	}
+	h.Set(HeaderIdempotentReplayed, "true")
+	if ct := h.Get(echo.HeaderContentType); ct != "" {
+		return ctx.Blob(saved.Status, ct, saved.Body)
+	}
+	ctx.Response().WriteHeader(saved.Status)
+	_, err := ctx.Response().Write(saved.Body)
+	return err
+}
+
+// captureWriter 在写出响应的同时记录响应头与响应体
+type captureWriter struct {
+	http.ResponseWriter
+	base     http.Header // 进入处理器前已由外层中间件设置的响应头（请求ID、限流等），不保存
+	limit    int
+	header   http.Header
+	buf      bytes.Buffer
+	overflow bool
+}
+
+func (w *captureWriter) WriteHeader(code int) {
+	// 只保存处理器设置的响应头
+	w.header = make(http.Header)
+	for k, v := range w.Header() {
+		if slices.Contains(idempotencySkipHeaders, k) || slices.Equal(w.base[k], v) {
+			continue
+		}
+		w.header[k] = slices.Clone(v)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	if !w.overflow {
+		if w.limit > 0 && w.buf.Len()+len(b) > w.limit {
+			w.overflow = true
+			w.buf.Reset()
+		} else {
+			w.buf.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush 实现 http.Flusher
+func (w *captureWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack 实现 http.Hijacker，协议升级后的连接不再保存响应
+func (w *captureWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		w.overflow = true
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
+
+// Unwrap 供 http.ResponseController 访问底层写入器
+func (w *captureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/idempotency"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotency(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.Server.Mode = "debug"
+	cfg.Idempotency.Enabled = true
+	cfg.Idempotency.Methods = []string{"post"}
+	cfg.Idempotency.TTL = time.Hour
+	cfg.Idempotency.LockTTL = time.Minute
+	cfg.Idempotency.MaxKeyLength = 16
+	logger := util.NewLogger(cfg)
+
+	var created atomic.Int32
+	entered, block := make(chan struct{}), make(chan struct{})
+	e := echo.New()
+	e.HTTPErrorHandler = CustomHTTPErrorHandler
+	e.Use(RequestID())
+	fakeAuth := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", c.Request().Header.Get("X-Test-User"))
+			return next(c)
+		}
+	}
+	idem := Idempotency(cfg, idempotency.NewMemoryStore(), logger)
+	e.POST("/items", func(c echo.Context) error {
+		if c.QueryParam("wait") != "" {
+			entered <- struct{}{}
+			<-block
+		}
+		if c.QueryParam("fail") != "" {
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{"msg": "unavailable"})
+		}
+		n := created.Add(1)
+		c.Response().Header().Set(echo.HeaderLocation, fmt.Sprintf("/items/%d", n))
+		return c.JSON(http.StatusCreated, map[string]int32{"id": n})
+	}, fakeAuth, idem)
+	e.GET("/items", func(c echo.Context) error {
+		return c.String(http.StatusOK, "list")
+	}, fakeAuth, idem)
+
+	do := func(method, target, key, user, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		if user != "" {
+			req.Header.Set("X-Test-User", user)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("未携带幂等键时每次都执行", func(t *testing.T) {
+		before := created.Load()
+		do(http.MethodPost, "/items", "", "u1", `{}`)
+		do(http.MethodPost, "/items", "", "u1", `{}`)
+		assert.Equal(t, before+2, created.Load())
+	})
+
+	t.Run("重试回放首次响应", func(t *testing.T) {
+		first := do(http.MethodPost, "/items", "k1", "u1", `{"name":"a"}`)
+		require.Equal(t, http.StatusCreated, first.Code)
+		before := created.Load()
+
+		retry := do(http.MethodPost, "/items", "k1", "u1", `{"name":"a"}`)
+		assert.Equal(t, before, created.Load())
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, first.Header().Get(echo.HeaderLocation), retry.Header().Get(echo.HeaderLocation))
+		assert.Equal(t, "true", retry.Header().Get(HeaderIdempotentReplayed))
+		assert.Empty(t, first.Header().Get(HeaderIdempotentReplayed))
+		// 请求ID 等外层中间件设置的头不随响应回放
+		assert.NotEqual(t, first.Header().Get(echo.HeaderXRequestID), retry.Header().Get(echo.HeaderXRequestID))
+	})
+
+	t.Run("幂等键用于不同请求体返回422", func(t *testing.T) {
+		rec := do(http.MethodPost, "/items", "k1", "u1", `{"name":"b"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":10007`)
+	})
+
+	t.Run("幂等键按主体隔离", func(t *testing.T) {
+		before := created.Load()
+		rec := do(http.MethodPost, "/items", "k1", "u2", `{"name":"a"}`)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Empty(t, rec.Header().Get(HeaderIdempotentReplayed))
+		assert.Equal(t, before+1, created.Load())
+	})
+
+	t.Run("未认证请求的重试不受客户端IP变化影响", func(t *testing.T) {
+		anonymous := func(ip string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"m"}`))
+			req.RemoteAddr = ip + ":1234"
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set("Idempotency-Key", "anon-1")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			return rec
+		}
+		first := anonymous("192.0.2.1")
+		require.Equal(t, http.StatusCreated, first.Code)
+		before := created.Load()
+
+		retry := anonymous("198.51.100.7")
+		assert.Equal(t, before, created.Load())
+		assert.Equal(t, "true", retry.Header().Get(HeaderIdempotentReplayed))
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+	})
+
+	t.Run("并发的重复请求返回409", func(t *testing.T) {
+		done := make(chan *httptest.ResponseRecorder)
+		go func() { done <- do(http.MethodPost, "/items?wait=1", "k2", "u1", `{}`) }()
+
+		<-entered
+		rec := do(http.MethodPost, "/items?wait=1", "k2", "u1", `{}`)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":10008`)
+
+		close(block)
+		assert.Equal(t, http.StatusCreated, (<-done).Code)
+		assert.Equal(t, "true", do(http.MethodPost, "/items?wait=1", "k2", "u1", `{}`).Header().Get(HeaderIdempotentReplayed))
+	})
+
+	t.Run("5xx响应不保存", func(t *testing.T) {
+		assert.Equal(t, http.StatusServiceUnavailable, do(http.MethodPost, "/items?fail=1", "k3", "u1", `{}`).Code)
+		rec := do(http.MethodPost, "/items?fail=1", "k3", "u1", `{}`)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Empty(t, rec.Header().Get(HeaderIdempotentReplayed))
+	})
+
+	t.Run("幂等键过长返回400", func(t *testing.T) {
+		rec := do(http.MethodPost, "/items", strings.Repeat("k", 17), "u1", `{}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("未配置的方法忽略幂等键", func(t *testing.T) {
+		do(http.MethodGet, "/items", "k4", "u1", "")
+		rec := do(http.MethodGet, "/items", "k4", "u1", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(HeaderIdempotentReplayed))
+	})
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/netip"
+
+	"github.com/HoronLee/EchoHub/internal/errcode"
+	"github.com/HoronLee/EchoHub/internal/ipfilter"
+	res "github.com/HoronLee/EchoHub/internal/response"
+	"github.com/labstack/echo/v4"
+)
+
+// IPFilter IP 访问控制中间件
+// group 为空时只检查全局名单，否则额外检查对应的命名名单；客户端 IP 由 Echo 的 IPExtractor 按受信任代理解析
+func IPFilter(filter *ipfilter.Filter, group string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if !filter.Enabled() {
+				return next(ctx)
+			}
+			ip, err := netip.ParseAddr(ctx.RealIP())
+			if err != nil || !filter.Allowed(group, ip) {
+				return res.Write(ctx, res.Fail(errcode.Forbidden))
+			}
+			return next(ctx)
+		}
+	}
+}
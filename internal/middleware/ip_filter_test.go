@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/ipfilter"
+	res "github.com/HoronLee/EchoHub/internal/response"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPFilter(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.Server.Mode = "debug"
+	cfg.IPFilter.Enabled = true
+	cfg.IPFilter.Deny = []string{"203.0.113.0/24"}
+	cfg.IPFilter.Groups = map[string]config.IPList{"admin": {Allow: []string{"127.0.0.1"}}}
+	filter, err := ipfilter.NewFilter(cfg, util.NewLogger(cfg))
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.HTTPErrorHandler = CustomHTTPErrorHandler
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.Use(RequestID(), IPFilter(filter, ""))
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.GET("/public", ok)
+	admin := e.Group("/admin", IPFilter(filter, "admin"))
+	admin.GET("/stats", ok)
+
+	do := func(path, remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remote + ":1234"
+		req.Header.Set(echo.HeaderXForwardedFor, "127.0.0.1") // 未配置受信任代理时忽略
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, do("/public", "198.51.100.1").Code)
+	assert.Equal(t, http.StatusOK, do("/admin/stats", "127.0.0.1").Code)
+	assert.Equal(t, http.StatusForbidden, do("/admin/stats", "198.51.100.1").Code)
+
+	rec := do("/public", "203.0.113.5")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	var body res.Response
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, 403, body.Code)
+	assert.NotEmpty(t, body.RequestID)
+
+	// 名单更新后立即生效
+	cfg.IPFilter.Deny = nil
+	require.NoError(t, filter.Update(cfg))
+	assert.Equal(t, http.StatusOK, do("/public", "203.0.113.5").Code)
+}
@@ -1,36 +1,256 @@
 package middleware
 
 import (
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/HoronLee/EchoHub/internal/config"
 	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/HoronLee/EchoHub/internal/util/pathmatch"
+	"github.com/HoronLee/EchoHub/internal/util/requestid"
 	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
-// Logger Echo日志中间件
-func Logger(logger *util.Logger) echo.MiddlewareFunc {
+// redacted 脱敏后的占位值
+const redacted = "[REDACTED]"
+
+// 访问日志可选字段
+const (
+	accessFieldRoute     = "route"
+	accessFieldQuery     = "query"
+	accessFieldRequestID = "request_id"
+	accessFieldTraceID   = "trace_id"
+	accessFieldUserID    = "user_id"
+	accessFieldReferer   = "referer"
+	accessFieldUserAgent = "user_agent"
+	accessFieldBytesIn   = "bytes_in"
+	accessFieldBytesOut  = "bytes_out"
+	accessFieldHeaders   = "headers"
+)
+
+// Logger 访问日志中间件
+// method、path、status、latency、ip 总是记录，其余字段由 access_log.fields 选择；查询参数与请求头按配置脱敏。
+// 状态码 >= 500 记为 Error，4xx 记为 Warn；成功请求可按路由采样，exclude_paths 中的路径不记录；BodyCapture 捕获的请求/响应体附加为 request_body、response_body 字段
+func Logger(cfg *config.AppConfig, logger *util.AccessLogger) echo.MiddlewareFunc {
+	ac := cfg.AccessLog
+	fields := make(map[string]bool, len(ac.Fields))
+	for _, f := range ac.Fields {
+		fields[strings.ToLower(f)] = true
+	}
+	redactParams := make(map[string]bool, len(ac.RedactQueryParams))
+	for _, p := range ac.RedactQueryParams {
+		redactParams[strings.ToLower(p)] = true
+	}
+	redactHeaders := make(map[string]bool, len(ac.RedactHeaders))
+	for _, h := range ac.RedactHeaders {
+		redactHeaders[http.CanonicalHeaderKey(h)] = true
+	}
+	excluded := pathmatch.ParseList(ac.ExcludePaths)
+	sampler := util.NewSampler(ac.Sampling.Initial, ac.Sampling.Thereafter, ac.Sampling.Tick)
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !ac.Enabled {
+			return next
+		}
 		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			if excluded.Match(req.URL.Path, ctx.Path()) {
+				return next(ctx)
+			}
+
 			start := time.Now()
+			var body *countingReader
+			if req.Body != nil && req.Body != http.NoBody {
+				body = &countingReader{ReadCloser: req.Body}
+				req.Body = body
+			}
 
 			err := next(ctx)
 
 			latency := time.Since(start)
-			req := ctx.Request()
+			status := responseStatus(ctx, err)
+			if status < http.StatusBadRequest && !sampler.Allow(req.Method+" "+ctx.Path()) {
+				return err
+			}
 
-			logger.Info("HTTP Request",
-				zap.Int("status", ctx.Response().Status),
+			level := zapcore.InfoLevel
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = zapcore.ErrorLevel
+			case status >= http.StatusBadRequest:
+				level = zapcore.WarnLevel
+			}
+
+			query := redactQuery(req.URL.RawQuery, redactParams)
+			userID, _ := ctx.Get("user_id").(string)
+			bytesIn := req.ContentLength
+			if body != nil && body.n > bytesIn {
+				bytesIn = body.n
+			}
+
+			if logger.Format == util.AccessFormatCombined {
+				logger.Log(level, combinedLine(ctx, start, query, userID, status))
+				return err
+			}
+
+			entry := []zap.Field{
 				zap.String("method", req.Method),
 				zap.String("path", req.URL.Path),
-				zap.String("query", req.URL.RawQuery),
-				zap.String("ip", ctx.RealIP()),
+				zap.Int("status", status),
 				zap.Duration("latency", latency),
-				zap.String("user-agent", req.UserAgent()),
-				zap.Error(err),
-			)
-
+				zap.String("ip", ctx.RealIP()),
+			}
+			if fields[accessFieldRoute] {
+				entry = append(entry, zap.String("route", ctx.Path()))
+			}
+			if fields[accessFieldQuery] && query != "" {
+				entry = append(entry, zap.String("query", query))
+			}
+			if fields[accessFieldRequestID] {
+				if id := requestid.FromContext(req.Context()); id != "" {
+					entry = append(entry, zap.String("request_id", id))
+				}
+			}
+			if fields[accessFieldTraceID] {
+				if sc := trace.SpanContextFromContext(req.Context()); sc.IsValid() {
+					entry = append(entry, zap.String("trace_id", sc.TraceID().String()))
+				}
+			}
+			if fields[accessFieldUserID] && userID != "" {
+				entry = append(entry, zap.String("user_id", userID))
+			}
+			if fields[accessFieldReferer] && req.Referer() != "" {
+				entry = append(entry, zap.String("referer", req.Referer()))
+			}
+			if fields[accessFieldUserAgent] {
+				entry = append(entry, zap.String("user_agent", req.UserAgent()))
+			}
+			if fields[accessFieldBytesIn] {
+				entry = append(entry, zap.Int64("bytes_in", max(bytesIn, 0)))
+			}
+			if fields[accessFieldBytesOut] {
+				entry = append(entry, zap.Int64("bytes_out", ctx.Response().Size))
+			}
+			if fields[accessFieldHeaders] && len(ac.Headers) > 0 {
+				entry = append(entry, zap.Any("headers", selectHeaders(req.Header, ac.Headers, redactHeaders)))
+			}
+			if captured, ok := ctx.Get(capturedBodiesKey).(*capturedBodies); ok {
+				if err != nil && !ctx.Response().Committed {
+					captured.writeError(ctx, err)
+				}
+				if b := captured.requestBody(); b != "" {
+					entry = append(entry, zap.String("request_body", b))
+				}
+				if b := captured.responseBody(); b != "" {
+					entry = append(entry, zap.String("response_body", b))
+				}
+			}
+			if err != nil {
+				entry = append(entry, zap.Error(err))
+			}
+			logger.Log(level, "HTTP Request", entry...)
 			return err
 		}
 	}
 }
+
+// countingReader 统计实际读取的请求体字节数，用于分块传输等未声明长度的请求
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// redactQuery 将需要脱敏的查询参数值替换为 [REDACTED]，其余参数保持原样
+func redactQuery(raw string, params map[string]bool) string {
+	if raw == "" || len(params) == 0 {
+		return raw
+	}
+	parts := strings.Split(raw, "&")
+	for i, part := range parts {
+		key, _, _ := strings.Cut(part, "=")
+		if name, err := url.QueryUnescape(key); err == nil {
+			key = name
+		}
+		if params[strings.ToLower(key)] {
+			k, _, _ := strings.Cut(part, "=")
+			parts[i] = k + "=" + redacted
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// selectHeaders 取出需要记录的请求头，敏感请求头的值替换为 [REDACTED]
+func selectHeaders(header http.Header, names []string, redact map[string]bool) map[string]string {
+	out := make(map[string]string, len(names))
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(name)
+		values := header.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		if redact[name] {
+			out[name] = redacted
+			continue
+		}
+		out[name] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+// combinedLine 生成 Apache/NCSA combined 格式的日志行：
+// host ident authuser [time] "request" status bytes "referer" "user-agent"
+func combinedLine(ctx echo.Context, start time.Time, query, userID string, status int) string {
+	req := ctx.Request()
+	uri := req.URL.EscapedPath()
+	if query != "" {
+		uri += "?" + query
+	}
+	bytesOut := "-"
+	if size := ctx.Response().Size; size > 0 {
+		bytesOut = strconv.FormatInt(size, 10)
+	}
+
+	var b strings.Builder
+	b.WriteString(ctx.RealIP())
+	b.WriteString(" - ")
+	b.WriteString(orDash(userID))
+	b.WriteString(" [")
+	b.WriteString(start.Format("02/Jan/2006:15:04:05 -0700"))
+	b.WriteString(`] "`)
+	b.WriteString(escapeQuotes(req.Method + " " + uri + " " + req.Proto))
+	b.WriteString(`" `)
+	b.WriteString(strconv.Itoa(status))
+	b.WriteString(" ")
+	b.WriteString(bytesOut)
+	b.WriteString(` "`)
+	b.WriteString(orDash(escapeQuotes(req.Referer())))
+	b.WriteString(`" "`)
+	b.WriteString(orDash(escapeQuotes(req.UserAgent())))
+	b.WriteString(`"`)
+	return b.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// escapeQuotes 转义请求头中的引号与反斜杠，防止伪造日志字段
+func escapeQuotes(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
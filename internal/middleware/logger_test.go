@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newAccessLogEcho(cfg *config.AppConfig, format string) (*echo.Echo, *observer.ObservedLogs) {
+	cfg.AccessLog.Enabled = true
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := &util.AccessLogger{Logger: zap.New(core), Format: format}
+
+	e := echo.New()
+	e.HTTPErrorHandler = CustomHTTPErrorHandler
+	e.Use(RequestID(), Logger(cfg, logger))
+	e.POST("/items/:id", func(c echo.Context) error {
+		c.Set("user_id", "u-1")
+		return c.String(http.StatusCreated, "created")
+	})
+	e.GET("/healthz", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	e.GET("/fail", func(c echo.Context) error { return echo.NewHTTPError(http.StatusBadGateway, "upstream") })
+	return e, logs
+}
+
+func TestAccessLogFields(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.AccessLog.Fields = []string{"route", "query", "request_id", "user_id", "referer", "bytes_in", "bytes_out", "headers"}
+	cfg.AccessLog.Headers = []string{"Authorization", "X-Client"}
+	cfg.AccessLog.RedactQueryParams = []string{"token"}
+	cfg.AccessLog.RedactHeaders = []string{"authorization"}
+	e, logs := newAccessLogEcho(cfg, util.AccessFormatJSON)
+
+	req := httptest.NewRequest(http.MethodPost, "/items/42?Token=s3cret&page=2", strings.NewReader("payload"))
+	req.Header.Set(echo.HeaderAuthorization, "Bearer s3cret")
+	req.Header.Set("X-Client", "cli")
+	req.Header.Set("Referer", "https://app.example.com/")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, zapcore.InfoLevel, entry.Level)
+	fields := entry.ContextMap()
+	assert.Equal(t, "/items/:id", fields["route"])
+	assert.Equal(t, "Token=[REDACTED]&page=2", fields["query"])
+	assert.Equal(t, int64(201), fields["status"])
+	assert.Equal(t, "u-1", fields["user_id"])
+	assert.Equal(t, "https://app.example.com/", fields["referer"])
+	assert.Equal(t, int64(7), fields["bytes_in"])
+	assert.Equal(t, int64(len("created")), fields["bytes_out"])
+	assert.NotEmpty(t, fields["request_id"])
+	assert.NotContains(t, fields, "user_agent", "fields not selected are omitted")
+	assert.Equal(t, map[string]string{"Authorization": "[REDACTED]", "X-Client": "cli"}, fields["headers"])
+}
+
+func TestAccessLogLevelsAndExclusions(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.AccessLog.ExcludePaths = []string{"/healthz"}
+	e, logs := newAccessLogEcho(cfg, util.AccessFormatJSON)
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, 0, logs.Len(), "excluded paths are not logged")
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	require.Equal(t, 2, logs.Len())
+	assert.Equal(t, zapcore.ErrorLevel, logs.All()[0].Level)
+	assert.Equal(t, int64(http.StatusBadGateway), logs.All()[0].ContextMap()["status"])
+	assert.Equal(t, zapcore.WarnLevel, logs.All()[1].Level)
+	assert.Equal(t, int64(http.StatusNotFound), logs.All()[1].ContextMap()["status"])
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.AccessLog.Sampling.Initial = 2
+	cfg.AccessLog.Sampling.Thereafter = 3
+	cfg.AccessLog.Sampling.Tick = time.Hour
+	e, logs := newAccessLogEcho(cfg, util.AccessFormatJSON)
+
+	for i := 0; i < 8; i++ {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	}
+	assert.Equal(t, 4, logs.Len(), "first 2 then every 3rd: requests 1, 2, 5, 8")
+
+	// 错误请求不参与采样
+	for i := 0; i < 3; i++ {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	}
+	assert.Equal(t, 7, logs.Len())
+}
+
+func TestAccessLogCombined(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.AccessLog.RedactQueryParams = []string{"token"}
+	e, logs := newAccessLogEcho(cfg, util.AccessFormatCombined)
+
+	req := httptest.NewRequest(http.MethodPost, "/items/42?token=abc", strings.NewReader("x"))
+	req.RemoteAddr = "192.0.2.10:5555"
+	req.Header.Set("User-Agent", `curl/8 "quoted"`)
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Equal(t, 1, logs.Len())
+	assert.Regexp(t,
+		`^192\.0\.2\.10 - u-1 \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "POST /items/42\?token=\[REDACTED\] HTTP/1\.1" 201 7 "-" "curl/8 \\"quoted\\""$`,
+		logs.All()[0].Message)
+}
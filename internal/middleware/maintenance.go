@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/netip"
+	"strconv"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/maintenance"
+	res "github.com/HoronLee/EchoHub/internal/response"
+	"github.com/HoronLee/EchoHub/internal/service"
+	"github.com/HoronLee/EchoHub/internal/util/pathmatch"
+	"github.com/labstack/echo/v4"
+)
+
+// Maintenance 维护模式中间件
+// 维护模式开启时，除 exempt_paths 中的路径、allow_ips 中的客户端与携带 bypass_roles 角色 JWT 的用户外，
+// 其余请求返回 503（业务错误码 10006）与 Retry-After；认证位于路由分组，此处自行解析可选的 Bearer 令牌。
+// 豁免角色取自令牌中签名的 role 声明而不查询数据库：维护期间数据库可能正在迁移或被锁定，
+// 每个被拒绝的请求都不应产生数据库负载；角色变更要到令牌过期后才影响维护模式豁免，管理端点仍由 RequireAdmin 按当前角色校验
+func Maintenance(cfg *config.AppConfig, m *maintenance.Manager) echo.MiddlewareFunc {
+	exempt := pathmatch.ParseList(cfg.Maintenance.ExemptPaths)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			state := m.State()
+			if !state.Enabled || exempt.Match(ctx.Request().URL.Path, ctx.Path()) {
+				return next(ctx)
+			}
+
+			ip, _ := netip.ParseAddr(ctx.RealIP())
+			if m.Bypass(ip, requestRole(ctx)) {
+				return next(ctx)
+			}
+
+			ctx.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(state.RetryAfter))
+			r := res.Fail(service.ErrUnderMaintenance.WithDetail("retry_after", state.RetryAfter))
+			r.Msg = m.Message()
+			return res.Write(ctx, r)
+		}
+	}
+}
+
+// requestRole 返回可选的 Bearer 令牌中的角色，未携带或令牌无效时返回空串
+func requestRole(ctx echo.Context) string {
+	if ctx.Request().Header.Get(echo.HeaderAuthorization) == "" {
+		return ""
+	}
+	tokenString, err := bearerToken(ctx)
+	if err != nil {
+		return ""
+	}
+	claims, err := parseClaims(tokenString)
+	if err != nil {
+		return ""
+	}
+	return claims.Role
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/maintenance"
+	model "github.com/HoronLee/EchoHub/internal/model/maintenance"
+	"github.com/HoronLee/EchoHub/internal/model/user"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMaintenanceEcho(t *testing.T) (*echo.Echo, *maintenance.Manager) {
+	t.Helper()
+	config.JWT_SECRET = []byte("test-secret")
+	cfg := &config.AppConfig{}
+	cfg.Server.Mode = "debug"
+	cfg.Maintenance.RetryAfter = 5 * time.Minute
+	cfg.Maintenance.AllowIPs = []string{"10.0.0.0/8"}
+	cfg.Maintenance.BypassRoles = []string{user.RoleAdmin}
+	cfg.Maintenance.ExemptPaths = []string{"/api/v1/admin/*", "/healthz"}
+	m, err := maintenance.NewManager(cfg, util.NewLogger(cfg))
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.HTTPErrorHandler = CustomHTTPErrorHandler
+	e.Use(Maintenance(cfg, m))
+	ok := func(c echo.Context) error { return c.String(http.StatusOK, "ok") }
+	e.GET("/api/v1/items", ok)
+	e.GET("/api/v1/admin/maintenance", ok)
+	e.GET("/healthz", ok)
+	return e, m
+}
+
+func TestMaintenance(t *testing.T) {
+	e, m := newMaintenanceEcho(t)
+	serve := func(path, ip, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		if token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/api/v1/items", "192.0.2.1", "").Code, "disabled by default")
+
+	_, err := m.Update(model.UpdateRequest{Enabled: true, RetryAfter: 120}, "test")
+	require.NoError(t, err)
+
+	rec := serve("/api/v1/items", "192.0.2.1", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "120", rec.Header().Get(echo.HeaderRetryAfter))
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.EqualValues(t, 10006, body["code"])
+	assert.Equal(t, "系统维护中，请稍后再试", body["msg"])
+	assert.EqualValues(t, 120, body["details"].(map[string]any)["retry_after"])
+
+	assert.Equal(t, http.StatusOK, serve("/api/v1/admin/maintenance", "192.0.2.1", "").Code, "exempt route")
+	assert.Equal(t, http.StatusOK, serve("/healthz", "192.0.2.1", "").Code, "exempt path")
+	assert.Equal(t, http.StatusOK, serve("/api/v1/items", "10.1.2.3", "").Code, "allowlisted IP")
+	assert.Equal(t, http.StatusOK, serve("/api/v1/items", "192.0.2.1", signTestToken(t, "u-admin", user.RoleAdmin)).Code, "bypass role")
+	assert.Equal(t, http.StatusServiceUnavailable, serve("/api/v1/items", "192.0.2.1", signTestToken(t, "u-user", user.RoleUser)).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, serve("/api/v1/items", "192.0.2.1", "forged").Code)
+
+	_, err = m.Update(model.UpdateRequest{Enabled: true, Message: "数据库迁移中"}, "test")
+	require.NoError(t, err)
+	rec = serve("/api/v1/items", "192.0.2.1", "")
+	assert.Equal(t, "300", rec.Header().Get(echo.HeaderRetryAfter), "default retry_after")
+	assert.Contains(t, rec.Body.String(), "数据库迁移中", "custom message")
+}
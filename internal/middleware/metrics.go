@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/metrics"
+	"github.com/labstack/echo/v4"
+)
+
+// unmatchedRoute 未匹配到路由的请求统一使用的标签值
+const unmatchedRoute = "unmatched"
+
+// Metrics HTTP 指标中间件，按路由模板、方法和状态码记录请求数与耗时
+// skip 中的路由模板不计入指标（如指标端点自身）
+func Metrics(registry *metrics.Registry, skip ...string) echo.MiddlewareFunc {
+	skipped := make(map[string]struct{}, len(skip))
+	for _, path := range skip {
+		skipped[path] = struct{}{}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			route := ctx.Path()
+			if _, ok := skipped[route]; ok {
+				return next(ctx)
+			}
+
+			start := time.Now()
+			err := next(ctx)
+
+			// 使用路由模板而非原始路径，避免路径参数导致标签基数失控
+			if route == "" || route == "/*" || route == "/api/v1/*" {
+				route = unmatchedRoute
+			}
+			registry.ObserveHTTP(ctx.Request().Method, route, responseStatus(ctx, err), time.Since(start))
+			return err
+		}
+	}
+}
+
+// responseStatus 返回最终的响应状态码
+// 处理器返回错误时响应尚未写出，状态码由错误处理器根据错误类型决定
+func responseStatus(ctx echo.Context, err error) int {
+	if err == nil || ctx.Response().Committed {
+		return ctx.Response().Status
+	}
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return he.Code
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/metrics"
+	res "github.com/HoronLee/EchoHub/internal/response"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.Metrics.Namespace = "test"
+	registry := metrics.NewRegistry(cfg)
+
+	e := echo.New()
+	e.HTTPErrorHandler = CustomHTTPErrorHandler
+	e.Use(Metrics(registry, "/metrics"))
+	e.GET("/metrics", echo.WrapHandler(registry.Handler()))
+	e.GET("/items/:id", res.Execute(func(c echo.Context) res.Response {
+		if c.Param("id") == "missing" {
+			return res.NotFound("not found")
+		}
+		return res.Success(nil)
+	}))
+	e.GET("/boom", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusBadGateway, "upstream")
+	})
+
+	for _, path := range []string{"/items/1", "/items/2", "/items/missing", "/boom", "/no/such/route", "/metrics"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	expected := `
+# HELP test_http_requests_total Total number of HTTP requests by route template, method and status.
+# TYPE test_http_requests_total counter
+test_http_requests_total{method="GET",route="/boom",status="502"} 1
+test_http_requests_total{method="GET",route="/items/:id",status="200"} 2
+test_http_requests_total{method="GET",route="/items/:id",status="404"} 1
+test_http_requests_total{method="GET",route="unmatched",status="404"} 1
+# HELP test_business_errors_total Total number of business error responses by business code.
+# TYPE test_business_errors_total counter
+test_business_errors_total{code="404"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(registry.Gatherer(), strings.NewReader(expected),
+		"test_http_requests_total", "test_business_errors_total"))
+
+	// 直方图按相同标签分组，原始 URL 不会成为标签
+	n, err := testutil.GatherAndCount(registry.Gatherer(), "test_http_request_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/errcode"
+	"github.com/HoronLee/EchoHub/internal/ratelimit"
+	res "github.com/HoronLee/EchoHub/internal/response"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// 限流响应头，遵循 draft-ietf-httpapi-ratelimit-headers
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+)
+
+// RateLimit 限流中间件，按配置中的路由规则（未命中时为默认策略）选择策略
+// 按用户限流的策略需挂载在 JwtAuth 之后，才能读取到 user_id
+func RateLimit(limiter *ratelimit.Limiter, logger *util.Logger) echo.MiddlewareFunc {
+	return rateLimit(limiter, logger, func(ctx echo.Context) *ratelimit.Policy {
+		return limiter.Match(ctx.Request().Method, ctx.Path())
+	})
+}
+
+// RateLimitPolicy 使用指定策略的限流中间件，用于在单个路由或分组上显式挂载
+// 策略不存在时 panic，以便在启动阶段暴露配置错误
+func RateLimitPolicy(limiter *ratelimit.Limiter, logger *util.Logger, name string) echo.MiddlewareFunc {
+	if !limiter.Enabled() {
+		return rateLimit(limiter, logger, nil)
+	}
+	p, ok := limiter.Lookup(name)
+	if !ok {
+		panic(fmt.Sprintf("ratelimit: unknown policy %q", name))
+	}
+	return rateLimit(limiter, logger, func(echo.Context) *ratelimit.Policy { return p })
+}
+
+func rateLimit(limiter *ratelimit.Limiter, logger *util.Logger, policyFor func(echo.Context) *ratelimit.Policy) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !limiter.Enabled() {
+			return next
+		}
+		return func(ctx echo.Context) error {
+			// 预检请求与未匹配到具体路由的请求不计入配额
+			path := ctx.Path()
+			if ctx.Request().Method == http.MethodOptions || path == "" || path == "/api/v1/*" {
+				return next(ctx)
+			}
+
+			p := policyFor(ctx)
+			if p == nil {
+				return next(ctx)
+			}
+
+			result, err := limiter.Allow(ctx.Request().Context(), p, rateLimitSubject(ctx, limiter, p.Key))
+			if err != nil {
+				// 存储不可用时放行，避免限流组件故障导致整体不可用
+				logger.WithContext(ctx.Request().Context()).Warn("Rate limit check failed",
+					zap.String("policy", p.Name), zap.Error(err))
+				return next(ctx)
+			}
+
+			setRateLimitHeaders(ctx.Response().Header(), p, result)
+			if !result.Allowed {
+				ctx.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(max(ceilSeconds(result.RetryAfter), 1)))
+				return res.Write(ctx, res.Fail(errcode.TooManyRequests))
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// rateLimitSubject 返回限流维度的取值，user/api_key 缺失时回退到客户端 IP
+func rateLimitSubject(ctx echo.Context, limiter *ratelimit.Limiter, key string) string {
+	switch key {
+	case ratelimit.KeyUser:
+		if userID, ok := ctx.Get("user_id").(string); ok && userID != "" {
+			return "user:" + userID
+		}
+	case ratelimit.KeyAPIKey:
+		if apiKey := ctx.Request().Header.Get(limiter.APIKeyHeader()); apiKey != "" {
+			// 只保存摘要，避免明文 API Key 出现在计数存储中
+			sum := sha256.Sum256([]byte(apiKey))
+			return "key:" + hex.EncodeToString(sum[:16])
+		}
+	}
+	return "ip:" + ctx.RealIP()
+}
+
+func setRateLimitHeaders(h http.Header, p *ratelimit.Policy, r ratelimit.Result) {
+	h.Set(HeaderRateLimitLimit, strconv.Itoa(r.Limit))
+	h.Set(HeaderRateLimitRemaining, strconv.Itoa(max(r.Remaining, 0)))
+	h.Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(r.ResetAfter)))
+
+	policy := fmt.Sprintf("%d;w=%d", p.Limit.Rate, ceilSeconds(p.Limit.Period))
+	if p.Limit.Algorithm == ratelimit.TokenBucket {
+		policy += ";burst=" + strconv.Itoa(p.Limit.Burst)
+	}
+	h.Set(HeaderRateLimitPolicy, policy)
+}
+
+// ceilSeconds 将时长向上取整为秒
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
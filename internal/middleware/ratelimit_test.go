@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/ratelimit"
+	res "github.com/HoronLee/EchoHub/internal/response"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateLimitEcho(t *testing.T) *echo.Echo {
+	cfg := &config.AppConfig{}
+	cfg.Server.Mode = "debug"
+	cfg.RateLimit.Enabled = true
+	cfg.RateLimit.DefaultPolicy = "default"
+	cfg.RateLimit.Policies = map[string]config.RateLimitPolicy{
+		"default": {Limit: 100, Period: time.Minute, Burst: 100},
+		"auth":    {Algorithm: ratelimit.SlidingWindow, Limit: 2, Period: time.Minute},
+		"user":    {Limit: 60, Period: time.Minute, Burst: 1, Key: ratelimit.KeyUser},
+	}
+	cfg.RateLimit.Routes = []config.RateLimitRoute{
+		{Method: http.MethodPost, Path: "/login", Policy: "auth"},
+		{Path: "/me", Policy: "user"},
+	}
+
+	logger := util.NewLogger(cfg)
+	limiter, err := ratelimit.NewLimiter(cfg, ratelimit.NewMemoryStore(), logger)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.HTTPErrorHandler = CustomHTTPErrorHandler
+	e.Use(RequestID())
+	fakeAuth := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", c.Request().Header.Get("X-Test-User"))
+			return next(c)
+		}
+	}
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.POST("/login", ok, RateLimit(limiter, logger))
+	e.GET("/me", ok, fakeAuth, RateLimit(limiter, logger))
+	// 显式挂载的策略在认证之前执行，认证失败的请求同样计入配额
+	deny := func(echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error { return c.NoContent(http.StatusUnauthorized) }
+	}
+	e.GET("/admin/status", ok, RateLimitPolicy(limiter, logger, "auth"), deny)
+	return e
+}
+
+func TestRateLimit(t *testing.T) {
+	e := newRateLimitEcho(t)
+
+	do := func(method, path string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("按路由选择策略并返回限流头", func(t *testing.T) {
+		rec := do(http.MethodPost, "/login", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get(HeaderRateLimitLimit))
+		assert.Equal(t, "1", rec.Header().Get(HeaderRateLimitRemaining))
+		assert.Equal(t, "2;w=60", rec.Header().Get(HeaderRateLimitPolicy))
+		assert.NotEmpty(t, rec.Header().Get(HeaderRateLimitReset))
+	})
+
+	t.Run("超出配额返回429与Retry-After", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do(http.MethodPost, "/login", nil).Code)
+
+		rec := do(http.MethodPost, "/login", nil)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get(echo.HeaderRetryAfter))
+		assert.Equal(t, "0", rec.Header().Get(HeaderRateLimitRemaining))
+
+		var body res.Response
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, 429, body.Code)
+		assert.NotEmpty(t, body.RequestID)
+
+		// 预检请求不计入配额
+		assert.NotEqual(t, http.StatusTooManyRequests, do(http.MethodOptions, "/login", nil).Code)
+	})
+
+	t.Run("按用户计数", func(t *testing.T) {
+		alice := map[string]string{"X-Test-User": "alice"}
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/me", alice).Code)
+		assert.Equal(t, http.StatusTooManyRequests, do(http.MethodGet, "/me", alice).Code)
+
+		// 同一 IP 下的其他用户拥有独立配额
+		bob := map[string]string{"X-Test-User": "bob"}
+		rec := do(http.MethodGet, "/me", bob)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "60;w=60;burst=1", rec.Header().Get(HeaderRateLimitPolicy))
+	})
+
+	t.Run("显式挂载的策略", func(t *testing.T) {
+		// 使用另一个客户端 IP，避免与上面 /login 共享 auth 策略的配额
+		client := map[string]string{echo.HeaderXRealIP: "192.0.2.2"}
+		assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/admin/status", client).Code)
+		assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/admin/status", client).Code)
+		assert.Equal(t, http.StatusTooManyRequests, do(http.MethodGet, "/admin/status", client).Code)
+	})
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"strings"
 
+	"github.com/HoronLee/EchoHub/internal/errcode"
+	"github.com/HoronLee/EchoHub/internal/errreport"
 	"github.com/HoronLee/EchoHub/internal/response"
 	util "github.com/HoronLee/EchoHub/internal/util/log"
 	"github.com/labstack/echo/v4"
@@ -13,11 +15,14 @@ import (
 )
 
 // Recovery 自定义 panic 恢复中间件
-func Recovery(logger *util.Logger) echo.MiddlewareFunc {
+// panic 连同调用栈与请求信息上报到 reporter，客户端断开连接引起的 panic 不上报
+func Recovery(logger *util.Logger, reporter errreport.Reporter) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(ctx echo.Context) (err error) {
 			defer func() {
 				if r := recover(); r != nil {
+					logger := logger.WithContext(ctx.Request().Context())
+
 					// 检测是否为客户端断开连接
 					var brokenPipe bool
 					if ne, ok := r.(*net.OpError); ok {
@@ -48,8 +53,12 @@ func Recovery(logger *util.Logger) echo.MiddlewareFunc {
 						zap.String("ip", ctx.RealIP()),
 						zap.Stack("stacktrace"),
 					)
+					reporter.Report(ctx.Request().Context(), errreport.NewPanicEvent(r).WithRequest(ctx))
 
-					err = ctx.JSON(http.StatusInternalServerError, response.InternalServerError("Internal server error"))
+					// 与其他错误响应一致（本地化消息、problem+json）；panic 已带调用栈上报，清除 Err 避免重复上报
+					r := response.Fail(errcode.Internal)
+					r.Err = nil
+					err = response.Write(ctx, r)
 				}
 			}()
 			return next(ctx)
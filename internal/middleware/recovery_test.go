@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/errreport"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingReporter 记录上报的事件
+type recordingReporter struct {
+	events []*errreport.Event
+}
+
+func (r *recordingReporter) Report(_ context.Context, e *errreport.Event) {
+	r.events = append(r.events, e)
+}
+
+func TestRecoveryReportsPanic(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.Server.Mode = "debug"
+	reporter := &recordingReporter{}
+
+	e := echo.New()
+	e.Use(RequestID())
+	e.Use(Recovery(util.NewLogger(cfg), reporter))
+	e.GET("/items/:id", func(c echo.Context) error {
+		c.Set("user_id", "u-1")
+		var m map[string]int
+		m["boom"] = 1
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/items/9", nil)
+	req.Header.Set("Accept-Language", "en-US")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.EqualValues(t, 500, body["code"])
+	assert.Equal(t, "Internal server error", body["msg"])
+	assert.NotEmpty(t, body["request_id"])
+
+	require.Len(t, reporter.events, 1)
+	ev := reporter.events[0]
+	assert.Equal(t, errreport.SourcePanic, ev.Source)
+	assert.Equal(t, "u-1", ev.UserID)
+	require.NotNil(t, ev.Request)
+	assert.Equal(t, "/items/:id", ev.Request.Route)
+	assert.Equal(t, "/items/9", ev.Request.Path)
+	var top errreport.Frame
+	for _, f := range ev.Stack {
+		if f.InApp {
+			top = f
+			break
+		}
+	}
+	assert.True(t, strings.Contains(top.Function, "TestRecoveryReportsPanic"),
+		"stack should start at the panic site, got %s", top.Function)
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/HoronLee/EchoHub/internal/util/requestid"
+	"github.com/labstack/echo/v4"
+)
+
+// RequestID 请求ID中间件
+// 沿用客户端传入的合法 X-Request-ID，否则生成新的ID；写入响应头并存入请求上下文，
+// 供日志、错误响应及 SQL 日志关联同一请求
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			id := req.Header.Get(requestid.Header)
+			if !requestid.Valid(id) {
+				id = requestid.New()
+			}
+
+			ctx.SetRequest(req.WithContext(requestid.NewContext(req.Context(), id)))
+			ctx.Response().Header().Set(requestid.Header, id)
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	res "github.com/HoronLee/EchoHub/internal/response"
+	"github.com/HoronLee/EchoHub/internal/util/requestid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequestIDEcho() *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = CustomHTTPErrorHandler
+	e.Use(RequestID())
+	e.GET("/ctx", func(c echo.Context) error {
+		return c.String(http.StatusOK, requestid.FromContext(c.Request().Context()))
+	})
+	e.GET("/fail", res.Execute(func(c echo.Context) res.Response {
+		return res.BadRequest("bad")
+	}))
+	return e
+}
+
+func TestRequestID(t *testing.T) {
+	e := newRequestIDEcho()
+
+	tests := []struct {
+		name     string
+		incoming string
+		reuse    bool
+	}{
+		{name: "未携带时生成", incoming: "", reuse: false},
+		{name: "合法ID原样沿用", incoming: "abc-123", reuse: true},
+		{name: "非法ID重新生成", incoming: "bad id\nwith newline", reuse: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ctx", nil)
+			if tt.incoming != "" {
+				req.Header.Set(requestid.Header, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(requestid.Header)
+			assert.NotEmpty(t, id)
+			assert.Equal(t, id, rec.Body.String(), "handler context should carry the echoed id")
+			if tt.reuse {
+				assert.Equal(t, tt.incoming, id)
+			} else {
+				assert.NotEqual(t, tt.incoming, id)
+			}
+		})
+	}
+}
+
+func TestRequestIDInErrorBodies(t *testing.T) {
+	e := newRequestIDEcho()
+
+	for _, path := range []string{"/fail", "/notfound"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(requestid.Header, "ticket-42")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		var body res.Response
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), path)
+		assert.Equal(t, "ticket-42", body.RequestID, path)
+	}
+
+	// 成功响应不携带请求ID字段
+	e.GET("/ok", res.Execute(func(c echo.Context) res.Response { return res.Success(nil) }))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	assert.NotContains(t, rec.Body.String(), "request_id")
+}
@@ -0,0 +1,321 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/HoronLee/EchoHub/internal/util/pathmatch"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// 安全相关的响应头
+const (
+	HeaderCSP                = "Content-Security-Policy"
+	HeaderCSPReportOnly      = "Content-Security-Policy-Report-Only"
+	HeaderPermissionsPolicy  = "Permissions-Policy"
+	HeaderReportingEndpoints = "Reporting-Endpoints"
+)
+
+// CSPNonceKey 每请求 CSP nonce 在 echo.Context 中的键
+const CSPNonceKey = "csp_nonce"
+
+// cspReportGroup Reporting-Endpoints 中 CSP 报告端点的名称
+const cspReportGroup = "csp-endpoint"
+
+// cspReportMaxBytes 单个违规报告的最大字节数
+const cspReportMaxBytes = 64 << 10
+
+// CSPPolicy 内容安全策略构建器，指令按名称排序输出（default-src 在最前）
+type CSPPolicy struct {
+	directives []cspDirective
+}
+
+type cspDirective struct {
+	name    string
+	sources []string
+}
+
+// NewCSPPolicy 根据“指令 -> 来源列表”创建策略
+func NewCSPPolicy(directives map[string][]string) *CSPPolicy {
+	p := &CSPPolicy{}
+	for name, sources := range directives {
+		p.directives = append(p.directives, cspDirective{name: strings.ToLower(name), sources: slices.Clone(sources)})
+	}
+	return p
+}
+
+// With 返回追加了来源的策略副本
+// 不存在的 *-src 指令以 default-src 的来源为基础，避免新增指令意外收紧策略
+func (p *CSPPolicy) With(name string, sources ...string) *CSPPolicy {
+	out := &CSPPolicy{directives: make([]cspDirective, len(p.directives))}
+	for i, d := range p.directives {
+		out.directives[i] = cspDirective{name: d.name, sources: slices.Clone(d.sources)}
+	}
+	for i := range out.directives {
+		if out.directives[i].name == name {
+			out.directives[i].sources = append(out.directives[i].sources, sources...)
+			return out
+		}
+	}
+	var base []string
+	if strings.HasSuffix(name, "-src") {
+		base = out.source("default-src")
+	}
+	out.directives = append(out.directives, cspDirective{name: name, sources: append(base, sources...)})
+	return out
+}
+
+// source 返回指令的来源列表副本
+func (p *CSPPolicy) source(name string) []string {
+	for _, d := range p.directives {
+		if d.name == name {
+			return slices.Clone(d.sources)
+		}
+	}
+	return nil
+}
+
+// String 返回 Content-Security-Policy 头的值
+func (p *CSPPolicy) String() string {
+	directives := slices.Clone(p.directives)
+	slices.SortFunc(directives, func(a, b cspDirective) int {
+		switch {
+		case a.name == b.name:
+			return 0
+		case a.name == "default-src":
+			return -1
+		case b.name == "default-src":
+			return 1
+		}
+		return strings.Compare(a.name, b.name)
+	})
+
+	parts := make([]string, 0, len(directives))
+	for _, d := range directives {
+		if len(d.sources) == 0 {
+			parts = append(parts, d.name) // 如 upgrade-insecure-requests
+			continue
+		}
+		parts = append(parts, d.name+" "+strings.Join(d.sources, " "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// cspRoute 路由内容安全策略
+type cspRoute struct {
+	path   pathmatch.Pattern
+	policy string
+}
+
+// SecurityHeaders 安全响应头中间件
+// 设置 X-Content-Type-Options、X-Frame-Options、Referrer-Policy、Permissions-Policy，
+// release 模式或启用 TLS 时设置 HSTS；CSP 按路由模板选择策略，默认策略为 nonce_directives 附加每请求 nonce，
+// 路由策略（如 Swagger UI）原样输出。配置了 report_path 时附加 report-uri 与 report-to
+func SecurityHeaders(cfg *config.AppConfig) echo.MiddlewareFunc {
+	sc := cfg.SecurityHeaders
+
+	static := make(http.Header)
+	if sc.HSTS.Enabled && sc.HSTS.MaxAge > 0 && (cfg.Server.Mode == "release" || cfg.Server.TLS.Enabled) {
+		v := "max-age=" + strconv.FormatInt(int64(sc.HSTS.MaxAge.Seconds()), 10)
+		if sc.HSTS.IncludeSubdomains {
+			v += "; includeSubDomains"
+		}
+		if sc.HSTS.Preload {
+			v += "; preload"
+		}
+		static.Set(echo.HeaderStrictTransportSecurity, v)
+	}
+	if sc.ContentTypeNosniff {
+		static.Set(echo.HeaderXContentTypeOptions, "nosniff")
+	}
+	if sc.FrameOptions != "" {
+		static.Set(echo.HeaderXFrameOptions, sc.FrameOptions)
+	}
+	if sc.ReferrerPolicy != "" {
+		static.Set(echo.HeaderReferrerPolicy, sc.ReferrerPolicy)
+	}
+	if sc.PermissionsPolicy != "" {
+		static.Set(HeaderPermissionsPolicy, sc.PermissionsPolicy)
+	}
+
+	csp := sc.CSP
+	cspHeader := HeaderCSP
+	if csp.ReportOnly {
+		cspHeader = HeaderCSPReportOnly
+	}
+	withReport := func(p *CSPPolicy) *CSPPolicy {
+		if csp.ReportPath == "" {
+			return p
+		}
+		return p.With("report-uri", csp.ReportPath).With("report-to", cspReportGroup)
+	}
+	if csp.Enabled && csp.ReportPath != "" {
+		static.Set(HeaderReportingEndpoints, cspReportGroup+`="`+csp.ReportPath+`"`)
+	}
+
+	policy := withReport(NewCSPPolicy(csp.Directives))
+	defaultPolicy := policy.String()
+	routes := make([]cspRoute, 0, len(csp.Routes))
+	for _, r := range csp.Routes {
+		routes = append(routes, cspRoute{path: pathmatch.Parse(r.Path), policy: withReport(NewCSPPolicy(r.Directives)).String()})
+	}
+	routePolicy := func(route string) (string, bool) {
+		for _, r := range routes {
+			if r.path.Match(route) {
+				return r.policy, true
+			}
+		}
+		return "", false
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !sc.Enabled {
+			return next
+		}
+		return func(ctx echo.Context) error {
+			h := ctx.Response().Header()
+			for k, v := range static {
+				h[k] = v
+			}
+			if !csp.Enabled {
+				return next(ctx)
+			}
+
+			if p, ok := routePolicy(ctx.Path()); ok {
+				h.Set(cspHeader, p)
+				return next(ctx)
+			}
+			if len(csp.NonceDirectives) == 0 {
+				h.Set(cspHeader, defaultPolicy)
+				return next(ctx)
+			}
+
+			nonce := newCSPNonce()
+			ctx.Set(CSPNonceKey, nonce)
+			p := policy
+			for _, d := range csp.NonceDirectives {
+				p = p.With(d, "'nonce-"+nonce+"'")
+			}
+			h.Set(cspHeader, p.String())
+			return next(ctx)
+		}
+	}
+}
+
+// CSPNonce 返回当前请求的 CSP nonce，用于模板中的 <script nonce="...">；未启用时返回空字符串
+func CSPNonce(ctx echo.Context) string {
+	nonce, _ := ctx.Get(CSPNonceKey).(string)
+	return nonce
+}
+
+func newCSPNonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// CSPReport CSP 违规报告收集端点
+// 兼容 report-uri（application/csp-report）与 Reporting API（application/reports+json）两种格式，记录日志后返回 204
+func CSPReport(logger *util.Logger) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		body, err := io.ReadAll(io.LimitReader(ctx.Request().Body, cspReportMaxBytes))
+		if err != nil {
+			return ctx.NoContent(http.StatusBadRequest)
+		}
+
+		log := logger.WithContext(ctx.Request().Context())
+		for _, r := range parseCSPReports(body) {
+			log.Warn("CSP violation",
+				zap.String("document_uri", r.DocumentURI),
+				zap.String("directive", r.Directive),
+				zap.String("blocked_uri", r.BlockedURI),
+				zap.String("source_file", r.SourceFile),
+				zap.Int("line", r.Line),
+				zap.String("disposition", r.Disposition),
+				zap.String("user_agent", ctx.Request().UserAgent()),
+			)
+		}
+		return ctx.NoContent(http.StatusNoContent)
+	}
+}
+
+// cspViolation 违规报告中记录的字段
+type cspViolation struct {
+	DocumentURI string
+	Directive   string
+	BlockedURI  string
+	SourceFile  string
+	Line        int
+	Disposition string
+}
+
+// parseCSPReports 解析违规报告，无法识别的内容返回空
+func parseCSPReports(body []byte) []cspViolation {
+	// report-uri 格式：{"csp-report": {"document-uri": ..., "violated-directive": ...}}
+	var legacy struct {
+		Report *struct {
+			DocumentURI        string `json:"document-uri"`
+			ViolatedDirective  string `json:"violated-directive"`
+			EffectiveDirective string `json:"effective-directive"`
+			BlockedURI         string `json:"blocked-uri"`
+			SourceFile         string `json:"source-file"`
+			LineNumber         int    `json:"line-number"`
+			Disposition        string `json:"disposition"`
+		} `json:"csp-report"`
+	}
+	if err := json.Unmarshal(body, &legacy); err == nil && legacy.Report != nil {
+		r := legacy.Report
+		directive := r.EffectiveDirective
+		if directive == "" {
+			directive = r.ViolatedDirective
+		}
+		return []cspViolation{{
+			DocumentURI: r.DocumentURI,
+			Directive:   directive,
+			BlockedURI:  r.BlockedURI,
+			SourceFile:  r.SourceFile,
+			Line:        r.LineNumber,
+			Disposition: r.Disposition,
+		}}
+	}
+
+	// Reporting API 格式：[{"type": "csp-violation", "body": {"documentURL": ..., "effectiveDirective": ...}}]
+	var reports []struct {
+		Type string `json:"type"`
+		Body struct {
+			DocumentURL        string `json:"documentURL"`
+			EffectiveDirective string `json:"effectiveDirective"`
+			BlockedURL         string `json:"blockedURL"`
+			SourceFile         string `json:"sourceFile"`
+			LineNumber         int    `json:"lineNumber"`
+			Disposition        string `json:"disposition"`
+		} `json:"body"`
+	}
+	if err := json.Unmarshal(body, &reports); err != nil {
+		return nil
+	}
+	out := make([]cspViolation, 0, len(reports))
+	for _, r := range reports {
+		if r.Type != "csp-violation" {
+			continue
+		}
+		out = append(out, cspViolation{
+			DocumentURI: r.Body.DocumentURL,
+			Directive:   r.Body.EffectiveDirective,
+			BlockedURI:  r.Body.BlockedURL,
+			SourceFile:  r.Body.SourceFile,
+			Line:        r.Body.LineNumber,
+			Disposition: r.Body.Disposition,
+		})
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSecurityConfig() *config.AppConfig {
+	cfg := &config.AppConfig{}
+	cfg.Server.Mode = "release"
+	sc := &cfg.SecurityHeaders
+	sc.Enabled = true
+	sc.HSTS.Enabled = true
+	sc.HSTS.MaxAge = 365 * 24 * time.Hour
+	sc.HSTS.IncludeSubdomains = true
+	sc.ContentTypeNosniff = true
+	sc.FrameOptions = "DENY"
+	sc.ReferrerPolicy = "no-referrer"
+	sc.PermissionsPolicy = "camera=()"
+	sc.CSP.Enabled = true
+	sc.CSP.ReportPath = "/csp-report"
+	sc.CSP.NonceDirectives = []string{"script-src"}
+	sc.CSP.Directives = map[string][]string{
+		"object-src":  {"'none'"},
+		"default-src": {"'self'"},
+	}
+	sc.CSP.Routes = []config.CSPRoute{{
+		Path:       "/swagger/*",
+		Directives: map[string][]string{"default-src": {"'self'"}, "script-src": {"'self'", "'unsafe-inline'"}},
+	}}
+	return cfg
+}
+
+func serveSecurity(cfg *config.AppConfig, path string) (*httptest.ResponseRecorder, string) {
+	var nonce string
+	e := echo.New()
+	e.Use(SecurityHeaders(cfg))
+	handler := func(c echo.Context) error {
+		nonce = CSPNonce(c)
+		return c.String(http.StatusOK, "ok")
+	}
+	e.GET("/page", handler)
+	e.GET("/swagger/*", handler)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec, nonce
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	cfg := newSecurityConfig()
+
+	rec, nonce := serveSecurity(cfg, "/page")
+	h := rec.Header()
+	assert.Equal(t, "max-age=31536000; includeSubDomains", h.Get(echo.HeaderStrictTransportSecurity))
+	assert.Equal(t, "nosniff", h.Get(echo.HeaderXContentTypeOptions))
+	assert.Equal(t, "DENY", h.Get(echo.HeaderXFrameOptions))
+	assert.Equal(t, "no-referrer", h.Get(echo.HeaderReferrerPolicy))
+	assert.Equal(t, "camera=()", h.Get(HeaderPermissionsPolicy))
+	assert.Equal(t, `csp-endpoint="/csp-report"`, h.Get(HeaderReportingEndpoints))
+
+	// 缺少 script-src 时以 default-src 为基础附加 nonce
+	require.NotEmpty(t, nonce)
+	assert.Equal(t,
+		"default-src 'self'; object-src 'none'; report-to csp-endpoint; report-uri /csp-report; script-src 'self' 'nonce-"+nonce+"'",
+		h.Get(HeaderCSP))
+	assert.Empty(t, h.Get(HeaderCSPReportOnly))
+
+	_, other := serveSecurity(cfg, "/page")
+	assert.NotEqual(t, nonce, other, "nonce is generated per request")
+
+	t.Run("路由策略原样输出且不附加nonce", func(t *testing.T) {
+		rec, nonce := serveSecurity(cfg, "/swagger/index.html")
+		assert.Empty(t, nonce)
+		assert.Equal(t,
+			"default-src 'self'; report-to csp-endpoint; report-uri /csp-report; script-src 'self' 'unsafe-inline'",
+			rec.Header().Get(HeaderCSP))
+	})
+
+	t.Run("report_only", func(t *testing.T) {
+		cfg := newSecurityConfig()
+		cfg.SecurityHeaders.CSP.ReportOnly = true
+		rec, _ := serveSecurity(cfg, "/page")
+		assert.Empty(t, rec.Header().Get(HeaderCSP))
+		assert.Contains(t, rec.Header().Get(HeaderCSPReportOnly), "default-src 'self'")
+	})
+
+	t.Run("debug模式且未启用TLS时不发送HSTS", func(t *testing.T) {
+		cfg := newSecurityConfig()
+		cfg.Server.Mode = "debug"
+		rec, _ := serveSecurity(cfg, "/page")
+		assert.Empty(t, rec.Header().Get(echo.HeaderStrictTransportSecurity))
+
+		cfg.Server.TLS.Enabled = true
+		rec, _ = serveSecurity(cfg, "/page")
+		assert.NotEmpty(t, rec.Header().Get(echo.HeaderStrictTransportSecurity))
+	})
+
+	t.Run("未启用时不设置", func(t *testing.T) {
+		cfg := newSecurityConfig()
+		cfg.SecurityHeaders.Enabled = false
+		rec, _ := serveSecurity(cfg, "/page")
+		assert.Empty(t, rec.Header().Get(echo.HeaderXContentTypeOptions))
+		assert.Empty(t, rec.Header().Get(HeaderCSP))
+	})
+}
+
+func TestCSPReport(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.Server.Mode = "debug"
+	e := echo.New()
+	e.POST("/csp-report", CSPReport(util.NewLogger(cfg)))
+
+	bodies := map[string]string{
+		"application/csp-report":   `{"csp-report":{"document-uri":"https://echohub.com/","violated-directive":"script-src","blocked-uri":"inline","line-number":3}}`,
+		"application/reports+json": `[{"type":"csp-violation","body":{"documentURL":"https://echohub.com/","effectiveDirective":"script-src-elem","blockedURL":"https://evil.example/x.js"}}]`,
+		"text/plain":               `not json`,
+	}
+	for ct, body := range bodies {
+		req := httptest.NewRequest(http.MethodPost, "/csp-report", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, ct)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNoContent, rec.Code, ct)
+	}
+
+	v := parseCSPReports([]byte(bodies["application/csp-report"]))
+	require.Len(t, v, 1)
+	assert.Equal(t, cspViolation{DocumentURI: "https://echohub.com/", Directive: "script-src", BlockedURI: "inline", Line: 3}, v[0])
+
+	v = parseCSPReports([]byte(bodies["application/reports+json"]))
+	require.Len(t, v, 1)
+	assert.Equal(t, "script-src-elem", v[0].Directive)
+	assert.Equal(t, "https://evil.example/x.js", v[0].BlockedURI)
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// ListFilter 批量查询过滤条件
+// 按主键升序的游标分页：每批返回 ID 大于 AfterID 的前 Limit 条记录
+type ListFilter struct {
+	AfterID uint      // 游标，上一批最后一条记录的ID
+	Limit   int       // 每批条数
+	Since   time.Time // 创建时间下限（包含），零值表示不限制
+	Until   time.Time // 创建时间上限（不包含），零值表示不限制
+}
@@ -0,0 +1,25 @@
+package model
+
+import "github.com/google/uuid"
+
+// PublicIDField 公开ID字段名约定
+// 模型声明该字段后，创建时由数据层自动生成 UUIDv7，对外（JSON、路径参数、JWT）只暴露该值，
+// 自增主键仅在内部用于关联查询：
+//
+//	ID       uint   `gorm:"primaryKey" json:"-"`
+//	PublicID string `gorm:"type:varchar(36);uniqueIndex" json:"id"`
+const PublicIDField = "PublicID"
+
+// NewPublicID 生成新的公开ID（UUIDv7，按时间有序，对索引友好）
+func NewPublicID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// IsPublicID 判断字符串是否为合法的公开ID
+func IsPublicID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	_, err := uuid.Parse(s)
+	return err == nil
+}
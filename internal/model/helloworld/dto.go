@@ -9,7 +9,15 @@ type CreateRequest struct {
 // CreateResponse 创建 HelloWorld 的响应
 // swagger:model CreateResponse
 type CreateResponse struct {
+	ID       string `json:"id" example:"01890a5d-ac96-774b-bcce-b302099a8057" description:"新建记录的公开ID"`
 	Message  string `json:"message" example:"Hello, World!" description:"返回的问候消息"`
 	Version  string `json:"version" example:"1.0.0" description:"应用程序版本"`
 	Database string `json:"database" example:"SQLite" description:"数据库类型"`
 }
+
+// UpdateRequest 更新 HelloWorld 的请求
+// swagger:model UpdateRequest
+type UpdateRequest struct {
+	Message string `json:"message" validate:"required,min=1,max=20" example:"Hello, EchoHub!" description:"问候消息，长度1-20字符"`
+	Version uint   `json:"version" example:"1" description:"期望的版本号，未携带 If-Match 请求头时使用"`
+}
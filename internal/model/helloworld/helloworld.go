@@ -2,9 +2,14 @@ package helloworld
 
 import "time"
 
+// CacheTag HelloWorld 响应缓存标签，记录变更后按该标签失效
+const CacheTag = "helloworld"
+
 // HelloWorld 定义HelloWorld实体
 type HelloWorld struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
+	ID        uint      `gorm:"primaryKey" json:"-"`                    // 内部主键，仅用于关联查询
+	PublicID  string    `gorm:"type:varchar(36);uniqueIndex" json:"id"` // 对外暴露的公开ID（UUIDv7）
 	Message   string    `gorm:"type:text;not null" json:"message"`
+	Version   uint      `gorm:"not null;default:1" json:"version"` // 乐观锁版本号，每次更新自增
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -0,0 +1,11 @@
+package helloworld
+
+import "time"
+
+// TransferRecord HelloWorld 导入导出记录，导入时保留原ID
+type TransferRecord struct {
+	ID        uint      `json:"id" validate:"required"`
+	PublicID  string    `json:"public_id,omitempty" validate:"omitempty,uuid"` // 为空时导入生成新的公开ID
+	Message   string    `json:"message" validate:"required,min=1"`
+	CreatedAt time.Time `json:"created_at"`
+}
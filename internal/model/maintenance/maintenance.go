@@ -0,0 +1,21 @@
+package maintenance
+
+import "time"
+
+// State 维护模式状态，持久化到 maintenance.state_file
+// swagger:model MaintenanceState
+type State struct {
+	Enabled    bool      `json:"enabled" example:"true" description:"是否处于维护模式"`
+	Message    string    `json:"message,omitempty" example:"数据库迁移中，预计 10 分钟后恢复" description:"提示消息，为空时使用配置或本地化的默认消息"`
+	RetryAfter int       `json:"retry_after" example:"600" description:"建议客户端重试的间隔（秒）"`
+	UpdatedAt  time.Time `json:"updated_at" description:"最近一次切换时间"`
+	UpdatedBy  string    `json:"updated_by,omitempty" example:"admin-token" description:"最近一次切换的操作者"`
+}
+
+// UpdateRequest 切换维护模式请求
+// swagger:model MaintenanceUpdateRequest
+type UpdateRequest struct {
+	Enabled    bool   `json:"enabled" example:"true" description:"开启或关闭维护模式"`
+	Message    string `json:"message" validate:"max=500" example:"数据库迁移中，预计 10 分钟后恢复" description:"提示消息，留空使用默认消息"`
+	RetryAfter int    `json:"retry_after" validate:"min=0" example:"600" description:"Retry-After（秒），0 表示使用默认值"`
+}
@@ -0,0 +1,55 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 事件状态
+const (
+	StatusPending   = "pending"   // 待投递
+	StatusPublished = "published" // 已投递
+	StatusFailed    = "failed"    // 超过最大重试次数，需人工介入
+)
+
+// Event 事务性发件箱事件
+// 与领域数据在同一事务中写入，由 Relay 异步投递到消息代理
+type Event struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	AggregateType string     `gorm:"type:varchar(64);not null;index:idx_outbox_aggregate,priority:1" json:"aggregate_type"`
+	AggregateID   string     `gorm:"type:varchar(64);not null;index:idx_outbox_aggregate,priority:2" json:"aggregate_id"`
+	EventType     string     `gorm:"type:varchar(128);not null" json:"event_type"`
+	Payload       string     `gorm:"type:text;not null" json:"payload"`
+	Status        string     `gorm:"type:varchar(16);not null;default:pending;index:idx_outbox_status_next,priority:1" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_outbox_status_next,priority:2" json:"next_attempt_at"`
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	PublishedAt   *time.Time `json:"published_at,omitempty"`
+}
+
+// TableName 指定表名为 outbox
+func (Event) TableName() string {
+	return "outbox"
+}
+
+// AggregateKey 返回聚合标识，用于保证同一聚合内事件的投递顺序
+func (e *Event) AggregateKey() string {
+	return e.AggregateType + ":" + e.AggregateID
+}
+
+// NewEvent 创建待投递事件，payload 序列化为 JSON
+func NewEvent(aggregateType, aggregateID, eventType string, payload any) (*Event, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       string(b),
+		Status:        StatusPending,
+		NextAttemptAt: time.Now(),
+	}, nil
+}
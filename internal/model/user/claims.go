@@ -4,7 +4,8 @@ import "github.com/golang-jwt/jwt/v5"
 
 // Claims JWT Claims 结构体
 type Claims struct {
-	UserID   uint   `json:"user_id"`
+	UserID   string `json:"user_id"` // 用户公开ID，令牌中不携带内部主键
 	Username string `json:"username"`
+	Role     string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
@@ -19,3 +19,10 @@ type LoginRequest struct {
 type LoginResponse struct {
 	Token string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..." description:"JWT访问令牌"`
 }
+
+// UpdateRequest 更新用户信息请求
+// swagger:model UpdateRequest
+type UpdateRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=50,username" example:"john_doe" description:"用户名，长度3-50字符"`
+	Version  uint   `json:"version" example:"1" description:"期望的版本号，未携带 If-Match 请求头时使用"`
+}
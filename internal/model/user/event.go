@@ -0,0 +1,24 @@
+package user
+
+import "time"
+
+// 用户领域事件类型
+const (
+	AggregateType       = "user"
+	EventUserRegistered = "user.registered"
+	EventUserDeleted    = "user.deleted"
+)
+
+// RegisteredEvent 用户注册事件负载
+type RegisteredEvent struct {
+	PublicID  string    `json:"public_id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DeletedEvent 用户删除事件负载
+type DeletedEvent struct {
+	PublicID  string    `json:"public_id"`
+	Username  string    `json:"username"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
@@ -0,0 +1,16 @@
+package user
+
+import "time"
+
+// TransferRecord 用户导入导出记录
+// 导出时仅在显式开启时携带密码哈希；导入时 password_hash 原样写入，或提供明文 password 由服务层哈希
+type TransferRecord struct {
+	ID           uint      `json:"id"`
+	PublicID     string    `json:"public_id,omitempty" validate:"omitempty,uuid"` // 为空时导入生成新的公开ID
+	Username     string    `json:"username" validate:"required,min=3,max=50,username"`
+	PasswordHash string    `json:"password_hash,omitempty"`
+	Password     string    `json:"password,omitempty" validate:"omitempty,min=6"`
+	Role         string    `json:"role,omitempty" validate:"omitempty,oneof=user admin"` // 为空时新用户为 user，覆盖时保留原角色
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -2,11 +2,20 @@ package user
 
 import "time"
 
+// 用户角色
+const (
+	RoleUser  = "user"  // 普通用户
+	RoleAdmin = "admin" // 管理员
+)
+
 // User 用户模型
 type User struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
+	ID        uint      `gorm:"primaryKey" json:"-"`                    // 内部主键，仅用于关联查询
+	PublicID  string    `gorm:"type:varchar(36);uniqueIndex" json:"id"` // 对外暴露的公开ID（UUIDv7）
 	Username  string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"username"`
 	Password  string    `gorm:"type:varchar(255);not null" json:"-"`
+	Role      string    `gorm:"type:varchar(20);not null;default:user" json:"role"` // 角色，可选值: user, admin
+	Version   uint      `gorm:"not null;default:1" json:"version"`                  // 乐观锁版本号，每次更新自增
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -0,0 +1,144 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	outboxModel "github.com/HoronLee/EchoHub/internal/model/outbox"
+	"github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// 事件消息头
+const (
+	HeaderEventType     = "Event-Type"
+	HeaderAggregateType = "Aggregate-Type"
+	HeaderAggregateID   = "Aggregate-Id"
+)
+
+// flushTimeout 等待 NATS 服务端确认的超时时间
+const flushTimeout = 5 * time.Second
+
+// NATSPublisher 基于 NATS 的事件发布器
+// 每条消息携带 Nats-Msg-Id 头（事件ID），开启 JetStream 时可用于服务端去重
+type NATSPublisher struct {
+	nc       *nats.Conn
+	embedded *server.Server
+	prefix   string
+	log      *log.Logger
+}
+
+// NewNATSPublisher 创建NATSPublisher实例
+// 配置 outbox.nats.embedded 为 true 时会先启动内嵌 NATS 服务并连接到该服务
+func NewNATSPublisher(cfg *config.AppConfig, logger *log.Logger) (*NATSPublisher, error) {
+	natsCfg := cfg.Outbox.NATS
+	p := &NATSPublisher{
+		prefix: natsCfg.SubjectPrefix,
+		log:    logger,
+	}
+
+	url := natsCfg.URL
+	if natsCfg.Embedded {
+		ns, err := StartEmbeddedNATS(natsCfg.EmbeddedHost, natsCfg.EmbeddedPort)
+		if err != nil {
+			return nil, err
+		}
+		p.embedded = ns
+		url = ns.ClientURL()
+		logger.Info("Embedded NATS server started", zap.String("url", url))
+	}
+	if url == "" {
+		url = nats.DefaultURL
+	}
+
+	nc, err := nats.Connect(url,
+		nats.Name("echohub-outbox"),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			logger.Warn("NATS disconnected", zap.Error(err))
+		}),
+		nats.ReconnectHandler(func(c *nats.Conn) {
+			logger.Info("NATS reconnected", zap.String("url", c.ConnectedUrl()))
+		}),
+	)
+	if err != nil {
+		if p.embedded != nil {
+			p.embedded.Shutdown()
+		}
+		return nil, fmt.Errorf("failed to connect nats: %w", err)
+	}
+	p.nc = nc
+
+	return p, nil
+}
+
+// StartEmbeddedNATS 启动内嵌 NATS 服务，port 为 -1 时使用随机端口
+func StartEmbeddedNATS(host string, port int) (*server.Server, error) {
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	ns, err := server.NewServer(&server.Options{
+		Host:   host,
+		Port:   port,
+		NoLog:  true,
+		NoSigs: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedded nats: %w", err)
+	}
+	go ns.Start()
+	if !ns.ReadyForConnections(5 * time.Second) {
+		ns.Shutdown()
+		return nil, fmt.Errorf("embedded nats not ready")
+	}
+	return ns, nil
+}
+
+// Subject 返回事件对应的主题，例如 echohub.user.registered
+func (p *NATSPublisher) Subject(e *outboxModel.Event) string {
+	if p.prefix == "" {
+		return e.EventType
+	}
+	return p.prefix + "." + e.EventType
+}
+
+// Publish 发布事件并等待服务端确认
+func (p *NATSPublisher) Publish(ctx context.Context, e *outboxModel.Event) error {
+	msg := nats.NewMsg(p.Subject(e))
+	msg.Data = []byte(e.Payload)
+	msg.Header.Set(nats.MsgIdHdr, strconv.FormatUint(uint64(e.ID), 10))
+	msg.Header.Set(HeaderEventType, e.EventType)
+	msg.Header.Set(HeaderAggregateType, e.AggregateType)
+	msg.Header.Set(HeaderAggregateID, e.AggregateID)
+
+	if err := p.nc.PublishMsg(msg); err != nil {
+		return err
+	}
+
+	// Flush 往返一次服务端，确保消息已被接收而不是停留在本地缓冲区
+	ctx, cancel := context.WithTimeout(ctx, flushTimeout)
+	defer cancel()
+	return p.nc.FlushWithContext(ctx)
+}
+
+// Conn 返回底层 NATS 连接，可用于订阅（例如内嵌模式下的本地消费）
+func (p *NATSPublisher) Conn() *nats.Conn {
+	return p.nc
+}
+
+// Close 关闭连接以及内嵌服务
+func (p *NATSPublisher) Close() {
+	if p.nc != nil {
+		p.nc.Close()
+	}
+	if p.embedded != nil {
+		p.embedded.Shutdown()
+		p.embedded.WaitForShutdown()
+	}
+	p.log.Info("NATS publisher closed")
+}
@@ -0,0 +1,15 @@
+// Package outbox 实现事务性发件箱的投递协程
+//
+// 领域数据变更与事件在同一事务中写入 outbox 表，Relay 轮询待投递事件并通过 Publisher
+// 发送到外部消息代理，提供至少一次（at-least-once）投递语义：
+//   - 投递失败按指数退避重试，超过最大次数后标记为 failed
+//   - 同一聚合（aggregate_type + aggregate_id）内的事件严格按写入顺序投递，
+//     前序事件未投递成功时后续事件不会被投递
+//
+// 同一部署中只应有一个实例开启 Relay。
+package outbox
+
+import "github.com/google/wire"
+
+// ProviderSet is outbox providers.
+var ProviderSet = wire.NewSet(NewPublisher, NewRelay)
@@ -0,0 +1,58 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	outboxModel "github.com/HoronLee/EchoHub/internal/model/outbox"
+	"github.com/HoronLee/EchoHub/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// Publisher 定义事件发布接口
+// Publish 返回 nil 表示消息代理已确认接收该事件
+type Publisher interface {
+	Publish(ctx context.Context, e *outboxModel.Event) error
+}
+
+// NewPublisher 根据配置创建发布器
+func NewPublisher(cfg *config.AppConfig, logger *log.Logger) (Publisher, func(), error) {
+	if !cfg.Outbox.Enabled {
+		return NewLogPublisher(logger), func() {}, nil
+	}
+
+	switch cfg.Outbox.Publisher {
+	case "", "nats":
+		p, err := NewNATSPublisher(cfg, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		return p, p.Close, nil
+	case "log":
+		return NewLogPublisher(logger), func() {}, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported outbox publisher: %s", cfg.Outbox.Publisher)
+	}
+}
+
+// LogPublisher 仅将事件写入日志的发布器，用于调试
+type LogPublisher struct {
+	log *log.Logger
+}
+
+// NewLogPublisher 创建LogPublisher实例
+func NewLogPublisher(logger *log.Logger) *LogPublisher {
+	return &LogPublisher{log: logger}
+}
+
+// Publish 将事件写入日志
+func (p *LogPublisher) Publish(ctx context.Context, e *outboxModel.Event) error {
+	p.log.Info("Outbox event published",
+		zap.Uint("id", e.ID),
+		zap.String("event_type", e.EventType),
+		zap.String("aggregate", e.AggregateKey()),
+		zap.String("payload", e.Payload),
+	)
+	return nil
+}
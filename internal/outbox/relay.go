@@ -0,0 +1,278 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/errreport"
+	outboxModel "github.com/HoronLee/EchoHub/internal/model/outbox"
+	"github.com/HoronLee/EchoHub/internal/util/log"
+	"go.uber.org/zap"
+)
+
+// Store 定义 Relay 读取和更新发件箱的接口，由数据层实现
+type Store interface {
+	// FetchPending 按写入顺序返回到期的待投递事件，
+	// 不包含同一聚合中存在处于退避或失败状态的前序事件的记录
+	FetchPending(ctx context.Context, now time.Time, limit int) ([]*outboxModel.Event, error)
+	MarkPublished(ctx context.Context, id uint, at time.Time) error
+	MarkRetry(ctx context.Context, id uint, attempts int, next time.Time, lastErr string) error
+	MarkFailed(ctx context.Context, id uint, attempts int, lastErr string) error
+	// PendingStats 返回待投递事件数以及最早一条的写入时间
+	PendingStats(ctx context.Context) (count int64, oldest time.Time, err error)
+}
+
+// Metrics Relay 运行指标快照
+type Metrics struct {
+	Published   uint64        // 累计投递成功数
+	Retried     uint64        // 累计投递失败并计划重试的次数
+	Failed      uint64        // 累计超过最大重试次数的事件数
+	Pending     int64         // 当前待投递事件数
+	Lag         time.Duration // 最早一条待投递事件已等待的时长
+	DeliveryLag time.Duration // 最近一次投递成功的端到端延迟（写入到投递）
+}
+
+// Relay 发件箱投递协程
+type Relay struct {
+	store     Store
+	publisher Publisher
+	log       *log.Logger
+
+	enabled      bool
+	pollInterval time.Duration
+	batchSize    int
+	maxAttempts  int
+	retryBackoff time.Duration
+	maxBackoff   time.Duration
+
+	mu      sync.Mutex
+	metrics Metrics
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewRelay 创建Relay实例
+func NewRelay(cfg *config.AppConfig, store Store, publisher Publisher, logger *log.Logger) *Relay {
+	r := &Relay{
+		store:        store,
+		publisher:    publisher,
+		log:          logger,
+		enabled:      cfg.Outbox.Enabled,
+		pollInterval: cfg.Outbox.PollInterval,
+		batchSize:    cfg.Outbox.BatchSize,
+		maxAttempts:  cfg.Outbox.MaxAttempts,
+		retryBackoff: cfg.Outbox.RetryBackoff,
+		maxBackoff:   cfg.Outbox.MaxBackoff,
+	}
+	if r.pollInterval <= 0 {
+		r.pollInterval = time.Second
+	}
+	if r.batchSize <= 0 {
+		r.batchSize = 100
+	}
+	if r.maxAttempts <= 0 {
+		r.maxAttempts = 10
+	}
+	if r.retryBackoff <= 0 {
+		r.retryBackoff = time.Second
+	}
+	if r.maxBackoff <= 0 {
+		r.maxBackoff = 5 * time.Minute
+	}
+	return r
+}
+
+// Start 启动后台投递协程，未开启时直接返回
+func (r *Relay) Start() {
+	if !r.enabled {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go r.loop(ctx)
+	r.log.Info("Outbox relay started",
+		zap.Duration("poll_interval", r.pollInterval),
+		zap.Int("batch_size", r.batchSize),
+	)
+}
+
+// Stop 停止投递协程并等待当前批次结束
+func (r *Relay) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel = nil
+	r.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	select {
+	case <-done:
+		r.log.Info("Outbox relay stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Metrics 返回当前运行指标快照
+func (r *Relay) Metrics() Metrics {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.metrics
+}
+
+func (r *Relay) loop(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// 整批投递成功时立即拉取下一批，尽快消化积压
+		for {
+			n, err := r.RunOnce(ctx)
+			if err != nil && ctx.Err() == nil {
+				r.log.Error("Outbox relay batch failed", zap.Error(err))
+				errreport.Default().Report(ctx, errreport.NewJobEvent("outbox_relay", err))
+			}
+			if err != nil || n < r.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 拉取并投递一批事件，返回本批投递成功的事件数
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	now := time.Now()
+	events, err := r.store.FetchPending(ctx, now, r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	// 同一聚合中前序事件投递失败后，本批次内的后续事件不再投递，保证顺序
+	blocked := make(map[string]bool)
+	delivered := 0
+	for _, e := range events {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+		key := e.AggregateKey()
+		if blocked[key] {
+			continue
+		}
+		if err := r.deliver(ctx, e); err != nil {
+			blocked[key] = true
+			continue
+		}
+		delivered++
+	}
+
+	r.refreshLag(ctx)
+	return delivered, nil
+}
+
+// deliver 投递单个事件并更新其状态
+func (r *Relay) deliver(ctx context.Context, e *outboxModel.Event) error {
+	pubErr := r.publisher.Publish(ctx, e)
+	if pubErr == nil {
+		publishedAt := time.Now()
+		if err := r.store.MarkPublished(ctx, e.ID, publishedAt); err != nil {
+			// 状态未能落库，下次轮询会重复投递，由下游按事件ID去重
+			r.log.Error("Failed to mark outbox event published", zap.Uint("id", e.ID), zap.Error(err))
+			return err
+		}
+		r.mu.Lock()
+		r.metrics.Published++
+		r.metrics.DeliveryLag = publishedAt.Sub(e.CreatedAt)
+		r.mu.Unlock()
+		return nil
+	}
+
+	attempts := e.Attempts + 1
+	if attempts >= r.maxAttempts {
+		r.log.Error("Outbox event exceeded max attempts",
+			zap.Uint("id", e.ID),
+			zap.String("event_type", e.EventType),
+			zap.String("aggregate", e.AggregateKey()),
+			zap.Int("attempts", attempts),
+			zap.Error(pubErr),
+		)
+		errreport.Default().Report(ctx, errreport.NewJobEvent("outbox_relay", pubErr).
+			WithTag("event_type", e.EventType))
+		if err := r.store.MarkFailed(ctx, e.ID, attempts, pubErr.Error()); err != nil {
+			r.log.Error("Failed to mark outbox event failed", zap.Uint("id", e.ID), zap.Error(err))
+		}
+		r.mu.Lock()
+		r.metrics.Failed++
+		r.mu.Unlock()
+		return pubErr
+	}
+
+	next := time.Now().Add(r.backoff(attempts))
+	r.log.Warn("Outbox event publish failed, will retry",
+		zap.Uint("id", e.ID),
+		zap.String("event_type", e.EventType),
+		zap.Int("attempts", attempts),
+		zap.Time("next_attempt_at", next),
+		zap.Error(pubErr),
+	)
+	if err := r.store.MarkRetry(ctx, e.ID, attempts, next, pubErr.Error()); err != nil {
+		r.log.Error("Failed to schedule outbox retry", zap.Uint("id", e.ID), zap.Error(err))
+	}
+	r.mu.Lock()
+	r.metrics.Retried++
+	r.mu.Unlock()
+	return pubErr
+}
+
+// backoff 计算第 attempts 次失败后的重试间隔
+func (r *Relay) backoff(attempts int) time.Duration {
+	d := r.retryBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= r.maxBackoff {
+			return r.maxBackoff
+		}
+	}
+	return d
+}
+
+// refreshLag 刷新待投递数量与积压时长
+func (r *Relay) refreshLag(ctx context.Context) {
+	count, oldest, err := r.store.PendingStats(ctx)
+	if err != nil {
+		r.log.Warn("Failed to get outbox pending stats", zap.Error(err))
+		return
+	}
+
+	var lag time.Duration
+	if count > 0 && !oldest.IsZero() {
+		lag = time.Since(oldest)
+	}
+
+	r.mu.Lock()
+	r.metrics.Pending = count
+	r.metrics.Lag = lag
+	r.mu.Unlock()
+}
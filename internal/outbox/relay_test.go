@@ -0,0 +1,152 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/data"
+	outboxModel "github.com/HoronLee/EchoHub/internal/model/outbox"
+	"github.com/HoronLee/EchoHub/internal/outbox"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// flakyPublisher 对指定聚合的前 failures 次投递返回错误
+type flakyPublisher struct {
+	mu        sync.Mutex
+	failKey   string
+	failures  int
+	published []uint
+}
+
+func (p *flakyPublisher) Publish(_ context.Context, e *outboxModel.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e.AggregateKey() == p.failKey && p.failures > 0 {
+		p.failures--
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, e.ID)
+	return nil
+}
+
+func newTestEnv(t *testing.T) (*config.AppConfig, *data.Data, *util.Logger) {
+	cfg := &config.AppConfig{}
+	cfg.Database.Driver = "sqlite"
+	cfg.Database.Source = ":memory:"
+	cfg.Server.Mode = "debug"
+	cfg.Outbox.Enabled = true
+	cfg.Outbox.BatchSize = 10
+	cfg.Outbox.MaxAttempts = 3
+	cfg.Outbox.RetryBackoff = time.Millisecond
+	cfg.Outbox.MaxBackoff = time.Millisecond
+
+	logger := util.NewLogger(cfg)
+	db, err := data.NewDB(cfg, logger, noop.NewTracerProvider())
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // 内存 SQLite 每个连接是独立的数据库
+	t.Cleanup(func() { sqlDB.Close() })
+
+	d, cleanup, err := data.NewData(db, logger)
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+	return cfg, d, logger
+}
+
+func addEvents(t *testing.T, d *data.Data, logger *util.Logger, aggregateIDs ...string) {
+	repo := data.NewOutboxRepo(d, logger)
+	for _, id := range aggregateIDs {
+		e, err := outboxModel.NewEvent("user", id, "user.registered", map[string]string{"id": id})
+		require.NoError(t, err)
+		require.NoError(t, repo.AddEvents(context.Background(), e))
+	}
+}
+
+func TestRelayRetriesAndKeepsAggregateOrder(t *testing.T) {
+	cfg, d, logger := newTestEnv(t)
+	ctx := context.Background()
+
+	// 事件ID: 1=user:1, 2=user:2, 3=user:1
+	addEvents(t, d, logger, "1", "2", "1")
+
+	pub := &flakyPublisher{failKey: "user:1", failures: 1}
+	relay := outbox.NewRelay(cfg, data.NewOutboxStore(d, logger), pub, logger)
+
+	// 第一轮：user:1 的首个事件失败，同聚合的后续事件被阻塞，user:2 不受影响
+	n, err := relay.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []uint{2}, pub.published)
+	assert.Equal(t, uint64(1), relay.Metrics().Retried)
+	assert.Equal(t, int64(2), relay.Metrics().Pending)
+
+	// 退避结束后按写入顺序投递
+	time.Sleep(5 * time.Millisecond)
+	n, err = relay.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []uint{2, 1, 3}, pub.published)
+
+	m := relay.Metrics()
+	assert.Equal(t, uint64(3), m.Published)
+	assert.Equal(t, int64(0), m.Pending)
+	assert.Zero(t, m.Lag)
+}
+
+func TestRelayMarksFailedAfterMaxAttempts(t *testing.T) {
+	cfg, d, logger := newTestEnv(t)
+	ctx := context.Background()
+
+	addEvents(t, d, logger, "1", "1")
+	pub := &flakyPublisher{failKey: "user:1", failures: 100}
+	relay := outbox.NewRelay(cfg, data.NewOutboxStore(d, logger), pub, logger)
+
+	for i := 0; i < cfg.Outbox.MaxAttempts; i++ {
+		_, err := relay.RunOnce(ctx)
+		require.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Equal(t, uint64(1), relay.Metrics().Failed)
+
+	// 首个事件失败后，同聚合的后续事件不会越过它被投递
+	_, err := relay.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pub.published)
+}
+
+func TestNATSPublisherWithEmbeddedServer(t *testing.T) {
+	cfg, d, logger := newTestEnv(t)
+	cfg.Outbox.Publisher = "nats"
+	cfg.Outbox.NATS.Embedded = true
+	cfg.Outbox.NATS.EmbeddedPort = -1
+	cfg.Outbox.NATS.SubjectPrefix = "echohub"
+
+	pub, cleanup, err := outbox.NewPublisher(cfg, logger)
+	require.NoError(t, err)
+	defer cleanup()
+	np := pub.(*outbox.NATSPublisher)
+
+	sub, err := np.Conn().SubscribeSync("echohub.>")
+	require.NoError(t, err)
+
+	addEvents(t, d, logger, "42")
+	relay := outbox.NewRelay(cfg, data.NewOutboxStore(d, logger), pub, logger)
+	n, err := relay.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	msg, err := sub.NextMsg(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "echohub.user.registered", msg.Subject)
+	assert.Equal(t, "1", msg.Header.Get(nats.MsgIdHdr))
+	assert.Equal(t, "42", msg.Header.Get(outbox.HeaderAggregateID))
+	assert.JSONEq(t, `{"id":"42"}`, string(msg.Data))
+}
@@ -0,0 +1,170 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/HoronLee/EchoHub/internal/util/pathmatch"
+	"go.uber.org/zap"
+)
+
+// 限流维度
+const (
+	KeyIP     = "ip"
+	KeyUser   = "user"
+	KeyAPIKey = "api_key"
+)
+
+// Policy 具名限流策略
+type Policy struct {
+	Name  string
+	Limit Limit
+	Key   string // 限流维度
+}
+
+// route 路由限流规则
+type route struct {
+	method string
+	path   pathmatch.Pattern // 以 * 结尾时按前缀匹配
+	policy *Policy
+}
+
+// Limiter 按路由解析限流策略并在存储上执行判定
+type Limiter struct {
+	enabled       bool
+	store         Store
+	policies      map[string]*Policy
+	routes        []route
+	defaultPolicy *Policy
+	apiKeyHeader  string
+	logger        *log.Logger
+}
+
+// NewLimiter 根据配置创建限流器，策略或路由规则非法时返回错误
+func NewLimiter(cfg *config.AppConfig, store Store, logger *log.Logger) (*Limiter, error) {
+	rc := cfg.RateLimit
+	l := &Limiter{
+		enabled:      rc.Enabled,
+		store:        store,
+		policies:     make(map[string]*Policy, len(rc.Policies)),
+		apiKeyHeader: rc.APIKeyHeader,
+		logger:       logger,
+	}
+	if !l.enabled {
+		return l, nil
+	}
+	if l.apiKeyHeader == "" {
+		l.apiKeyHeader = "X-API-Key"
+	}
+
+	for name, pc := range rc.Policies {
+		p, err := newPolicy(name, pc)
+		if err != nil {
+			return nil, err
+		}
+		l.policies[name] = p
+	}
+
+	if rc.DefaultPolicy != "" {
+		p, ok := l.policies[rc.DefaultPolicy]
+		if !ok {
+			return nil, fmt.Errorf("ratelimit: unknown default policy %q", rc.DefaultPolicy)
+		}
+		l.defaultPolicy = p
+	}
+	if rc.AdminPolicy != "" {
+		if _, ok := l.policies[rc.AdminPolicy]; !ok {
+			return nil, fmt.Errorf("ratelimit: unknown admin policy %q", rc.AdminPolicy)
+		}
+	}
+
+	for _, rr := range rc.Routes {
+		p, ok := l.policies[rr.Policy]
+		if !ok {
+			return nil, fmt.Errorf("ratelimit: route %s %s references unknown policy %q", rr.Method, rr.Path, rr.Policy)
+		}
+		l.routes = append(l.routes, route{
+			method: strings.ToUpper(rr.Method),
+			path:   pathmatch.Parse(rr.Path),
+			policy: p,
+		})
+	}
+
+	logger.Info("Rate limiter initialized",
+		zap.Int("policies", len(l.policies)),
+		zap.Int("routes", len(l.routes)),
+		zap.String("default_policy", rc.DefaultPolicy))
+	return l, nil
+}
+
+func newPolicy(name string, pc config.RateLimitPolicy) (*Policy, error) {
+	p := &Policy{
+		Name: name,
+		Key:  pc.Key,
+		Limit: Limit{
+			Algorithm: pc.Algorithm,
+			Rate:      pc.Limit,
+			Period:    pc.Period,
+			Burst:     pc.Burst,
+		},
+	}
+	switch p.Limit.Algorithm {
+	case "":
+		p.Limit.Algorithm = TokenBucket
+	case TokenBucket, SlidingWindow:
+	default:
+		return nil, fmt.Errorf("ratelimit: policy %q has unsupported algorithm %q", name, pc.Algorithm)
+	}
+	switch p.Key {
+	case "":
+		p.Key = KeyIP
+	case KeyIP, KeyUser, KeyAPIKey:
+	default:
+		return nil, fmt.Errorf("ratelimit: policy %q has unsupported key %q", name, pc.Key)
+	}
+	if p.Limit.Rate <= 0 || p.Limit.Period <= 0 {
+		return nil, fmt.Errorf("ratelimit: policy %q requires positive limit and period", name)
+	}
+	if p.Limit.Burst <= 0 {
+		p.Limit.Burst = p.Limit.Rate
+	}
+	return p, nil
+}
+
+// Enabled 是否启用限流
+func (l *Limiter) Enabled() bool {
+	return l.enabled
+}
+
+// APIKeyHeader 返回按 API Key 限流时读取的请求头
+func (l *Limiter) APIKeyHeader() string {
+	return l.apiKeyHeader
+}
+
+// Lookup 按名称查找策略，用于在路由上显式挂载
+func (l *Limiter) Lookup(name string) (*Policy, bool) {
+	p, ok := l.policies[name]
+	return p, ok
+}
+
+// Match 返回请求命中的策略：按顺序匹配第一条路由规则，未命中时使用默认策略
+// path 为路由模板（echo.Context.Path），而不是实际请求路径
+func (l *Limiter) Match(method, path string) *Policy {
+	for _, r := range l.routes {
+		if r.method != "" && r.method != method {
+			continue
+		}
+		if r.path.Match(path) {
+			return r.policy
+		}
+	}
+	return l.defaultPolicy
+}
+
+// Allow 对指定主体执行一次判定，subject 为限流维度的取值（如 ip:1.2.3.4）
+func (l *Limiter) Allow(ctx context.Context, p *Policy, subject string) (Result, error) {
+	return l.store.Allow(ctx, p.Name+":"+subject, p.Limit)
+}
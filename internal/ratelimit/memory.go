@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval 进程内存储清理过期计数的最小间隔
+const sweepInterval = time.Minute
+
+// MemoryStore 进程内计数存储，仅适用于单实例部署
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucketState
+	windows   map[string]*windowState
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucketState struct {
+	tokens   float64
+	last     time.Time
+	expireAt time.Time
+}
+
+type windowState struct {
+	start    time.Time
+	prev     int
+	cur      int
+	expireAt time.Time
+}
+
+// NewMemoryStore 创建进程内计数存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucketState),
+		windows: make(map[string]*windowState),
+		now:     time.Now,
+	}
+}
+
+// Allow 执行一次限流判定
+func (m *MemoryStore) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	if limit.Algorithm == SlidingWindow {
+		return m.slidingWindow(key, limit, now), nil
+	}
+	return m.tokenBucket(key, limit, now), nil
+}
+
+func (m *MemoryStore) tokenBucket(key string, limit Limit, now time.Time) Result {
+	st, ok := m.buckets[key]
+	if !ok {
+		st = &bucketState{tokens: float64(limit.Burst), last: now}
+		m.buckets[key] = st
+	}
+
+	tokens, res := tokenBucket(st.tokens, now.Sub(st.last), limit)
+	st.tokens = tokens
+	st.last = now
+	st.expireAt = now.Add(res.ResetAfter)
+	return res
+}
+
+func (m *MemoryStore) slidingWindow(key string, limit Limit, now time.Time) Result {
+	start := windowStart(now, limit.Period)
+	st, ok := m.windows[key]
+	if !ok {
+		st = &windowState{start: start}
+		m.windows[key] = st
+	}
+
+	// 滚动窗口：跨过一个窗口时当前计数成为上一窗口，跨过多个窗口时全部清零
+	switch {
+	case st.start.Equal(start):
+	case st.start.Add(limit.Period).Equal(start):
+		st.prev, st.cur, st.start = st.cur, 0, start
+	default:
+		st.prev, st.cur, st.start = 0, 0, start
+	}
+
+	res := slidingWindow(st.prev, st.cur, now.Sub(start), limit)
+	if res.Allowed {
+		st.cur++
+	}
+	st.expireAt = start.Add(2 * limit.Period)
+	return res
+}
+
+// sweep 删除已经恢复满额、不再影响判定的计数
+func (m *MemoryStore) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+	for key, st := range m.buckets {
+		if !now.Before(st.expireAt) {
+			delete(m.buckets, key)
+		}
+	}
+	for key, st := range m.windows {
+		if !now.Before(st.expireAt) {
+			delete(m.windows, key)
+		}
+	}
+}
+
+// Len 返回当前保存的计数条目数
+func (m *MemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.buckets) + len(m.windows)
+}
@@ -0,0 +1,146 @@
+// Package ratelimit 提供可配置的限流中间件
+//
+// 支持令牌桶（token_bucket）与滑动窗口（sliding_window）两种算法，按 IP、用户或 API Key 计数；
+// 计数存储可选进程内（memory）或基于 Redis 协议（redis）的实现，算法在存储内原子执行。
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/google/wire"
+	"go.uber.org/zap"
+)
+
+// ProviderSet is ratelimit providers.
+var ProviderSet = wire.NewSet(NewStore, NewLimiter)
+
+// 限流算法
+const (
+	TokenBucket   = "token_bucket"
+	SlidingWindow = "sliding_window"
+)
+
+// Limit 单条限流规则
+type Limit struct {
+	Algorithm string        // 算法
+	Rate      int           // 每个周期允许的请求数
+	Period    time.Duration // 周期
+	Burst     int           // 令牌桶容量（仅 token_bucket）
+}
+
+// Result 一次限流判定的结果
+type Result struct {
+	Allowed    bool          // 是否放行
+	Limit      int           // 配额上限
+	Remaining  int           // 剩余配额
+	ResetAfter time.Duration // 配额完全恢复（或当前窗口结束）所需时间
+	RetryAfter time.Duration // 被拒绝时建议的重试等待时间
+}
+
+// Store 限流计数存储，实现需保证同一键上的判定是原子的
+type Store interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// NewStore 根据配置创建计数存储
+func NewStore(cfg *config.AppConfig, logger *log.Logger) (Store, func(), error) {
+	store := cfg.RateLimit.Store
+	if !cfg.RateLimit.Enabled {
+		store = "memory" // 未启用时不连接外部存储
+	}
+	switch store {
+	case "", "memory":
+		s := NewMemoryStore()
+		return s, func() {}, nil
+	case "redis":
+		rc := cfg.RateLimit.Redis
+		s, err := NewRedisStore(RedisOptions{
+			Addr:      rc.Addr,
+			Password:  rc.Password,
+			DB:        rc.DB,
+			KeyPrefix: rc.KeyPrefix,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		logger.Info("Rate limit store initialized", zap.String("store", "redis"), zap.String("addr", rc.Addr))
+		return s, func() {
+			if err := s.Close(); err != nil {
+				logger.Warn("Failed to close rate limit store", zap.Error(err))
+			}
+		}, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported rate limit store: %s", cfg.RateLimit.Store)
+	}
+}
+
+// tokenBucket 在给定状态上执行一次令牌桶判定，返回新的令牌数与判定结果
+// tokens 为上次判定后的令牌数，elapsed 为距上次判定的时间
+func tokenBucket(tokens float64, elapsed time.Duration, l Limit) (float64, Result) {
+	tokens += elapsed.Seconds() * l.perSecond()
+	if burst := float64(l.Burst); tokens > burst {
+		tokens = burst
+	}
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+	return tokens, bucketResult(tokens, allowed, l)
+}
+
+// bucketResult 根据判定后的令牌数计算令牌桶的判定结果
+func bucketResult(tokens float64, allowed bool, l Limit) Result {
+	perSecond := l.perSecond()
+	res := Result{
+		Allowed:    allowed,
+		Limit:      l.Burst,
+		Remaining:  int(tokens),
+		ResetAfter: seconds((float64(l.Burst) - tokens) / perSecond),
+	}
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / perSecond)
+	}
+	return res
+}
+
+// slidingWindow 基于前后两个固定窗口加权估算当前滑动窗口内的请求数
+// prev/cur 为上一窗口与当前窗口的计数（不含本次请求），elapsed 为当前窗口已经过的时间
+func slidingWindow(prev, cur int, elapsed time.Duration, l Limit) Result {
+	weight := float64(l.Period-elapsed) / float64(l.Period)
+	estimated := float64(prev)*weight + float64(cur)
+
+	res := Result{Limit: l.Rate, ResetAfter: l.Period - elapsed}
+	if estimated+1 <= float64(l.Rate) {
+		res.Allowed = true
+		res.Remaining = int(float64(l.Rate) - estimated - 1)
+		return res
+	}
+
+	// 等待上一窗口的权重衰减到足以容纳一次请求；当前窗口已满时等到下一窗口
+	res.RetryAfter = l.Period - elapsed
+	if prev > 0 && cur+1 <= l.Rate {
+		need := 1 - float64(l.Rate-cur-1)/float64(prev) // 需要达到的已过比例
+		if wait := time.Duration(need*float64(l.Period)) - elapsed; wait > 0 && wait < res.RetryAfter {
+			res.RetryAfter = wait
+		}
+	}
+	return res
+}
+
+// windowStart 返回 now 所在固定窗口的起始时间
+func windowStart(now time.Time, period time.Duration) time.Time {
+	return now.Truncate(period)
+}
+
+func (l Limit) perSecond() float64 {
+	return float64(l.Rate) / l.Period.Seconds()
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
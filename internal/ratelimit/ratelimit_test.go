@@ -0,0 +1,192 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClock 可手动推进的时钟，起点对齐到整分钟便于推算窗口
+type testClock struct{ t time.Time }
+
+func newTestClock() *testClock {
+	return &testClock{t: time.Unix(1_700_000_000, 0).Truncate(time.Minute)}
+}
+
+func (c *testClock) now() time.Time          { return c.t }
+func (c *testClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// storeFactories 返回使用同一时钟的各存储实现
+func storeFactories(t *testing.T) map[string]func(*testClock) Store {
+	return map[string]func(*testClock) Store{
+		"memory": func(c *testClock) Store {
+			s := NewMemoryStore()
+			s.now = c.now
+			return s
+		},
+		"redis": func(c *testClock) Store {
+			mr := miniredis.RunT(t)
+			s, err := NewRedisStore(RedisOptions{Addr: mr.Addr(), KeyPrefix: "test:"})
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = s.Close() })
+			s.now = c.now
+			return s
+		},
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	limit := Limit{Algorithm: TokenBucket, Rate: 60, Period: time.Minute, Burst: 3}
+
+	for name, newStore := range storeFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			clock := newTestClock()
+			s := newStore(clock)
+
+			// 容量内的突发请求全部放行
+			for i := 2; i >= 0; i-- {
+				r, err := s.Allow(ctx, "k", limit)
+				require.NoError(t, err)
+				assert.True(t, r.Allowed)
+				assert.Equal(t, 3, r.Limit)
+				assert.Equal(t, i, r.Remaining)
+			}
+
+			r, err := s.Allow(ctx, "k", limit)
+			require.NoError(t, err)
+			assert.False(t, r.Allowed)
+			assert.Equal(t, time.Second, r.RetryAfter.Round(time.Millisecond))
+			assert.Equal(t, 3*time.Second, r.ResetAfter.Round(time.Millisecond))
+
+			// 每秒补充一个令牌
+			clock.advance(time.Second)
+			r, err = s.Allow(ctx, "k", limit)
+			require.NoError(t, err)
+			assert.True(t, r.Allowed)
+
+			// 不同键互不影响
+			r, err = s.Allow(ctx, "other", limit)
+			require.NoError(t, err)
+			assert.Equal(t, 2, r.Remaining)
+		})
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	limit := Limit{Algorithm: SlidingWindow, Rate: 4, Period: time.Minute}
+
+	for name, newStore := range storeFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			clock := newTestClock()
+			s := newStore(clock)
+
+			for i := 3; i >= 0; i-- {
+				r, err := s.Allow(ctx, "k", limit)
+				require.NoError(t, err)
+				assert.True(t, r.Allowed)
+				assert.Equal(t, i, r.Remaining)
+			}
+			r, err := s.Allow(ctx, "k", limit)
+			require.NoError(t, err)
+			assert.False(t, r.Allowed)
+			assert.Equal(t, time.Minute, r.RetryAfter)
+
+			// 进入下一窗口 15 秒：上一窗口权重 0.75，估算 3 次，只剩 1 次配额
+			clock.advance(75 * time.Second)
+			r, err = s.Allow(ctx, "k", limit)
+			require.NoError(t, err)
+			assert.True(t, r.Allowed)
+			assert.Equal(t, 0, r.Remaining)
+
+			r, err = s.Allow(ctx, "k", limit)
+			require.NoError(t, err)
+			assert.False(t, r.Allowed)
+			// 需等上一窗口权重衰减到 0.5（窗口内第 30 秒）
+			assert.Equal(t, 15*time.Second, r.RetryAfter.Round(time.Millisecond))
+			assert.Equal(t, 45*time.Second, r.ResetAfter)
+
+			// 跨过两个窗口后计数清零
+			clock.advance(2 * time.Minute)
+			r, err = s.Allow(ctx, "k", limit)
+			require.NoError(t, err)
+			assert.Equal(t, 3, r.Remaining)
+		})
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	ctx := context.Background()
+	clock := newTestClock()
+	s := NewMemoryStore()
+	s.now = clock.now
+
+	_, err := s.Allow(ctx, "a", Limit{Algorithm: TokenBucket, Rate: 60, Period: time.Minute, Burst: 5})
+	require.NoError(t, err)
+	_, err = s.Allow(ctx, "b", Limit{Algorithm: SlidingWindow, Rate: 5, Period: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.Len())
+
+	clock.advance(2 * sweepInterval)
+	_, err = s.Allow(ctx, "c", Limit{Algorithm: SlidingWindow, Rate: 5, Period: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.Len(), "recovered entries should be swept")
+}
+
+func TestNewLimiter(t *testing.T) {
+	logCfg := &config.AppConfig{}
+	logCfg.Server.Mode = "debug"
+	logger := util.NewLogger(logCfg)
+
+	newCfg := func() *config.AppConfig {
+		cfg := &config.AppConfig{}
+		cfg.RateLimit.Enabled = true
+		cfg.RateLimit.DefaultPolicy = "default"
+		cfg.RateLimit.Policies = map[string]config.RateLimitPolicy{
+			"default": {Limit: 100, Period: time.Minute},
+			"auth":    {Algorithm: SlidingWindow, Limit: 5, Period: time.Minute},
+			"api":     {Limit: 10, Period: time.Second, Key: KeyAPIKey},
+		}
+		cfg.RateLimit.Routes = []config.RateLimitRoute{
+			{Method: "post", Path: "/api/v1/login", Policy: "auth"},
+			{Path: "/api/v1/admin/*", Policy: "api"},
+		}
+		return cfg
+	}
+
+	l, err := NewLimiter(newCfg(), NewMemoryStore(), logger)
+	require.NoError(t, err)
+
+	assert.Equal(t, "auth", l.Match("POST", "/api/v1/login").Name)
+	assert.Equal(t, "default", l.Match("GET", "/api/v1/login").Name, "method must match")
+	assert.Equal(t, "api", l.Match("GET", "/api/v1/admin/users/:id").Name)
+	assert.Equal(t, "default", l.Match("GET", "/api/v1/user").Name)
+
+	def, ok := l.Lookup("default")
+	require.True(t, ok)
+	assert.Equal(t, TokenBucket, def.Limit.Algorithm)
+	assert.Equal(t, 100, def.Limit.Burst, "burst defaults to limit")
+	assert.Equal(t, KeyIP, def.Key)
+
+	cfg := newCfg()
+	cfg.RateLimit.Routes = append(cfg.RateLimit.Routes, config.RateLimitRoute{Path: "/x", Policy: "missing"})
+	_, err = NewLimiter(cfg, NewMemoryStore(), logger)
+	assert.Error(t, err)
+
+	cfg = newCfg()
+	cfg.RateLimit.AdminPolicy = "missing"
+	_, err = NewLimiter(cfg, NewMemoryStore(), logger)
+	assert.Error(t, err)
+
+	cfg = newCfg()
+	cfg.RateLimit.Policies["bad"] = config.RateLimitPolicy{Algorithm: "leaky", Limit: 1, Period: time.Second}
+	_, err = NewLimiter(cfg, NewMemoryStore(), logger)
+	assert.Error(t, err)
+}
@@ -0,0 +1,159 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript 原子地补充并消耗令牌
+// KEYS[1]: 桶键；ARGV: 每毫秒补充的令牌数、容量、当前毫秒时间戳、过期毫秒数
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = burst
+  ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return {allowed, tostring(tokens)}
+`)
+
+// slidingWindowScript 原子地读取前后两个窗口计数并在放行时递增当前窗口
+// KEYS[1]: 当前窗口键，KEYS[2]: 上一窗口键；ARGV: 配额、上一窗口权重、过期毫秒数
+var slidingWindowScript = redis.NewScript(`
+local cur = tonumber(redis.call('GET', KEYS[1]) or '0')
+local prev = tonumber(redis.call('GET', KEYS[2]) or '0')
+local limit = tonumber(ARGV[1])
+local weight = tonumber(ARGV[2])
+if prev * weight + cur + 1 <= limit then
+  redis.call('INCR', KEYS[1])
+  redis.call('PEXPIRE', KEYS[1], ARGV[3])
+  return {1, prev, cur}
+end
+return {0, prev, cur}
+`)
+
+// RedisOptions Redis 计数存储配置
+type RedisOptions struct {
+	Addr      string // 服务地址，例如 127.0.0.1:6379
+	Password  string // 密码
+	DB        int    // 数据库编号
+	KeyPrefix string // 键前缀
+}
+
+// RedisStore 基于 Redis 协议的计数存储，多实例共享配额
+// 判定通过 Lua 脚本原子执行；时间取自应用实例，实例间需保持时钟同步
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedisStore 创建 Redis 计数存储并检查连接
+func NewRedisStore(opts RedisOptions) (*RedisStore, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     opts.Addr,
+		Password: opts.Password,
+		DB:       opts.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect redis: %w", err)
+	}
+
+	return &RedisStore{
+		client: client,
+		prefix: opts.KeyPrefix,
+		now:    time.Now,
+	}, nil
+}
+
+// Allow 执行一次限流判定
+func (r *RedisStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	// 使用 hash tag 保证同一限流键的多个 Redis 键落在同一集群槽位
+	key = r.prefix + "{" + key + "}"
+	if limit.Algorithm == SlidingWindow {
+		return r.slidingWindow(ctx, key, limit)
+	}
+	return r.tokenBucket(ctx, key, limit)
+}
+
+func (r *RedisStore) tokenBucket(ctx context.Context, key string, limit Limit) (Result, error) {
+	perMilli := limit.perSecond() / 1000
+	refill := seconds(float64(limit.Burst) / limit.perSecond())
+	vals, err := tokenBucketScript.Run(ctx, r.client, []string{key + ":tb"},
+		strconv.FormatFloat(perMilli, 'g', -1, 64),
+		limit.Burst,
+		r.now().UnixMilli(),
+		max(refill.Milliseconds(), 1),
+	).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(vals) != 2 {
+		return Result{}, fmt.Errorf("unexpected token bucket reply: %v", vals)
+	}
+
+	allowed, _ := vals[0].(int64)
+	s, _ := vals[1].(string)
+	tokens, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("unexpected token bucket reply: %w", err)
+	}
+	return bucketResult(tokens, allowed == 1, limit), nil
+}
+
+func (r *RedisStore) slidingWindow(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := r.now()
+	start := windowStart(now, limit.Period)
+	elapsed := now.Sub(start)
+	idx := start.UnixMilli() / limit.Period.Milliseconds()
+	weight := float64(limit.Period-elapsed) / float64(limit.Period)
+
+	vals, err := slidingWindowScript.Run(ctx, r.client,
+		[]string{key + ":sw:" + strconv.FormatInt(idx, 10), key + ":sw:" + strconv.FormatInt(idx-1, 10)},
+		limit.Rate,
+		strconv.FormatFloat(weight, 'g', -1, 64),
+		(2 * limit.Period).Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(vals) != 3 {
+		return Result{}, fmt.Errorf("unexpected sliding window reply: %v", vals)
+	}
+
+	res := slidingWindow(int(vals[1]), int(vals[2]), elapsed, limit)
+	// 以脚本的判定为准，避免浮点误差导致两侧结论不一致
+	if allowed := vals[0] == 1; allowed != res.Allowed {
+		res.Allowed = allowed
+		res.RetryAfter = 0
+		if !allowed {
+			res.Remaining, res.RetryAfter = 0, res.ResetAfter
+		}
+	}
+	return res, nil
+}
+
+// Close 关闭连接
+func (r *RedisStore) Close() error {
+	return r.client.Close()
+}
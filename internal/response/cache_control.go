@@ -0,0 +1,58 @@
+package response
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 缓存相关的请求/响应头
+const (
+	HeaderCacheControl = "Cache-Control"
+	HeaderIfNoneMatch  = "If-None-Match"
+)
+
+// CacheControl Cache-Control 响应头的常用指令
+type CacheControl struct {
+	Public               bool          // 允许共享缓存（CDN、代理）缓存
+	Private              bool          // 只允许客户端缓存，适用于按用户区分的响应
+	NoCache              bool          // 使用前必须通过 ETag 重新验证
+	NoStore              bool          // 禁止任何缓存，同时跳过服务端响应缓存
+	MustRevalidate       bool          // 过期后必须重新验证
+	Immutable            bool          // 有效期内内容不会变化
+	MaxAge               time.Duration // max-age
+	SharedMaxAge         time.Duration // s-maxage，共享缓存的有效期
+	StaleWhileRevalidate time.Duration // stale-while-revalidate
+}
+
+// String 返回 Cache-Control 头的值
+func (c CacheControl) String() string {
+	var d []string
+	flag := func(on bool, name string) {
+		if on {
+			d = append(d, name)
+		}
+	}
+	age := func(v time.Duration, name string) {
+		if v > 0 {
+			d = append(d, name+"="+strconv.FormatInt(int64(v/time.Second), 10))
+		}
+	}
+	flag(c.Public, "public")
+	flag(c.Private, "private")
+	flag(c.NoCache, "no-cache")
+	flag(c.NoStore, "no-store")
+	flag(c.MustRevalidate, "must-revalidate")
+	flag(c.Immutable, "immutable")
+	age(c.MaxAge, "max-age")
+	age(c.SharedMaxAge, "s-maxage")
+	age(c.StaleWhileRevalidate, "stale-while-revalidate")
+	return strings.Join(d, ", ")
+}
+
+// SetCacheControl 设置 Cache-Control 响应头，覆盖 http_cache.default_cache_control
+func SetCacheControl(ctx echo.Context, cc CacheControl) {
+	ctx.Response().Header().Set(HeaderCacheControl, cc.String())
+}
@@ -0,0 +1,53 @@
+package response
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 条件请求相关的请求/响应头
+const (
+	HeaderETag    = "ETag"
+	HeaderIfMatch = "If-Match"
+)
+
+// ErrInvalidIfMatch If-Match 请求头格式错误
+var ErrInvalidIfMatch = errors.New("invalid If-Match header")
+
+// VersionETag 将资源版本号格式化为强 ETag，例如 "3"
+func VersionETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// SetVersionETag 根据资源版本号设置 ETag 响应头
+func SetVersionETag(ctx echo.Context, version uint) {
+	ctx.Response().Header().Set(HeaderETag, VersionETag(version))
+}
+
+// IfMatchVersion 从 If-Match 请求头解析期望的版本号
+// ok 为 false 表示未携带 If-Match 或其值为 "*"（不约束版本）
+func IfMatchVersion(ctx echo.Context) (version uint, ok bool, err error) {
+	header := strings.TrimSpace(ctx.Request().Header.Get(HeaderIfMatch))
+	if header == "" || header == "*" {
+		return 0, false, nil
+	}
+
+	// 乐观锁只能比较单个版本号，不支持列表形式
+	if strings.Contains(header, ",") {
+		return 0, false, ErrInvalidIfMatch
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false, ErrInvalidIfMatch
+	}
+
+	v, err := strconv.ParseUint(tag[1:len(tag)-1], 10, 64)
+	if err != nil || v == 0 {
+		return 0, false, ErrInvalidIfMatch
+	}
+	return uint(v), true, nil
+}
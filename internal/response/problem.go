@@ -0,0 +1,85 @@
+package response
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/HoronLee/EchoHub/internal/errcode"
+	"github.com/HoronLee/EchoHub/internal/errreport"
+	"github.com/labstack/echo/v4"
+)
+
+// MIMEApplicationProblemJSON RFC 7807 错误响应的媒体类型
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// Problem RFC 7807 错误响应
+// swagger:model Problem
+type Problem struct {
+	Type      string         `json:"type" example:"about:blank" description:"错误类型 URI，配置 type_base 时为 type_base+业务错误码"`
+	Title     string         `json:"title" example:"Conflict" description:"HTTP 状态码对应的简短描述"`
+	Status    int            `json:"status" example:"409" description:"HTTP 状态码"`
+	Detail    string         `json:"detail,omitempty" example:"用户名已存在" description:"本地化的错误消息"`
+	Instance  string         `json:"instance,omitempty" example:"/api/v1/register" description:"出错的请求路径"`
+	Code      int            `json:"code" example:"20001" description:"业务错误码"`
+	RequestID string         `json:"request_id,omitempty" description:"请求ID"`
+	Details   map[string]any `json:"details,omitempty" description:"错误的公开细节"`
+}
+
+// Options 错误响应格式选项
+type Options struct {
+	ProblemJSON     bool   // 错误响应统一使用 application/problem+json；为 false 时仅对 Accept 中声明该类型的请求使用
+	ProblemTypeBase string // problem type 的前缀，如 https://docs.example.com/errors/，为空时使用 about:blank
+}
+
+var options Options
+
+// Configure 设置错误响应格式，应在服务启动前调用
+func Configure(opts Options) {
+	options = opts
+}
+
+// Write 输出响应：补全默认状态码、为错误响应附加请求ID、上报未处理的 5xx 错误并按请求语言生成业务错误消息；
+// 启用 problem+json 或客户端请求该格式时，错误响应以 RFC 7807 格式输出
+func Write(ctx echo.Context, r Response) error {
+	if r.HTTPStatus == 0 {
+		r.HTTPStatus = http.StatusOK
+	}
+	r = r.WithRequestID(ctx)
+	report(ctx, r)
+	if r.appErr != nil && r.Msg == "" {
+		r.Msg = r.appErr.Message(errcode.NegotiateLocale(ctx.Request().Header.Get("Accept-Language")))
+	}
+	if r.Code == 0 || !wantsProblem(ctx) {
+		return ctx.JSON(r.HTTPStatus, r)
+	}
+
+	p := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(r.HTTPStatus),
+		Status:    r.HTTPStatus,
+		Detail:    r.Msg,
+		Instance:  ctx.Request().URL.Path,
+		Code:      r.Code,
+		RequestID: r.RequestID,
+		Details:   r.Details,
+	}
+	if options.ProblemTypeBase != "" {
+		p.Type = options.ProblemTypeBase + strconv.Itoa(r.Code)
+	}
+	ctx.Response().Header().Set(echo.HeaderContentType, MIMEApplicationProblemJSON)
+	return ctx.JSON(r.HTTPStatus, p)
+}
+
+// wantsProblem 判断错误响应是否使用 problem+json
+func wantsProblem(ctx echo.Context) bool {
+	return options.ProblemJSON || strings.Contains(ctx.Request().Header.Get(echo.HeaderAccept), MIMEApplicationProblemJSON)
+}
+
+// report 将未处理的服务端错误上报到错误追踪；503 属于主动拒绝（过载、维护），不上报
+func report(ctx echo.Context, r Response) {
+	if r.Err == nil || r.HTTPStatus < http.StatusInternalServerError || r.HTTPStatus == http.StatusServiceUnavailable {
+		return
+	}
+	errreport.Default().Report(ctx.Request().Context(), errreport.NewEvent(r.Err).WithRequest(ctx))
+}
@@ -0,0 +1,151 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HoronLee/EchoHub/internal/errcode"
+	"github.com/HoronLee/EchoHub/internal/errreport"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTestQuotaExceeded = errcode.New(98001, http.StatusConflict, errcode.Messages{
+	errcode.LocaleZhCN: "配额已用尽",
+	errcode.LocaleEnUS: "Quota exceeded",
+})
+
+func serve(t *testing.T, fn func(ctx echo.Context) Response, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	e := echo.New()
+	e.GET("/quota", Execute(fn))
+	req := httptest.NewRequest(http.MethodGet, "/quota", nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestFail(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		header     map[string]string
+		wantStatus int
+		wantCode   int
+		wantMsg    string
+	}{
+		{
+			name:       "业务错误按默认语言输出",
+			err:        fmt.Errorf("reserve: %w", errTestQuotaExceeded.Wrap(errors.New("db: row locked"))),
+			wantStatus: http.StatusConflict,
+			wantCode:   98001,
+			wantMsg:    "配额已用尽",
+		},
+		{
+			name:       "按 Accept-Language 本地化",
+			err:        errTestQuotaExceeded,
+			header:     map[string]string{"Accept-Language": "en-GB,en;q=0.9"},
+			wantStatus: http.StatusConflict,
+			wantCode:   98001,
+			wantMsg:    "Quota exceeded",
+		},
+		{
+			name:       "普通错误不暴露内容",
+			err:        errors.New("dial tcp 10.0.0.5:5432: connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   500,
+			wantMsg:    "服务器内部错误",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, func(ctx echo.Context) Response { return Fail(tt.err) }, tt.header)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.NotContains(t, rec.Body.String(), "10.0.0.5")
+			assert.NotContains(t, rec.Body.String(), "row locked")
+			var body Response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.wantCode, body.Code)
+			assert.Equal(t, tt.wantMsg, body.Msg)
+		})
+	}
+}
+
+func TestProblemJSON(t *testing.T) {
+	detailed := func(ctx echo.Context) Response {
+		return Fail(errTestQuotaExceeded.WithDetail("limit", 10))
+	}
+
+	t.Run("默认仍输出统一格式", func(t *testing.T) {
+		rec := serve(t, detailed, nil)
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON)
+		var body Response
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, float64(10), body.Details["limit"])
+	})
+
+	t.Run("Accept 声明时输出 problem+json", func(t *testing.T) {
+		rec := serve(t, detailed, map[string]string{echo.HeaderAccept: MIMEApplicationProblemJSON})
+		assert.Equal(t, MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+		var p Problem
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+		assert.Equal(t, Problem{
+			Type:     "about:blank",
+			Title:    "Conflict",
+			Status:   http.StatusConflict,
+			Detail:   "配额已用尽",
+			Instance: "/quota",
+			Code:     98001,
+			Details:  map[string]any{"limit": float64(10)},
+		}, p)
+	})
+
+	t.Run("全局启用并配置 type 前缀", func(t *testing.T) {
+		Configure(Options{ProblemJSON: true, ProblemTypeBase: "https://docs.example.com/errors/"})
+		t.Cleanup(func() { Configure(Options{}) })
+
+		rec := serve(t, detailed, nil)
+		var p Problem
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+		assert.Equal(t, "https://docs.example.com/errors/98001", p.Type)
+
+		ok := serve(t, func(ctx echo.Context) Response { return Success("pong") }, nil)
+		assert.Contains(t, ok.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON, "success responses keep the envelope")
+	})
+}
+
+// recordingReporter 记录上报的事件
+type recordingReporter struct {
+	events []*errreport.Event
+}
+
+func (r *recordingReporter) Report(_ context.Context, e *errreport.Event) {
+	r.events = append(r.events, e)
+}
+
+func TestReportUnhandledErrors(t *testing.T) {
+	reporter := &recordingReporter{}
+	errreport.SetDefault(reporter)
+	t.Cleanup(func() { errreport.SetDefault(errreport.Nop) })
+
+	serve(t, func(ctx echo.Context) Response { return Fail(errors.New("db down")) }, nil)
+	serve(t, func(ctx echo.Context) Response { return Fail(errTestQuotaExceeded) }, nil)
+	serve(t, func(ctx echo.Context) Response {
+		return Error(http.StatusServiceUnavailable, 503, "busy", errors.New("overloaded"))
+	}, nil)
+
+	require.Len(t, reporter.events, 1, "only unhandled 5xx errors except 503 are reported")
+	assert.Equal(t, errreport.SourceHTTP, reporter.events[0].Source)
+	assert.Equal(t, "db down", reporter.events[0].Message)
+	assert.Equal(t, "GET /quota", reporter.events[0].Request.Method+" "+reporter.events[0].Request.Route)
+}
@@ -3,7 +3,10 @@ package response
 import (
 	"net/http"
 
+	"github.com/HoronLee/EchoHub/internal/errcode"
+	"github.com/HoronLee/EchoHub/internal/metrics"
 	log "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/HoronLee/EchoHub/internal/util/requestid"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 )
@@ -23,8 +26,17 @@ type Response struct {
 	// Msg 返回信息，通常是状态描述
 	Msg string `json:"msg" example:"success" description:"返回信息，通常是状态描述"`
 
+	// RequestID 请求ID，仅错误响应携带，便于反馈问题时定位日志
+	RequestID string `json:"request_id,omitempty" example:"9f1c2d3e-4b5a-6789-abcd-ef0123456789" description:"请求ID，仅错误响应携带"`
+
+	// Details 错误的公开细节，仅部分业务错误携带
+	Details map[string]any `json:"details,omitempty" description:"错误的公开细节，仅部分业务错误携带"`
+
 	// Err 错误信息，序列化时忽略（仅供内部日志使用）
 	Err error `json:"-"`
+
+	// appErr 业务错误定义，输出时按请求语言生成 Msg
+	appErr *errcode.Error
 }
 
 // Execute 包装器，自动根据 Response 返回统一格式的 HTTP 响应 (仅处理返回类型为JSON的handler)
@@ -36,21 +48,44 @@ func Execute(fn func(ctx echo.Context) Response) echo.HandlerFunc {
 			res.HTTPStatus = http.StatusOK
 		}
 
+		if res.Code != 0 {
+			if m := metrics.Default(); m != nil {
+				m.ObserveBusinessError(res.Code)
+			}
+		}
+
 		if res.Err != nil {
-			log.GetLogger().Error("Business error",
+			msg := res.Msg
+			if msg == "" && res.appErr != nil {
+				msg = res.appErr.Message(errcode.LocaleEnUS)
+			}
+			// 客户端错误记为告警，服务端错误记为错误
+			logFn := log.GetLogger().WithContext(ctx.Request().Context()).Error
+			if res.HTTPStatus < http.StatusInternalServerError {
+				logFn = log.GetLogger().WithContext(ctx.Request().Context()).Warn
+			}
+			logFn("Business error",
 				zap.String("path", ctx.Path()),
 				zap.String("method", ctx.Request().Method),
 				zap.Int("http_status", res.HTTPStatus),
 				zap.Int("business_code", res.Code),
-				zap.String("message", res.Msg),
+				zap.String("message", msg),
 				zap.Error(res.Err),
 			)
 		}
 
-		return ctx.JSON(res.HTTPStatus, res)
+		return Write(ctx, res)
 	}
 }
 
+// WithRequestID 为错误响应附加当前请求的请求ID，成功响应保持不变
+func (r Response) WithRequestID(ctx echo.Context) Response {
+	if r.Code != 0 {
+		r.RequestID = requestid.FromContext(ctx.Request().Context())
+	}
+	return r
+}
+
 func Success(data any, msg ...string) Response {
 	message := "success"
 	if len(msg) > 0 {
@@ -98,6 +133,37 @@ func NotFound(msg string, err ...error) Response {
 	return Error(http.StatusNotFound, 404, msg, err...)
 }
 
+// Conflict 资源冲突响应（例如乐观锁版本不一致）
+func Conflict(msg string, err ...error) Response {
+	return Error(http.StatusConflict, 409, msg, err...)
+}
+
+// RequestEntityTooLarge 请求体过大响应
+func RequestEntityTooLarge(msg string, err ...error) Response {
+	return Error(http.StatusRequestEntityTooLarge, 413, msg, err...)
+}
+
+// TooManyRequests 请求过于频繁响应
+func TooManyRequests(msg string, err ...error) Response {
+	return Error(http.StatusTooManyRequests, 429, msg, err...)
+}
+
+// Fail 将错误映射为响应：业务错误（*errcode.Error）使用其错误码、HTTP 状态码、本地化消息与公开细节，
+// 其他错误一律返回 500 且不暴露错误内容
+func Fail(err error) Response {
+	e, ok := errcode.From(err)
+	if !ok {
+		e = errcode.Internal
+	}
+	return Response{
+		HTTPStatus: e.Status,
+		Code:       e.Code,
+		Details:    e.Details(),
+		Err:        err,
+		appErr:     e,
+	}
+}
+
 func InternalServerError(msg string, err ...error) Response {
 	return Error(http.StatusInternalServerError, 500, msg, err...)
 }
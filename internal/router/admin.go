@@ -0,0 +1,11 @@
+package router
+
+import "github.com/HoronLee/EchoHub/internal/handler"
+
+// setupV1AdminRoutes 设置 v1 版本的管理路由
+func setupV1AdminRoutes(routerGroup *VersionedRouterGroup, h *handler.Handlers) {
+	// Admin routes - 管理路由，需要管理令牌或管理员角色
+	// 路径: GET/PUT /api/v1/admin/maintenance
+	routerGroup.AdminRouter.GET("/maintenance", h.MaintenanceHandler.GetMaintenance())
+	routerGroup.AdminRouter.PUT("/maintenance", h.MaintenanceHandler.UpdateMaintenance())
+}
@@ -1,13 +1,21 @@
 package router
 
-import "github.com/HoronLee/EchoHub/internal/handler"
+import (
+	"github.com/HoronLee/EchoHub/internal/handler"
+	"github.com/HoronLee/EchoHub/internal/middleware"
+	"github.com/HoronLee/EchoHub/internal/model/helloworld"
+)
 
 // setupV1HelloWorldRoutes 设置 v1 版本的 HelloWorld 路由
 func setupV1HelloWorldRoutes(routerGroup *VersionedRouterGroup, h *handler.Handlers) {
 	// Public routes - 公开路由，无需认证
-	// 路径: POST /api/v1/helloworld
+	// 路径: POST /api/v1/helloworld, GET /api/v1/helloworld/:id
 	routerGroup.PublicRouter.POST("/helloworld", h.HelloWorldHandler.PostHelloWorld())
+	// 查询结果在服务端缓存，记录更新后按标签失效
+	routerGroup.PublicRouter.GET("/helloworld/:id", h.HelloWorldHandler.GetHelloWorld(),
+		middleware.ResponseCache(h.ResponseCache, 0, middleware.CacheTag(helloworld.CacheTag)))
 
 	// Private routes - 私有路由，需要 JWT 认证
-	// 可以在这里添加需要认证的 HelloWorld 相关路由
+	// 路径: PUT /api/v1/helloworld/:id
+	routerGroup.PrivateRouter.PUT("/helloworld/:id", h.HelloWorldHandler.UpdateHelloWorld())
 }
@@ -10,12 +10,15 @@ import (
 type VersionedRouterGroup struct {
 	PublicRouter  *echo.Group
 	PrivateRouter *echo.Group
+	AdminRouter   *echo.Group // 管理端点，受 ip_filter 的 admin 名单与管理认证保护
 }
 
 // SetupRouter 配置路由
-func SetupRouter(e *echo.Echo, h *handler.Handlers) {
+// groupMiddleware（限流、幂等等）按顺序挂载在公开与私有路由组上，私有路由组位于 JWT 认证之后，以支持按用户区分；
+// adminMiddleware（如管理端点限流）挂载在管理路由组的管理认证之前
+func SetupRouter(e *echo.Echo, h *handler.Handlers, adminMiddleware []echo.MiddlewareFunc, groupMiddleware ...echo.MiddlewareFunc) {
 	// 设置 v1 版本路由
-	v1RouterGroup := setupV1RouterGroup(e)
+	v1RouterGroup := setupV1RouterGroup(e, h, adminMiddleware, groupMiddleware)
 	setupV1Routes(v1RouterGroup, h)
 
 	// 设置资源路由（包括 Swagger UI）
@@ -27,17 +30,24 @@ func SetupRouter(e *echo.Echo, h *handler.Handlers) {
 }
 
 // setupV1RouterGroup 初始化 v1 版本路由组
-func setupV1RouterGroup(e *echo.Echo) *VersionedRouterGroup {
+func setupV1RouterGroup(e *echo.Echo, h *handler.Handlers, adminMiddleware, groupMiddleware []echo.MiddlewareFunc) *VersionedRouterGroup {
 	apiGroup := e.Group("/api")
 	v1Group := apiGroup.Group("/v1")
 
 	public := v1Group.Group("")
+	public.Use(groupMiddleware...)
 	private := v1Group.Group("")
 	private.Use(middleware.JwtAuth()) // JWT认证中间件
+	private.Use(groupMiddleware...)   // 位于认证之后
+	admin := v1Group.Group("/admin")
+	admin.Use(middleware.IPFilter(h.IPFilter, "admin"))
+	admin.Use(adminMiddleware...) // 位于管理认证之前，被拒绝的认证尝试同样计入配额
+	admin.Use(middleware.RequireAdmin(h.Roles))
 
 	return &VersionedRouterGroup{
 		PublicRouter:  public,
 		PrivateRouter: private,
+		AdminRouter:   admin,
 	}
 }
 
@@ -45,4 +55,5 @@ func setupV1RouterGroup(e *echo.Echo) *VersionedRouterGroup {
 func setupV1Routes(routerGroup *VersionedRouterGroup, h *handler.Handlers) {
 	setupV1HelloWorldRoutes(routerGroup, h)
 	setupV1UserRoutes(routerGroup, h)
+	setupV1AdminRoutes(routerGroup, h)
 }
@@ -10,6 +10,8 @@ func setupV1UserRoutes(routerGroup *VersionedRouterGroup, h *handler.Handlers) {
 	routerGroup.PublicRouter.POST("/login", h.UserHandler.Login())
 
 	// Private routes - 私有路由，需要 JWT 认证
-	// 路径: DELETE /api/v1/user
+	// 路径: GET/PUT/DELETE /api/v1/user
+	routerGroup.PrivateRouter.GET("/user", h.UserHandler.GetUser())
+	routerGroup.PrivateRouter.PUT("/user", h.UserHandler.UpdateUser())
 	routerGroup.PrivateRouter.DELETE("/user", h.UserHandler.DeleteUser())
 }
@@ -19,12 +19,23 @@ import (
 	"net/http"
 
 	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/data"
+	"github.com/HoronLee/EchoHub/internal/errcode"
+	"github.com/HoronLee/EchoHub/internal/errreport"
 	"github.com/HoronLee/EchoHub/internal/handler"
+	"github.com/HoronLee/EchoHub/internal/idempotency"
+	"github.com/HoronLee/EchoHub/internal/ipfilter"
+	"github.com/HoronLee/EchoHub/internal/maintenance"
+	"github.com/HoronLee/EchoHub/internal/metrics"
 	"github.com/HoronLee/EchoHub/internal/middleware"
+	"github.com/HoronLee/EchoHub/internal/outbox"
+	"github.com/HoronLee/EchoHub/internal/ratelimit"
+	res "github.com/HoronLee/EchoHub/internal/response"
 	"github.com/HoronLee/EchoHub/internal/router"
 	util "github.com/HoronLee/EchoHub/internal/util/log"
 	"github.com/HoronLee/EchoHub/internal/validator"
 	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -37,6 +48,15 @@ type HTTPServer struct {
 	db         *gorm.DB
 	logger     *util.Logger
 	validator  *validator.Validator
+	relay      *outbox.Relay
+	rotator    *data.KeyRotator
+	limiter    *ratelimit.Limiter
+	idemStore  idempotency.Store
+	ipFilter   *ipfilter.Filter
+	metrics    *metrics.Registry
+	adminSrv   *http.Server
+	certs      *certReloader
+	redirect   *http.Server
 }
 
 func NewHTTPServer(
@@ -44,7 +64,17 @@ func NewHTTPServer(
 	handlers *handler.Handlers,
 	db *gorm.DB,
 	logger *util.Logger,
+	accessLogger *util.AccessLogger,
 	v *validator.Validator,
+	relay *outbox.Relay,
+	rotator *data.KeyRotator,
+	limiter *ratelimit.Limiter,
+	idem idempotency.Store,
+	filter *ipfilter.Filter,
+	registry *metrics.Registry,
+	tp trace.TracerProvider,
+	reporter errreport.Reporter,
+	mm *maintenance.Manager,
 ) *HTTPServer {
 	e := echo.New()
 
@@ -64,10 +94,39 @@ func NewHTTPServer(
 	// 设置自定义错误处理器（必须在中间件之前）
 	e.HTTPErrorHandler = middleware.CustomHTTPErrorHandler
 
+	// 错误响应格式与默认语言
+	res.Configure(res.Options{
+		ProblemJSON:     cfg.Errors.ProblemJSON,
+		ProblemTypeBase: cfg.Errors.ProblemTypeBase,
+	})
+	errcode.SetDefaultLocale(cfg.Server.Locale)
+
 	// 中间件
-	e.Use(middleware.Logger(logger))
-	e.Use(middleware.Recovery(logger))
-	e.Use(middleware.CORS(cfg))
+	e.Use(middleware.RequestID()) // 必须位于 Logger 之前
+	e.Use(middleware.Tracing(tp)) // 必须位于 Logger 之前，使日志携带 trace_id
+	e.Use(middleware.Logger(cfg, accessLogger))
+	if cfg.Metrics.Enabled {
+		e.Use(middleware.Metrics(registry, cfg.Metrics.Path))
+	}
+	if cfg.Compression.Enabled {
+		e.Use(middleware.Compress(cfg)) // 位于 Recovery 之前，使 panic 响应同样被压缩
+	}
+	e.Use(middleware.Recovery(logger, reporter))
+	e.Use(middleware.SecurityHeaders(cfg))
+	e.Use(middleware.IPFilter(filter, "")) // 全局名单，命名名单按路由挂载
+	e.Use(middleware.CORS(cfg, logger))
+	e.Use(middleware.Maintenance(cfg, mm)) // 位于 CORS 之后，使 503 响应携带跨域响应头
+	if cfg.Server.TLS.Enabled {
+		e.Use(middleware.ClientCert())
+	}
+	e.Use(middleware.BodyLimit(cfg)) // 位于 Decompress 之前，限制的是传输大小
+	if cfg.Compression.Request.Enabled {
+		e.Use(middleware.Decompress(cfg))
+	}
+	e.Use(middleware.ETag(cfg)) // 位于 Compress 之内，按未压缩的响应体计算
+	if cfg.BodyCapture.Enabled {
+		e.Use(middleware.BodyCapture(cfg)) // 位于 Decompress、ETag 之内，捕获解压后的请求体与未压缩的响应体
+	}
 
 	return &HTTPServer{
 		cfg:       cfg,
@@ -76,37 +135,187 @@ func NewHTTPServer(
 		db:        db,
 		logger:    logger,
 		validator: v,
+		relay:     relay,
+		rotator:   rotator,
+		limiter:   limiter,
+		idemStore: idem,
+		ipFilter:  filter,
+		metrics:   registry,
 	}
 }
 
 func (s *HTTPServer) Start() error {
-	router.SetupRouter(s.echo, s.handlers)
+	extractor, err := newIPExtractor(s.cfg)
+	if err != nil {
+		return err
+	}
+	s.echo.IPExtractor = extractor
+
+	var adminMiddleware []echo.MiddlewareFunc
+	if policy := s.cfg.RateLimit.AdminPolicy; policy != "" {
+		adminMiddleware = append(adminMiddleware, middleware.RateLimitPolicy(s.limiter, s.logger, policy))
+	}
+	router.SetupRouter(s.echo, s.handlers, adminMiddleware,
+		middleware.RateLimit(s.limiter, s.logger),
+		middleware.Idempotency(s.cfg, s.idemStore, s.logger), // 位于限流之后，被限流的请求不占用幂等键
+	)
+	if sh := s.cfg.SecurityHeaders; sh.Enabled && sh.CSP.Enabled && sh.CSP.ReportPath != "" {
+		// 浏览器匿名上报，同样受限流保护
+		s.echo.POST(sh.CSP.ReportPath, middleware.CSPReport(s.logger), middleware.RateLimit(s.limiter, s.logger))
+	}
 
 	addr := fmt.Sprintf("%s:%s", s.cfg.Server.Host, s.cfg.Server.Port)
+	limits := s.cfg.Server.Limits
 	s.httpServer = &http.Server{
-		Addr:    addr,
-		Handler: s.echo,
+		Addr:              addr,
+		Handler:           s.echo,
+		ReadHeaderTimeout: limits.ReadHeaderTimeout,
+		ReadTimeout:       limits.ReadTimeout,
+		WriteTimeout:      limits.WriteTimeout,
+		IdleTimeout:       limits.IdleTimeout,
+		MaxHeaderBytes:    limits.MaxHeaderBytes,
 	}
 
-	s.logger.Info("Server starting", zap.String("addr", addr))
+	if err := s.configureTLS(); err != nil {
+		return err
+	}
+
+	s.logger.Info("Server starting", zap.String("addr", addr), zap.Bool("tls", s.certs != nil))
+
+	s.startMetrics()
 
 	go func() {
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if s.certs != nil {
+			err = s.httpServer.ListenAndServeTLS("", "") // 证书由 TLSConfig.GetCertificate 提供
+		} else {
+			err = s.httpServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			s.logger.Fatal("Failed to start server", zap.Error(err))
 		}
 	}()
 
+	// 启动发件箱投递协程（未开启时不执行任何操作）
+	s.relay.Start()
+
+	// 启动密钥轮换任务（未开启时不执行任何操作）
+	s.rotator.Start()
+
+	// 监听配置变化以热更新 IP 名单（未使用外部配置文件时不执行任何操作）
+	s.ipFilter.Start()
+
 	return nil
 }
 
 func (s *HTTPServer) Stop(ctx context.Context) error {
 	s.logger.Info("Shutting down server...")
+	if err := s.relay.Stop(ctx); err != nil {
+		s.logger.Warn("Failed to stop outbox relay", zap.Error(err))
+	}
+	if err := s.rotator.Stop(ctx); err != nil {
+		s.logger.Warn("Failed to stop key rotator", zap.Error(err))
+	}
+	if err := s.ipFilter.Stop(ctx); err != nil {
+		s.logger.Warn("Failed to stop IP filter reloader", zap.Error(err))
+	}
+	if s.adminSrv != nil {
+		if err := s.adminSrv.Shutdown(ctx); err != nil {
+			s.logger.Warn("Failed to stop metrics server", zap.Error(err))
+		}
+	}
+	if s.redirect != nil {
+		if err := s.redirect.Shutdown(ctx); err != nil {
+			s.logger.Warn("Failed to stop redirect server", zap.Error(err))
+		}
+	}
+	if s.certs != nil {
+		if err := s.certs.Stop(ctx); err != nil {
+			s.logger.Warn("Failed to stop certificate reloader", zap.Error(err))
+		}
+	}
 	if s.httpServer != nil {
 		return s.httpServer.Shutdown(ctx)
 	}
 	return nil
 }
 
+// configureTLS 开启 TLS 时加载证书、启动热重载并按需启动 HTTP→HTTPS 跳转监听
+func (s *HTTPServer) configureTLS() error {
+	tc := s.cfg.Server.TLS
+	if !tc.Enabled {
+		return nil
+	}
+
+	certs, err := newCertReloader(tc.CertFile, tc.KeyFile, tc.ClientCAFile, tc.ReloadInterval, s.logger)
+	if err != nil {
+		return fmt.Errorf("failed to load tls certificate: %w", err)
+	}
+	tlsConfig, err := certs.tlsConfig(s.cfg)
+	if err != nil {
+		return err
+	}
+	s.httpServer.TLSConfig = tlsConfig
+	s.certs = certs
+	certs.Start()
+
+	if tc.Redirect.Enabled {
+		s.redirect = newRedirectServer(tc.Redirect.Listen, s.cfg.Server.Port, s.cfg.Server.Limits.ReadHeaderTimeout)
+		s.logger.Info("HTTPS redirect server starting", zap.String("addr", tc.Redirect.Listen))
+		go func() {
+			if err := s.redirect.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				s.logger.Error("Redirect server stopped", zap.Error(err))
+			}
+		}()
+	}
+	return nil
+}
+
+// startMetrics 暴露指标端点：配置了独立监听地址时启动管理端口，否则挂载在业务端口上；
+// 业务端口对外暴露，未开启 ip_filter 时拒绝挂载，避免指标被公开访问
+func (s *HTTPServer) startMetrics() {
+	cfg := s.cfg.Metrics
+	if !cfg.Enabled {
+		return
+	}
+
+	// 数据库连接池统计
+	if sqlDB, err := s.db.DB(); err == nil {
+		s.metrics.RegisterDBStats(sqlDB, s.cfg.Database.Driver)
+	} else {
+		s.logger.Warn("Failed to export database pool metrics", zap.Error(err))
+	}
+	// 发件箱待投递数量与积压时长
+	if s.cfg.Outbox.Enabled {
+		s.metrics.RegisterOutbox(s.relay.Metrics)
+	}
+
+	if cfg.Listen == "" {
+		if !s.ipFilter.Enabled() {
+			s.logger.Warn("Metrics endpoint not exposed: set metrics.listen or enable ip_filter to serve it on the business port",
+				zap.String("path", cfg.Path))
+			return
+		}
+		s.echo.GET(cfg.Path, echo.WrapHandler(s.metrics.Handler()), middleware.IPFilter(s.ipFilter, "admin"))
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path, s.metrics.Handler())
+	s.adminSrv = &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: s.cfg.Server.Limits.ReadHeaderTimeout,
+	}
+	s.logger.Info("Metrics server starting", zap.String("addr", cfg.Listen), zap.String("path", cfg.Path))
+
+	go func() {
+		if err := s.adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			s.logger.Error("Metrics server stopped", zap.Error(err))
+		}
+	}()
+}
+
 func (s *HTTPServer) GetEcho() *echo.Echo {
 	return s.echo
 }
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/ipfilter"
+	"github.com/labstack/echo/v4"
+)
+
+// newIPExtractor 根据受信任代理配置创建客户端 IP 提取器
+// 未配置受信任代理时直接使用连接对端地址，忽略可伪造的 X-Forwarded-For 与 X-Real-IP；
+// 配置后只有来自受信任网段的请求才读取 real_ip_header，X-Forwarded-For 从右向左跳过受信任代理
+func newIPExtractor(cfg *config.AppConfig) (echo.IPExtractor, error) {
+	prefixes, err := ipfilter.ParsePrefixes(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("server.trusted_proxies: %w", err)
+	}
+	if len(prefixes) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	// 关闭默认信任的回环、链路本地与私有网段，只信任显式配置的代理
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, p := range prefixes {
+		_, ipNet, err := net.ParseCIDR(p.String())
+		if err != nil {
+			return nil, fmt.Errorf("server.trusted_proxies: %w", err)
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+
+	switch http.CanonicalHeaderKey(cfg.Server.RealIPHeader) {
+	case "", echo.HeaderXForwardedFor:
+		return echo.ExtractIPFromXFFHeader(options...), nil
+	case echo.HeaderXRealIP:
+		return echo.ExtractIPFromRealIPHeader(options...), nil
+	default:
+		return nil, fmt.Errorf("unsupported server.real_ip_header: %s", cfg.Server.RealIPHeader)
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPExtractor(t *testing.T) {
+	realIP := func(t *testing.T, cfg *config.AppConfig, remote string, header http.Header) string {
+		t.Helper()
+		extractor, err := newIPExtractor(cfg)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remote + ":1234"
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		return extractor(req)
+	}
+	xff := http.Header{echo.HeaderXForwardedFor: {"198.51.100.7, 10.0.0.2"}}
+
+	t.Run("未配置受信任代理时使用对端地址", func(t *testing.T) {
+		cfg := &config.AppConfig{}
+		assert.Equal(t, "10.0.0.1", realIP(t, cfg, "10.0.0.1", xff))
+	})
+
+	t.Run("只信任来自受信任代理的X-Forwarded-For", func(t *testing.T) {
+		cfg := &config.AppConfig{}
+		cfg.Server.TrustedProxies = []string{"10.0.0.0/8"}
+		assert.Equal(t, "198.51.100.7", realIP(t, cfg, "10.0.0.1", xff), "trusted hops are skipped from the right")
+		assert.Equal(t, "192.0.2.9", realIP(t, cfg, "192.0.2.9", xff), "untrusted peer cannot spoof its address")
+		assert.Equal(t, "127.0.0.1", realIP(t, cfg, "127.0.0.1", xff), "loopback is not trusted implicitly")
+	})
+
+	t.Run("X-Real-IP", func(t *testing.T) {
+		cfg := &config.AppConfig{}
+		cfg.Server.TrustedProxies = []string{"10.0.0.1"}
+		cfg.Server.RealIPHeader = "x-real-ip"
+		header := http.Header{echo.HeaderXRealIP: {"198.51.100.8"}}
+		assert.Equal(t, "198.51.100.8", realIP(t, cfg, "10.0.0.1", header))
+		assert.Equal(t, "10.0.0.2", realIP(t, cfg, "10.0.0.2", header))
+	})
+
+	t.Run("配置错误", func(t *testing.T) {
+		cfg := &config.AppConfig{}
+		cfg.Server.TrustedProxies = []string{"10.0.0.0/40"}
+		_, err := newIPExtractor(cfg)
+		assert.Error(t, err)
+
+		cfg.Server.TrustedProxies = []string{"10.0.0.0/8"}
+		cfg.Server.RealIPHeader = "Forwarded"
+		_, err = newIPExtractor(cfg)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,221 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/HoronLee/EchoHub/internal/util/reloader"
+	"go.uber.org/zap"
+)
+
+// tlsMaterial 一次加载得到的证书与客户端 CA
+type tlsMaterial struct {
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	digest    [sha256.Size]byte // 文件内容摘要，用于判断是否发生变化
+}
+
+// certReloader 从磁盘加载证书，文件变化或收到 SIGHUP 时热重载
+// 新握手使用新证书，已建立的连接不受影响
+type certReloader struct {
+	certFile, keyFile, caFile string
+	logger                    *util.Logger
+
+	current  atomic.Pointer[tlsMaterial]
+	reloader *reloader.Reloader
+}
+
+func newCertReloader(certFile, keyFile, caFile string, interval time.Duration, logger *util.Logger) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		logger:   logger,
+	}
+	r.reloader = reloader.New(interval, r.tryReload)
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload 重新读取证书文件，内容未变化时返回 false
+// 新证书无效时保留旧证书并返回错误
+func (r *certReloader) reload() (bool, error) {
+	certPEM, err := os.ReadFile(r.certFile)
+	if err != nil {
+		return false, fmt.Errorf("read tls cert: %w", err)
+	}
+	keyPEM, err := os.ReadFile(r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("read tls key: %w", err)
+	}
+	var caPEM []byte
+	if r.caFile != "" {
+		if caPEM, err = os.ReadFile(r.caFile); err != nil {
+			return false, fmt.Errorf("read client ca: %w", err)
+		}
+	}
+
+	digest := sha256.Sum256(bytes.Join([][]byte{certPEM, keyPEM, caPEM}, []byte{0}))
+	if old := r.current.Load(); old != nil && old.digest == digest {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, fmt.Errorf("parse tls key pair: %w", err)
+	}
+	m := &tlsMaterial{cert: &cert, digest: digest}
+	if caPEM != nil {
+		m.clientCAs = x509.NewCertPool()
+		if !m.clientCAs.AppendCertsFromPEM(caPEM) {
+			return false, errors.New("parse client ca: no certificates found")
+		}
+	}
+	r.current.Store(m)
+	return true, nil
+}
+
+// tryReload 重新加载证书并记录结果
+func (r *certReloader) tryReload(trigger string) {
+	changed, err := r.reload()
+	switch {
+	case err != nil:
+		r.logger.Error("Failed to reload TLS certificate, keeping the previous one",
+			zap.String("trigger", trigger), zap.Error(err))
+	case changed:
+		r.logger.Info("TLS certificate reloaded", zap.String("trigger", trigger), zap.String("cert_file", r.certFile))
+	}
+}
+
+// Start 监听 SIGHUP 并按间隔检查文件变化
+func (r *certReloader) Start() {
+	r.reloader.Start()
+}
+
+// Stop 停止监听
+func (r *certReloader) Stop(ctx context.Context) error {
+	return r.reloader.Stop(ctx)
+}
+
+// getCertificate 供 tls.Config.GetCertificate 使用，始终返回最新证书
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.current.Load().cert, nil
+}
+
+// tlsConfig 构建服务端 tls.Config，证书与客户端 CA 在每次握手时取最新值
+func (r *certReloader) tlsConfig(cfg *config.AppConfig) (*tls.Config, error) {
+	tc := cfg.Server.TLS
+
+	minVersion, err := parseTLSVersion(tc.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	suites, err := parseCipherSuites(tc.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	clientAuth, err := parseClientAuth(tc.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+	if clientAuth != tls.NoClientCert && r.caFile == "" {
+		return nil, fmt.Errorf("tls client_auth %q requires client_ca_file", tc.ClientAuth)
+	}
+
+	base := &tls.Config{
+		MinVersion:     minVersion,
+		CipherSuites:   suites,
+		ClientAuth:     clientAuth,
+		NextProtos:     []string{"h2", "http/1.1"},
+		GetCertificate: r.getCertificate,
+	}
+	if r.caFile == "" {
+		return base, nil
+	}
+
+	// 客户端 CA 可能随证书一起轮换，按握手动态下发
+	server := base.Clone()
+	server.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		c := base.Clone()
+		c.ClientCAs = r.current.Load().clientCAs
+		return c, nil
+	}
+	return server, nil
+}
+
+func parseTLSVersion(v string) (uint16, error) {
+	switch v {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported tls min_version: %s", v)
+	}
+}
+
+// parseCipherSuites 将套件名称转换为 ID，只允许 Go 认为安全的套件
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	known := make(map[string]uint16)
+	for _, s := range tls.CipherSuites() {
+		known[s.Name] = s.ID
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported or insecure tls cipher suite: %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func parseClientAuth(v string) (tls.ClientAuthType, error) {
+	switch v {
+	case "", "none":
+		return tls.NoClientCert, nil
+	case "verify_if_given":
+		return tls.VerifyClientCertIfGiven, nil
+	case "require":
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return 0, fmt.Errorf("unsupported tls client_auth: %s", v)
+	}
+}
+
+// newRedirectServer 创建将 HTTP 请求 301 跳转到 HTTPS 的服务
+// httpsPort 为业务端口，443 时跳转地址省略端口
+func newRedirectServer(listen, httpsPort string, readHeaderTimeout time.Duration) *http.Server {
+	return &http.Server{
+		Addr:              listen,
+		ReadHeaderTimeout: readHeaderTimeout,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			host := req.Host
+			if h, _, err := net.SplitHostPort(host); err == nil {
+				host = h
+			}
+			if httpsPort != "" && httpsPort != "443" {
+				host = net.JoinHostPort(host, httpsPort)
+			}
+			http.Redirect(w, req, "https://"+host+req.URL.RequestURI(), http.StatusMovedPermanently)
+		}),
+	}
+}
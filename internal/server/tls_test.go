@@ -0,0 +1,221 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/middleware"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA 测试用自签 CA
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "EchoHub Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue 签发证书，返回 PEM 编码的证书与私钥
+func (ca *testCA) issue(t *testing.T, cn string, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"EchoHub"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func newTestLogger() *util.Logger {
+	cfg := &config.AppConfig{}
+	cfg.Server.Mode = "debug"
+	return util.NewLogger(cfg)
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	writeFile(t, filepath.Join(dir, "tls.crt"), serverCert)
+	writeFile(t, filepath.Join(dir, "tls.key"), serverKey)
+	writeFile(t, filepath.Join(dir, "ca.crt"), ca.pem)
+
+	cfg := &config.AppConfig{}
+	cfg.Server.TLS.MinVersion = "1.2"
+	cfg.Server.TLS.ClientAuth = "require"
+
+	certs, err := newCertReloader(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt"), 0, newTestLogger())
+	require.NoError(t, err)
+	tlsConfig, err := certs.tlsConfig(cfg)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(middleware.ClientCert())
+	e.GET("/whoami", func(c echo.Context) error {
+		subject, _ := c.Get(middleware.ClientSubjectKey).(string)
+		return c.String(http.StatusOK, subject)
+	})
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	require.NoError(t, err)
+	srv := &http.Server{Handler: e}
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.pem)
+	url := "https://" + ln.Addr().String() + "/whoami"
+
+	// 未携带客户端证书时握手失败
+	anon := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	_, err = anon.Get(url)
+	assert.Error(t, err)
+
+	clientCert, clientKey := ca.issue(t, "svc-a", x509.ExtKeyUsageClientAuth)
+	pair, err := tls.X509KeyPair(clientCert, clientKey)
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{pair}}}}
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	buf := make([]byte, 64)
+	n, _ := resp.Body.Read(buf)
+	assert.Equal(t, "CN=svc-a,O=EchoHub", string(buf[:n]))
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	ca := newTestCA(t)
+
+	c1, k1 := ca.issue(t, "v1", x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, c1)
+	writeFile(t, keyFile, k1)
+	r, err := newCertReloader(certFile, keyFile, "", 0, newTestLogger())
+	require.NoError(t, err)
+
+	leafCN := func() string {
+		cert, err := r.getCertificate(nil)
+		require.NoError(t, err)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		return leaf.Subject.CommonName
+	}
+	assert.Equal(t, "v1", leafCN())
+
+	changed, err := r.reload()
+	require.NoError(t, err)
+	assert.False(t, changed, "unchanged files should not reload")
+
+	c2, k2 := ca.issue(t, "v2", x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, c2)
+	writeFile(t, keyFile, k2)
+	changed, err = r.reload()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "v2", leafCN())
+
+	// 新证书与私钥不匹配时保留旧证书
+	_, k3 := ca.issue(t, "v3", x509.ExtKeyUsageServerAuth)
+	writeFile(t, keyFile, k3)
+	_, err = r.reload()
+	assert.Error(t, err)
+	assert.Equal(t, "v2", leafCN())
+}
+
+func TestTLSConfigValidation(t *testing.T) {
+	r := &certReloader{}
+	cfg := &config.AppConfig{}
+
+	cfg.Server.TLS.MinVersion = "1.0"
+	_, err := r.tlsConfig(cfg)
+	assert.Error(t, err)
+
+	cfg.Server.TLS.MinVersion = "1.3"
+	cfg.Server.TLS.CipherSuites = []string{"TLS_RSA_WITH_RC4_128_SHA"}
+	_, err = r.tlsConfig(cfg)
+	assert.Error(t, err, "insecure suites are rejected")
+
+	cfg.Server.TLS.CipherSuites = []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}
+	cfg.Server.TLS.ClientAuth = "require"
+	_, err = r.tlsConfig(cfg)
+	assert.Error(t, err, "mTLS requires a client CA")
+
+	cfg.Server.TLS.ClientAuth = "none"
+	tc, err := r.tlsConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), tc.MinVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, tc.CipherSuites)
+}
+
+func TestRedirectServer(t *testing.T) {
+	tests := []struct {
+		port, host, want string
+	}{
+		{port: "8443", host: "example.com", want: "https://example.com:8443/api/v1/user?x=1"},
+		{port: "443", host: "example.com:80", want: "https://example.com/api/v1/user?x=1"},
+	}
+	for _, tt := range tests {
+		srv := newRedirectServer(":0", tt.port, time.Second)
+		req := httptest.NewRequest(http.MethodGet, "http://"+tt.host+"/api/v1/user?x=1", nil)
+		rec := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+		assert.Equal(t, tt.want, rec.Header().Get("Location"))
+	}
+}
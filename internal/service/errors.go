@@ -0,0 +1,148 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/HoronLee/EchoHub/internal/errcode"
+)
+
+// ErrNotFound 资源不存在，各模块的 ErrXxxNotFound 均满足 errors.Is(err, ErrNotFound)
+var ErrNotFound = errcode.NotFound
+
+// 业务错误码：前两位为模块，后三位为序号，发布后不可修改含义
+var (
+	// ErrConflict 乐观锁冲突，可通过 errors.Is 判断
+	ErrConflict = errcode.New(10001, http.StatusConflict, errcode.Messages{
+		errcode.LocaleZhCN: "资源已被其他请求修改，请刷新后重试",
+		errcode.LocaleEnUS: "Resource has been modified by another request",
+	})
+
+	// 数据库约束冲突，由数据层从驱动错误翻译为 *ConstraintError，可通过 errors.Is 判断
+	ErrDuplicate = errcode.New(10002, http.StatusConflict, errcode.Messages{
+		errcode.LocaleZhCN: "数据已存在",
+		errcode.LocaleEnUS: "Duplicate value",
+	})
+	ErrForeignKey = errcode.New(10003, http.StatusConflict, errcode.Messages{
+		errcode.LocaleZhCN: "关联数据不存在或仍被引用",
+		errcode.LocaleEnUS: "Referenced resource does not exist or is still in use",
+	})
+	ErrNotNull = errcode.New(10004, http.StatusUnprocessableEntity, errcode.Messages{
+		errcode.LocaleZhCN: "缺少必填字段",
+		errcode.LocaleEnUS: "Required field is missing",
+	})
+	ErrCheckViolation = errcode.New(10005, http.StatusUnprocessableEntity, errcode.Messages{
+		errcode.LocaleZhCN: "字段取值不符合约束",
+		errcode.LocaleEnUS: "Value violates a check constraint",
+	})
+	// ErrUnderMaintenance 服务维护中，由维护模式中间件返回
+	ErrUnderMaintenance = errcode.New(10006, http.StatusServiceUnavailable, errcode.Messages{
+		errcode.LocaleZhCN: "系统维护中，请稍后再试",
+		errcode.LocaleEnUS: "Service is under maintenance, please try again later",
+	})
+	// ErrIdempotencyKeyReused 幂等键已用于方法、路径或请求体不同的请求，由幂等中间件返回
+	ErrIdempotencyKeyReused = errcode.New(10007, http.StatusUnprocessableEntity, errcode.Messages{
+		errcode.LocaleZhCN: "幂等键已用于其他请求",
+		errcode.LocaleEnUS: "Idempotency key has already been used for a different request",
+	})
+	// ErrIdempotencyInProgress 使用相同幂等键的请求仍在处理中，由幂等中间件返回
+	ErrIdempotencyInProgress = errcode.New(10008, http.StatusConflict, errcode.Messages{
+		errcode.LocaleZhCN: "相同幂等键的请求正在处理中",
+		errcode.LocaleEnUS: "A request with the same idempotency key is still being processed",
+	})
+
+	// ErrUsernameTaken 用户名已被占用
+	ErrUsernameTaken = errcode.New(20001, http.StatusConflict, errcode.Messages{
+		errcode.LocaleZhCN: "用户名已存在",
+		errcode.LocaleEnUS: "Username already exists",
+	})
+	// ErrInvalidCredentials 用户名或密码错误
+	ErrInvalidCredentials = errcode.New(20002, http.StatusUnauthorized, errcode.Messages{
+		errcode.LocaleZhCN: "用户名或密码错误",
+		errcode.LocaleEnUS: "Invalid username or password",
+	})
+	// ErrUserNotFound 用户不存在
+	ErrUserNotFound = errcode.New(20003, http.StatusNotFound, errcode.Messages{
+		errcode.LocaleZhCN: "用户不存在",
+		errcode.LocaleEnUS: "User not found",
+	})
+
+	// ErrHelloWorldNotFound HelloWorld 记录不存在
+	ErrHelloWorldNotFound = errcode.New(30001, http.StatusNotFound, errcode.Messages{
+		errcode.LocaleZhCN: "HelloWorld 记录不存在",
+		errcode.LocaleEnUS: "HelloWorld not found",
+	})
+)
+
+// ConflictError 乐观锁版本冲突错误
+// 当更新时携带的版本号与数据库中的版本号不一致时由数据层返回
+type ConflictError struct {
+	Resource string // 资源名称，例如 users
+	ID       uint   // 资源ID
+	Version  uint   // 调用方期望的版本号
+}
+
+// Error 实现error接口
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s %d: expected version %d is stale", e.Resource, e.ID, e.Version)
+}
+
+// Is 使 errors.Is(err, ErrConflict) 成立
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// Unwrap 使响应层按 ErrConflict 的错误码映射，冲突细节只记录在日志中
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// ConstraintKind 数据库约束类型
+type ConstraintKind string
+
+// 数据层可识别的约束类型
+const (
+	ConstraintUnique     ConstraintKind = "unique"
+	ConstraintForeignKey ConstraintKind = "foreign_key"
+	ConstraintNotNull    ConstraintKind = "not_null"
+	ConstraintCheck      ConstraintKind = "check"
+)
+
+// ConstraintError 数据库约束冲突错误
+// 由数据层从 MySQL、SQLite、Postgres 的驱动错误翻译而来，响应中只携带违反约束的字段名，不包含字段值
+type ConstraintError struct {
+	Kind       ConstraintKind // 约束类型
+	Table      string         // 表名
+	Field      string         // 违反约束的列，多列时以逗号分隔；驱动未提供时为空
+	Constraint string         // 约束或索引名称，驱动未提供时为空
+	Err        error          // 原始驱动错误，仅用于日志
+}
+
+// Error 实现error接口
+func (e *ConstraintError) Error() string {
+	target := e.Table
+	if e.Field != "" {
+		target += "." + e.Field
+	}
+	return fmt.Sprintf("%s: %s constraint violated: %v", target, e.Kind, e.Err)
+}
+
+// Unwrap 使 errors.Is 可按约束类型判断（如 ErrDuplicate），响应层据此映射错误码并附带字段名；
+// 同时保留原始驱动错误
+func (e *ConstraintError) Unwrap() []error {
+	var domain *errcode.Error
+	switch e.Kind {
+	case ConstraintUnique:
+		domain = ErrDuplicate
+	case ConstraintForeignKey:
+		domain = ErrForeignKey
+	case ConstraintNotNull:
+		domain = ErrNotNull
+	default:
+		domain = ErrCheckViolation
+	}
+	if e.Field != "" {
+		domain = domain.WithDetail("field", e.Field)
+	}
+	return []error{domain, e.Err}
+}
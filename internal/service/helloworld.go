@@ -2,29 +2,82 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	commonModel "github.com/HoronLee/EchoHub/internal/model/common"
 	"github.com/HoronLee/EchoHub/internal/model/helloworld"
+	"gorm.io/gorm"
 )
 
 // HelloWorldRepo 定义HelloWorld数据访问接口
 type HelloWorldRepo interface {
 	CreateHelloWorld(ctx context.Context, hw *helloworld.HelloWorld) error
+	GetHelloWorldByID(ctx context.Context, id uint) (*helloworld.HelloWorld, error)
+	GetHelloWorldByPublicID(ctx context.Context, publicID string) (*helloworld.HelloWorld, error)
+	ResolveHelloWorldID(ctx context.Context, publicID string) (uint, error)
+	UpdateHelloWorld(ctx context.Context, hw *helloworld.HelloWorld) error
+	OverwriteHelloWorld(ctx context.Context, hw *helloworld.HelloWorld) error
+	ListHelloWorlds(ctx context.Context, filter commonModel.ListFilter) ([]*helloworld.HelloWorld, error)
 	GetDatabaseInfo(ctx context.Context) (string, error)
 }
 
 // HelloWorldService HelloWorld服务实现
 type HelloWorldService struct {
-	repo HelloWorldRepo
+	repo      HelloWorldRepo
+	responses ResponseInvalidator
 }
 
 // NewHelloWorldService 创建HelloWorldService实例
-func NewHelloWorldService(repo HelloWorldRepo) *HelloWorldService {
-	return &HelloWorldService{repo: repo}
+func NewHelloWorldService(repo HelloWorldRepo, responses ResponseInvalidator) *HelloWorldService {
+	return &HelloWorldService{repo: repo, responses: responses}
 }
 
-func (s *HelloWorldService) PostHelloWorld(ctx context.Context, message string) error {
+func (s *HelloWorldService) PostHelloWorld(ctx context.Context, message string) (*helloworld.HelloWorld, error) {
 	hw := &helloworld.HelloWorld{Message: message}
-	return s.repo.CreateHelloWorld(ctx, hw)
+	if err := s.repo.CreateHelloWorld(ctx, hw); err != nil {
+		return nil, err
+	}
+	return hw, nil
+}
+
+// ResolveHelloWorldID 将公开ID解析为内部主键，不存在时返回 ErrHelloWorldNotFound
+func (s *HelloWorldService) ResolveHelloWorldID(ctx context.Context, publicID string) (uint, error) {
+	id, err := s.repo.ResolveHelloWorldID(ctx, publicID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("helloworld %s: %w", publicID, ErrHelloWorldNotFound)
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetHelloWorld 查询单条HelloWorld记录
+func (s *HelloWorldService) GetHelloWorld(ctx context.Context, id uint) (*helloworld.HelloWorld, error) {
+	hw, err := s.repo.GetHelloWorldByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("helloworld %d: %w", id, ErrHelloWorldNotFound)
+		}
+		return nil, err
+	}
+	return hw, nil
+}
+
+// UpdateHelloWorld 以乐观锁方式更新HelloWorld消息
+// version 为调用方期望的当前版本号，不一致时返回 *ConflictError
+func (s *HelloWorldService) UpdateHelloWorld(ctx context.Context, id uint, message string, version uint) (*helloworld.HelloWorld, error) {
+	hw := &helloworld.HelloWorld{ID: id, Message: message, Version: version}
+	if err := s.repo.UpdateHelloWorld(ctx, hw); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("helloworld %d: %w", id, ErrHelloWorldNotFound)
+		}
+		return nil, err
+	}
+	// 更新已提交，失效不受客户端断开影响
+	s.responses.Invalidate(context.WithoutCancel(ctx), helloworld.CacheTag)
+	return s.repo.GetHelloWorldByID(ctx, id)
 }
 
 func (s *HelloWorldService) GetDatabaseInfo(ctx context.Context) (string, error) {
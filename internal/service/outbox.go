@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	outboxModel "github.com/HoronLee/EchoHub/internal/model/outbox"
+)
+
+// OutboxRepo 定义事务性发件箱写入接口
+// 需要与领域数据原子写入时，应在 Transaction.InTx 中调用
+type OutboxRepo interface {
+	AddEvents(ctx context.Context, events ...*outboxModel.Event) error
+}
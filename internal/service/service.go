@@ -1,6 +1,22 @@
 package service
 
-import "github.com/google/wire"
+import (
+	"context"
+
+	"github.com/google/wire"
+)
 
 // ProviderSet is service providers.
-var ProviderSet = wire.NewSet(NewHelloWorldService, NewUserService)
+var ProviderSet = wire.NewSet(NewHelloWorldService, NewUserService, NewTransferService)
+
+// Transaction 定义事务接口，由数据层实现
+// fn 中通过 ctx 调用的仓库方法共享同一数据库事务
+type Transaction interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// ResponseInvalidator 定义缓存响应失效接口，由 HTTP 响应缓存实现
+// 写操作提交后按标签调用，失败只记录日志，不影响写操作本身
+type ResponseInvalidator interface {
+	Invalidate(ctx context.Context, tags ...string)
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	commonModel "github.com/HoronLee/EchoHub/internal/model/common"
+	"github.com/HoronLee/EchoHub/internal/model/helloworld"
+	"github.com/HoronLee/EchoHub/internal/model/user"
+	cryptoUtil "github.com/HoronLee/EchoHub/internal/util/crypto"
+	"github.com/HoronLee/EchoHub/internal/validator"
+	"gorm.io/gorm"
+)
+
+// 导入冲突处理策略
+const (
+	ConflictSkip      = "skip"      // 跳过已存在的记录
+	ConflictOverwrite = "overwrite" // 覆盖已存在的记录
+	ConflictFail      = "fail"      // 遇到冲突立即中止
+)
+
+// 单条记录的导入结果
+const (
+	ImportCreated     = "created"
+	ImportOverwritten = "overwritten"
+	ImportSkipped     = "skipped"
+	ImportInvalid     = "invalid"
+)
+
+// ErrImportConflict 导入记录与已有数据冲突（仅 fail 策略返回）
+var ErrImportConflict = errors.New("import conflict")
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	OnConflict string // 冲突处理策略
+	DryRun     bool   // 仅校验并统计，不写入数据库
+}
+
+// ImportOutcome 单条记录的导入结果
+type ImportOutcome struct {
+	Action string // created / overwritten / skipped / invalid
+	Err    error  // Action 为 invalid 时的校验错误
+}
+
+// TransferService 批量导入导出服务
+// 导入经由仓库层写入，沿用与注册相同的密码哈希和校验规则；
+// 导入用于迁移与恢复已有数据，有意不写入 user.registered 等发件箱事件，避免下游重复处理已知用户
+type TransferService struct {
+	users       UserRepo
+	helloworlds HelloWorldRepo
+	tx          Transaction
+	responses   ResponseInvalidator
+	v           *validator.Validator
+}
+
+// NewTransferService 创建TransferService实例
+func NewTransferService(users UserRepo, helloworlds HelloWorldRepo, tx Transaction, responses ResponseInvalidator, v *validator.Validator) *TransferService {
+	return &TransferService{
+		users:       users,
+		helloworlds: helloworlds,
+		tx:          tx,
+		responses:   responses,
+		v:           v,
+	}
+}
+
+// ExportUsers 按批导出用户，每批调用一次 fn
+// filter.Limit 为每批条数，max > 0 时最多导出 max 条；withPasswordHash 为 true 时才导出密码哈希；返回导出总数
+func (s *TransferService) ExportUsers(ctx context.Context, filter commonModel.ListFilter, max int, withPasswordHash bool, fn func([]*user.TransferRecord) error) (int, error) {
+	return exportBatches(ctx, filter, max, s.users.ListUsers, func(u *user.User) (uint, *user.TransferRecord) {
+		rec := &user.TransferRecord{
+			ID:        u.ID,
+			PublicID:  u.PublicID,
+			Username:  u.Username,
+			Role:      u.Role,
+			CreatedAt: u.CreatedAt,
+			UpdatedAt: u.UpdatedAt,
+		}
+		if withPasswordHash {
+			rec.PasswordHash = u.Password
+		}
+		return u.ID, rec
+	}, fn)
+}
+
+// ExportHelloWorlds 按批导出HelloWorld记录，参数含义同 ExportUsers
+func (s *TransferService) ExportHelloWorlds(ctx context.Context, filter commonModel.ListFilter, max int, fn func([]*helloworld.TransferRecord) error) (int, error) {
+	return exportBatches(ctx, filter, max, s.helloworlds.ListHelloWorlds, func(hw *helloworld.HelloWorld) (uint, *helloworld.TransferRecord) {
+		return hw.ID, &helloworld.TransferRecord{
+			ID:        hw.ID,
+			PublicID:  hw.PublicID,
+			Message:   hw.Message,
+			CreatedAt: hw.CreatedAt,
+		}
+	}, fn)
+}
+
+// ImportUsers 在同一事务中导入一批用户，以用户名判断冲突
+// 返回的结果与 records 一一对应；返回错误时整批回滚。
+// 覆盖已有用户时只能失效本进程的缓存，服务端使用 memory 缓存时会在 cache.default_ttl 内返回旧数据
+func (s *TransferService) ImportUsers(ctx context.Context, records []*user.TransferRecord, opts ImportOptions) ([]ImportOutcome, error) {
+	pending := make(map[string]bool)
+	return importBatch(ctx, s, records, opts, func(ctx context.Context, rec *user.TransferRecord) (string, error) {
+		if err := s.v.ValidateStruct(rec); err != nil {
+			return ImportInvalid, errors.New(s.v.FirstErrorMessage(err))
+		}
+
+		hash := rec.PasswordHash
+		if rec.Password != "" {
+			hash = cryptoUtil.MD5Encrypt(rec.Password)
+		}
+		if hash == "" {
+			return ImportInvalid, errors.New("password or password_hash is required")
+		}
+
+		existing, err := s.users.GetUserByUsername(ctx, rec.Username)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", err
+		}
+
+		if existing == nil && !pending[rec.Username] {
+			if opts.DryRun {
+				pending[rec.Username] = true
+			} else {
+				u := &user.User{PublicID: rec.PublicID, Username: rec.Username, Password: hash, Role: cmp.Or(rec.Role, user.RoleUser), CreatedAt: rec.CreatedAt}
+				if err := s.users.CreateUser(ctx, u); err != nil {
+					return "", err
+				}
+			}
+			return ImportCreated, nil
+		}
+
+		return resolveConflict(opts, fmt.Sprintf("user %q", rec.Username), func() error {
+			return s.users.OverwriteUser(ctx, &user.User{ID: existing.ID, Password: hash, Role: rec.Role})
+		})
+	})
+}
+
+// ImportHelloWorlds 在同一事务中导入一批HelloWorld记录，以ID判断冲突
+// 返回的结果与 records 一一对应；返回错误时整批回滚
+func (s *TransferService) ImportHelloWorlds(ctx context.Context, records []*helloworld.TransferRecord, opts ImportOptions) ([]ImportOutcome, error) {
+	pending := make(map[uint]bool)
+	outcomes, err := importBatch(ctx, s, records, opts, func(ctx context.Context, rec *helloworld.TransferRecord) (string, error) {
+		if err := s.v.ValidateStruct(rec); err != nil {
+			return ImportInvalid, errors.New(s.v.FirstErrorMessage(err))
+		}
+
+		existing, err := s.helloworlds.GetHelloWorldByID(ctx, rec.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", err
+		}
+
+		if existing == nil && !pending[rec.ID] {
+			if opts.DryRun {
+				pending[rec.ID] = true
+			} else {
+				hw := &helloworld.HelloWorld{ID: rec.ID, PublicID: rec.PublicID, Message: rec.Message, CreatedAt: rec.CreatedAt}
+				if err := s.helloworlds.CreateHelloWorld(ctx, hw); err != nil {
+					return "", err
+				}
+			}
+			return ImportCreated, nil
+		}
+
+		return resolveConflict(opts, fmt.Sprintf("helloworld %d", rec.ID), func() error {
+			return s.helloworlds.OverwriteHelloWorld(ctx, &helloworld.HelloWorld{ID: rec.ID, Message: rec.Message})
+		})
+	})
+	if err != nil || opts.DryRun {
+		return outcomes, err
+	}
+	// 批次事务已提交，覆盖了已有记录时使缓存的响应失效
+	if slices.ContainsFunc(outcomes, func(o ImportOutcome) bool { return o.Action == ImportOverwritten }) {
+		s.responses.Invalidate(ctx, helloworld.CacheTag)
+	}
+	return outcomes, nil
+}
+
+// resolveConflict 按策略处理已存在的记录
+func resolveConflict(opts ImportOptions, subject string, overwrite func() error) (string, error) {
+	switch opts.OnConflict {
+	case ConflictOverwrite:
+		if !opts.DryRun {
+			if err := overwrite(); err != nil {
+				return "", err
+			}
+		}
+		return ImportOverwritten, nil
+	case ConflictFail:
+		return "", fmt.Errorf("%s already exists: %w", subject, ErrImportConflict)
+	default:
+		return ImportSkipped, nil
+	}
+}
+
+// importBatch 逐条导入记录，非 dry-run 时整批处于同一事务中
+// dry-run 不写入数据库，调用方需自行记录本批中将被新建的键（pending），使批内重复记录与实际导入一样按冲突处理
+func importBatch[T any](ctx context.Context, s *TransferService, records []T, opts ImportOptions, fn func(ctx context.Context, rec T) (string, error)) ([]ImportOutcome, error) {
+	switch opts.OnConflict {
+	case "", ConflictSkip, ConflictOverwrite, ConflictFail:
+	default:
+		return nil, fmt.Errorf("unsupported conflict strategy: %s", opts.OnConflict)
+	}
+
+	var outcomes []ImportOutcome
+	run := func(ctx context.Context) error {
+		outcomes = make([]ImportOutcome, len(records))
+		for i, rec := range records {
+			action, err := fn(ctx, rec)
+			if action == ImportInvalid {
+				outcomes[i] = ImportOutcome{Action: action, Err: err}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			outcomes[i] = ImportOutcome{Action: action}
+		}
+		return nil
+	}
+
+	if opts.DryRun {
+		return outcomes, run(ctx)
+	}
+	if err := s.tx.InTx(ctx, run); err != nil {
+		return nil, err
+	}
+	return outcomes, nil
+}
+
+// exportBatches 以ID游标分页读取记录并转换为导出记录
+func exportBatches[M any, R any](
+	ctx context.Context,
+	filter commonModel.ListFilter,
+	max int,
+	list func(ctx context.Context, filter commonModel.ListFilter) ([]*M, error),
+	convert func(*M) (uint, *R),
+	fn func([]*R) error,
+) (int, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 500
+	}
+	batchSize := filter.Limit
+
+	total := 0
+	for {
+		if max > 0 && max-total < filter.Limit {
+			filter.Limit = max - total
+		}
+		if filter.Limit <= 0 {
+			return total, nil
+		}
+
+		rows, err := list(ctx, filter)
+		if err != nil {
+			return total, err
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+
+		batch := make([]*R, len(rows))
+		for i, row := range rows {
+			var id uint
+			id, batch[i] = convert(row)
+			filter.AfterID = id
+		}
+		if err := fn(batch); err != nil {
+			return total, err
+		}
+		total += len(batch)
+
+		if len(rows) < batchSize {
+			return total, nil
+		}
+	}
+}
@@ -3,9 +3,12 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/HoronLee/EchoHub/internal/config"
+	commonModel "github.com/HoronLee/EchoHub/internal/model/common"
+	outboxModel "github.com/HoronLee/EchoHub/internal/model/outbox"
 	"github.com/HoronLee/EchoHub/internal/model/user"
 	cryptoUtil "github.com/HoronLee/EchoHub/internal/util/crypto"
 	jwtutil "github.com/HoronLee/EchoHub/internal/util/jwt"
@@ -17,18 +20,26 @@ import (
 type UserRepo interface {
 	CreateUser(ctx context.Context, u *user.User) error
 	GetUserByUsername(ctx context.Context, username string) (*user.User, error)
-	GetUserByID(ctx context.Context, id uint) (*user.User, error)
+	GetUserByID(ctx context.Context, id uint) (*user.User, error) // 返回的用户不含密码哈希
+	GetUserByPublicID(ctx context.Context, publicID string) (*user.User, error)
+	GetUserRole(ctx context.Context, publicID string) (string, error)
+	ResolveUserID(ctx context.Context, publicID string) (uint, error)
+	UpdateUser(ctx context.Context, u *user.User) error
+	OverwriteUser(ctx context.Context, u *user.User) error
+	ListUsers(ctx context.Context, filter commonModel.ListFilter) ([]*user.User, error)
 	DeleteUser(ctx context.Context, id uint) error
 }
 
 // UserService 用户服务实现
 type UserService struct {
-	repo UserRepo
-	jwt  *jwtutil.JWT[user.Claims]
+	repo   UserRepo
+	tx     Transaction
+	outbox OutboxRepo
+	jwt    *jwtutil.JWT[user.Claims]
 }
 
 // NewUserService 创建UserService实例（通过Wire注入）
-func NewUserService(repo UserRepo) *UserService {
+func NewUserService(repo UserRepo, tx Transaction, outbox OutboxRepo) *UserService {
 	// 创建JWT helper
 	jwtCfg := &jwtutil.Config{
 		SecretKey: string(config.JWT_SECRET),
@@ -36,13 +47,15 @@ func NewUserService(repo UserRepo) *UserService {
 	jwt := jwtutil.NewJWT[user.Claims](jwtCfg)
 
 	return &UserService{
-		repo: repo,
-		jwt:  jwt,
+		repo:   repo,
+		tx:     tx,
+		outbox: outbox,
+		jwt:    jwt,
 	}
 }
 
 // Register 用户注册
-// 检查用户名是否存在，使用MD5加密密码，创建用户
+// 检查用户名是否存在，使用MD5加密密码，创建用户并在同一事务中写入 user.registered 事件
 func (s *UserService) Register(ctx context.Context, req user.RegisterRequest) error {
 	// 1. 检查用户名是否已存在
 	existingUser, err := s.repo.GetUserByUsername(ctx, req.Username)
@@ -52,7 +65,7 @@ func (s *UserService) Register(ctx context.Context, req user.RegisterRequest) er
 	}
 	if existingUser != nil {
 		// 用户名已存在
-		return errors.New("username already exists")
+		return ErrUsernameTaken.WithDetail("field", "username")
 	}
 
 	// 2. 使用MD5加密密码
@@ -62,9 +75,29 @@ func (s *UserService) Register(ctx context.Context, req user.RegisterRequest) er
 	newUser := &user.User{
 		Username: req.Username,
 		Password: hashedPassword,
+		Role:     user.RoleUser,
 	}
 
-	return s.repo.CreateUser(ctx, newUser)
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateUser(ctx, newUser); err != nil {
+			return usernameTaken(err)
+		}
+		return s.addUserEvent(ctx, newUser.PublicID, user.EventUserRegistered, user.RegisteredEvent{
+			PublicID:  newUser.PublicID,
+			Username:  newUser.Username,
+			CreatedAt: newUser.CreatedAt,
+		})
+	})
+}
+
+// usernameTaken 将用户名唯一约束冲突转换为 ErrUsernameTaken
+// 先查询后写入存在竞态，并发请求可能同时通过检查，此时由数据库唯一索引兜底
+func usernameTaken(err error) error {
+	var ce *ConstraintError
+	if errors.As(err, &ce) && ce.Kind == ConstraintUnique && ce.Field == "username" {
+		return ErrUsernameTaken.Wrap(err).WithDetail("field", "username")
+	}
+	return err
 }
 
 // Login 用户登录
@@ -74,7 +107,7 @@ func (s *UserService) Login(ctx context.Context, req user.LoginRequest) (string,
 	u, err := s.repo.GetUserByUsername(ctx, req.Username)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", errors.New("invalid username or password")
+			return "", ErrInvalidCredentials
 		}
 		return "", err
 	}
@@ -82,13 +115,14 @@ func (s *UserService) Login(ctx context.Context, req user.LoginRequest) (string,
 	// 2. 验证密码
 	hashedPassword := cryptoUtil.MD5Encrypt(req.Password)
 	if u.Password != hashedPassword {
-		return "", errors.New("invalid username or password")
+		return "", ErrInvalidCredentials
 	}
 
 	// 3. 生成JWT Token
 	claims := &user.Claims{
-		UserID:   u.ID,
+		UserID:   u.PublicID,
 		Username: u.Username,
+		Role:     u.Role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(config.Config.Auth.Jwt.Expires) * time.Second)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -107,17 +141,96 @@ func (s *UserService) Login(ctx context.Context, req user.LoginRequest) (string,
 	return token, nil
 }
 
+// ResolveUserID 将公开ID解析为内部主键，不存在时返回 ErrUserNotFound
+func (s *UserService) ResolveUserID(ctx context.Context, publicID string) (uint, error) {
+	id, err := s.repo.ResolveUserID(ctx, publicID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("user %s: %w", publicID, ErrUserNotFound)
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// CurrentRole 查询用户当前角色，以数据库为准而不是令牌签发时的角色，用于管理权限判断
+func (s *UserService) CurrentRole(ctx context.Context, publicID string) (string, error) {
+	role, err := s.repo.GetUserRole(ctx, publicID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("user %s: %w", publicID, ErrUserNotFound)
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+// GetUser 查询用户信息
+func (s *UserService) GetUser(ctx context.Context, userID uint) (*user.User, error) {
+	u, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user %d: %w", userID, ErrUserNotFound)
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+// UpdateUser 以乐观锁方式更新用户信息
+// version 为调用方期望的当前版本号，不一致时返回 *ConflictError
+func (s *UserService) UpdateUser(ctx context.Context, userID uint, req user.UpdateRequest, version uint) (*user.User, error) {
+	// 1. 检查新用户名是否被其他用户占用
+	existingUser, err := s.repo.GetUserByUsername(ctx, req.Username)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existingUser != nil && existingUser.ID != userID {
+		return nil, ErrUsernameTaken.WithDetail("field", "username")
+	}
+
+	// 2. 带版本号更新
+	u := &user.User{ID: userID, Username: req.Username, Version: version}
+	if err := s.repo.UpdateUser(ctx, u); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user %d: %w", userID, ErrUserNotFound)
+		}
+		return nil, usernameTaken(err)
+	}
+
+	return s.repo.GetUserByID(ctx, userID)
+}
+
 // DeleteUser 删除用户
+// 删除用户并在同一事务中写入 user.deleted 事件
 func (s *UserService) DeleteUser(ctx context.Context, userID uint) error {
 	// 1. 检查用户是否存在
-	_, err := s.repo.GetUserByID(ctx, userID)
+	u, err := s.repo.GetUserByID(ctx, userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("user not found")
+			return fmt.Errorf("user %d: %w", userID, ErrUserNotFound)
 		}
 		return err
 	}
 
 	// 2. 删除用户
-	return s.repo.DeleteUser(ctx, userID)
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteUser(ctx, userID); err != nil {
+			return err
+		}
+		return s.addUserEvent(ctx, u.PublicID, user.EventUserDeleted, user.DeletedEvent{
+			PublicID:  u.PublicID,
+			Username:  u.Username,
+			DeletedAt: time.Now(),
+		})
+	})
+}
+
+// addUserEvent 写入用户聚合的领域事件，以公开ID作为聚合ID，避免向下游暴露自增主键
+func (s *UserService) addUserEvent(ctx context.Context, publicID string, eventType string, payload any) error {
+	e, err := outboxModel.NewEvent(user.AggregateType, publicID, eventType, payload)
+	if err != nil {
+		return err
+	}
+	return s.outbox.AddEvents(ctx, e)
 }
@@ -23,6 +23,98 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/v1/admin/maintenance": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "返回当前维护模式状态，需要管理令牌或管理员角色",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "运维管理"
+                ],
+                "summary": "查询维护模式状态",
+                "responses": {
+                    "200": {
+                        "description": "维护模式状态",
+                        "schema": {
+                            "$ref": "#/definitions/maintenance.State"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "无权访问",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "开启或关闭维护模式，状态写入状态文件，服务重启后保持；需要管理令牌或管理员角色",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "运维管理"
+                ],
+                "summary": "切换维护模式",
+                "parameters": [
+                    {
+                        "description": "维护模式参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/maintenance.UpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "切换后的维护模式状态",
+                        "schema": {
+                            "$ref": "#/definitions/maintenance.State"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "无权访问",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "参数校验失败",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/helloworld": {
             "post": {
                 "description": "创建一个新的HelloWorld消息并返回系统信息",
@@ -37,6 +129,12 @@ const docTemplate = `{
                 ],
                 "summary": "创建HelloWorld消息",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键，重试时携带相同的值将回放首次响应",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "HelloWorld创建请求参数",
                         "name": "request",
@@ -59,6 +157,157 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "409": {
+                        "description": "相同幂等键的请求仍在处理中",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "幂等键已用于不同的请求",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/helloworld/{id}": {
+            "get": {
+                "description": "根据公开ID查询HelloWorld消息，响应头 ETag 携带当前版本号",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "HelloWorld"
+                ],
+                "summary": "查询HelloWorld消息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "HelloWorld 公开ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "已缓存响应的 ETag，未变化时返回 304",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "查询成功",
+                        "schema": {
+                            "$ref": "#/definitions/helloworld.HelloWorld"
+                        }
+                    },
+                    "304": {
+                        "description": "内容未变化"
+                    },
+                    "400": {
+                        "description": "请求参数错误或ID格式非法",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "记录不存在（30001）",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "以乐观锁方式更新HelloWorld消息，期望版本号通过 If-Match 请求头或请求体 version 字段传入",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "HelloWorld"
+                ],
+                "summary": "更新HelloWorld消息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "HelloWorld 公开ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "期望的版本 ETag，例如 \\",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "HelloWorld更新请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/helloworld.UpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "$ref": "#/definitions/helloworld.HelloWorld"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误或ID格式非法",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "记录不存在（30001）",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "版本冲突（10001）",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "参数校验失败",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "428": {
+                        "description": "缺少版本号",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
                 }
             }
@@ -94,8 +343,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/user.LoginResponse"
                         }
                     },
-                    "400": {
-                        "description": "请求参数错误或登录失败",
+                    "401": {
+                        "description": "用户名或密码错误（20002）",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "参数校验失败",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -138,7 +393,19 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "请求参数错误或注册失败",
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "用户名已存在（20001）",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "参数校验失败",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -147,6 +414,111 @@ const docTemplate = `{
             }
         },
         "/v1/user": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "查询当前登录用户的信息，响应头 ETag 携带当前版本号",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "查询当前用户",
+                "responses": {
+                    "200": {
+                        "description": "查询成功",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_HoronLee_EchoHub_internal_model_user.User"
+                        }
+                    },
+                    "401": {
+                        "description": "用户未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在（20003）",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "以乐观锁方式更新当前登录用户的信息，期望版本号通过 If-Match 请求头或请求体 version 字段传入",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "更新当前用户",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "期望的版本 ETag，例如 \\",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "更新请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.UpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_HoronLee_EchoHub_internal_model_user.User"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "用户未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "版本冲突（10001）或用户名已存在（20001）",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "428": {
+                        "description": "缺少版本号",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
             "delete": {
                 "security": [
                     {
@@ -174,14 +546,14 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "400": {
-                        "description": "用户未认证或删除失败",
+                    "401": {
+                        "description": "用户未认证",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     },
-                    "401": {
-                        "description": "用户未认证",
+                    "404": {
+                        "description": "用户不存在（20003）",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -191,6 +563,32 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "github_com_HoronLee_EchoHub_internal_model_user.User": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "对外暴露的公开ID（UUIDv7）",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "角色，可选值: user, admin",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "乐观锁版本号，每次更新自增",
+                    "type": "integer"
+                }
+            }
+        },
         "helloworld.CreateRequest": {
             "type": "object",
             "required": [
@@ -212,6 +610,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "SQLite"
                 },
+                "id": {
+                    "type": "string",
+                    "example": "01890a5d-ac96-774b-bcce-b302099a8057"
+                },
                 "message": {
                     "type": "string",
                     "example": "Hello, World!"
@@ -222,6 +624,86 @@ const docTemplate = `{
                 }
             }
         },
+        "helloworld.HelloWorld": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "对外暴露的公开ID（UUIDv7）",
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "乐观锁版本号，每次更新自增",
+                    "type": "integer"
+                }
+            }
+        },
+        "helloworld.UpdateRequest": {
+            "type": "object",
+            "required": [
+                "message"
+            ],
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "minLength": 1,
+                    "example": "Hello, EchoHub!"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "maintenance.State": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "message": {
+                    "type": "string",
+                    "example": "数据库迁移中，预计 10 分钟后恢复"
+                },
+                "retry_after": {
+                    "type": "integer",
+                    "example": 600
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string",
+                    "example": "admin-token"
+                }
+            }
+        },
+        "maintenance.UpdateRequest": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "message": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "数据库迁移中，预计 10 分钟后恢复"
+                },
+                "retry_after": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 600
+                }
+            }
+        },
         "response.Response": {
             "type": "object",
             "properties": {
@@ -233,10 +715,20 @@ const docTemplate = `{
                 "data": {
                     "description": "Data 响应数据，具体内容因接口而异"
                 },
+                "details": {
+                    "description": "Details 错误的公开细节，仅部分业务错误携带",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
                 "msg": {
                     "description": "Msg 返回信息，通常是状态描述",
                     "type": "string",
                     "example": "success"
+                },
+                "request_id": {
+                    "description": "RequestID 请求ID，仅错误响应携带，便于反馈问题时定位日志",
+                    "type": "string",
+                    "example": "9f1c2d3e-4b5a-6789-abcd-ef0123456789"
                 }
             }
         },
@@ -285,6 +777,24 @@ const docTemplate = `{
                     "example": "john_doe"
                 }
             }
+        },
+        "user.UpdateRequest": {
+            "type": "object",
+            "required": [
+                "username"
+            ],
+            "properties": {
+                "username": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 3,
+                    "example": "john_doe"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
         }
     }
 }`
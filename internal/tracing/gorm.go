@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// gormSpanKey 在 gorm.Statement 中保存当前 span 的键
+const gormSpanKey = "echohub:tracing_span"
+
+// GormPlugin 为每条 GORM 语句创建子 span 的插件
+// span 的父级取自 db.WithContext 传入的上下文，因此仓储层需使用 Data.DB(ctx)
+type GormPlugin struct {
+	tracer        trace.Tracer
+	system        string
+	withStatement bool
+}
+
+// NewGormPlugin 创建 GORM 追踪插件，system 为数据库类型（如 mysql），withStatement 控制是否记录 SQL
+func NewGormPlugin(tp trace.TracerProvider, system string, withStatement bool) *GormPlugin {
+	return &GormPlugin{
+		tracer:        tp.Tracer(InstrumentationName),
+		system:        system,
+		withStatement: withStatement,
+	}
+}
+
+// Name 插件名称
+func (p *GormPlugin) Name() string {
+	return "echohub:tracing"
+}
+
+// Initialize 在各类操作的回调前后注册 span 的开始与结束
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	hooks := []struct {
+		op     string
+		before func(string, func(*gorm.DB)) error
+		after  func(string, func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, h := range hooks {
+		if err := h.before("echohub:tracing_before_"+h.op, p.before(h.op)); err != nil {
+			return err
+		}
+		if err := h.after("echohub:tracing_after_"+h.op, p.after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *GormPlugin) before(op string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := p.tracer.Start(db.Statement.Context, "gorm."+op, trace.WithSpanKind(trace.SpanKindClient))
+		span.SetAttributes(
+			semconv.DBSystemNameKey.String(p.system),
+			semconv.DBOperationName(op),
+		)
+		if table := db.Statement.Table; table != "" {
+			span.SetAttributes(semconv.DBCollectionName(table))
+		}
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func (p *GormPlugin) after(db *gorm.DB) {
+	v, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := v.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	if p.withStatement {
+		// 只记录带占位符的 SQL，不展开绑定参数
+		span.SetAttributes(semconv.DBQueryText(db.Statement.SQL.String()))
+	}
+	if db.RowsAffected >= 0 {
+		span.SetAttributes(attribute.Int64("db.response.rows_affected", db.RowsAffected))
+	}
+
+	if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
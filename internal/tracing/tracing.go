@@ -0,0 +1,139 @@
+// Package tracing 提供基于 OpenTelemetry 的链路追踪
+//
+// NewTracerProvider 根据配置创建 TracerProvider 并注册为全局实例，同时启用 W3C traceparent/baggage 传播；
+// HTTP 服务端 span 由 middleware.Tracing 创建，数据库 span 由 GormPlugin 创建。
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/google/wire"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+)
+
+// ProviderSet is tracing providers.
+var ProviderSet = wire.NewSet(NewTracerProvider)
+
+// InstrumentationName 本项目创建 span 使用的 instrumentation scope 名称
+const InstrumentationName = "github.com/HoronLee/EchoHub"
+
+// 导出器类型
+const (
+	ExporterOTLPGRPC = "otlp_grpc"
+	ExporterOTLPHTTP = "otlp_http"
+	ExporterStdout   = "stdout"
+)
+
+// NewTracerProvider 根据配置创建 TracerProvider 并设置为全局实例
+// 未启用时返回 noop 实现，调用方无需判断
+func NewTracerProvider(cfg *config.AppConfig, logger *log.Logger) (trace.TracerProvider, func(), error) {
+	if !cfg.Tracing.Enabled {
+		return noop.NewTracerProvider(), func() {}, nil
+	}
+
+	exporter, err := newExporter(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	tp, err := NewSDKProvider(cfg, exporter)
+	if err != nil {
+		return nil, nil, err
+	}
+	Install(tp)
+
+	logger.Info("Tracing initialized",
+		zap.String("exporter", cfg.Tracing.Exporter),
+		zap.String("endpoint", cfg.Tracing.Endpoint),
+		zap.Float64("sample_ratio", cfg.Tracing.SampleRatio))
+
+	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tp.Shutdown(ctx); err != nil {
+			logger.Warn("Failed to shutdown tracer provider", zap.Error(err))
+		}
+	}
+	return tp, cleanup, nil
+}
+
+// NewSDKProvider 使用给定导出器创建 TracerProvider，测试中可传入 tracetest.InMemoryExporter
+func NewSDKProvider(cfg *config.AppConfig, exporter sdktrace.SpanExporter) (*sdktrace.TracerProvider, error) {
+	serviceName := cfg.Tracing.ServiceName
+	if serviceName == "" {
+		serviceName = "echohub"
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(serviceName),
+		semconv.DeploymentEnvironmentName(cfg.Server.Mode),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tracing resource: %w", err)
+	}
+
+	ratio := min(max(cfg.Tracing.SampleRatio, 0), 1)
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// 上游已决定是否采样时跟随上游，根 span 按比例采样
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	), nil
+}
+
+// Install 将 TracerProvider 设置为全局实例并启用 W3C TraceContext 与 Baggage 传播
+func Install(tp trace.TracerProvider) {
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
+
+func newExporter(cfg *config.AppConfig) (sdktrace.SpanExporter, error) {
+	tc := cfg.Tracing
+	ctx := context.Background()
+
+	switch tc.Exporter {
+	case ExporterOTLPGRPC:
+		var opts []otlptracegrpc.Option
+		if tc.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(tc.Endpoint))
+		}
+		if tc.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if len(tc.Headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(tc.Headers))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case ExporterOTLPHTTP:
+		var opts []otlptracehttp.Option
+		if tc.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(tc.Endpoint))
+		}
+		if tc.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(tc.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(tc.Headers))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	case ExporterStdout, "":
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter: %s", tc.Exporter)
+	}
+}
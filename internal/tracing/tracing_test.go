@@ -0,0 +1,98 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/middleware"
+	"github.com/HoronLee/EchoHub/internal/tracing"
+	util "github.com/HoronLee/EchoHub/internal/util/log"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func TestRequestAndQuerySpans(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.Tracing.SampleRatio = 1
+	exporter := tracetest.NewInMemoryExporter()
+	tp, err := tracing.NewSDKProvider(cfg, exporter)
+	require.NoError(t, err)
+	tracing.Install(tp)
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tracing.NewGormPlugin(tp, "sqlite", true)))
+	require.NoError(t, db.AutoMigrate(&widget{}))
+	require.NoError(t, tp.ForceFlush(context.Background()))
+	exporter.Reset()
+
+	core, logs := observer.New(zap.InfoLevel)
+	log := &util.Logger{Logger: zap.New(core)}
+
+	e := echo.New()
+	e.Use(middleware.Tracing(tp))
+	e.GET("/widgets/:id", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		log.WithContext(ctx).Info("loading widget")
+		var w widget
+		err := db.WithContext(ctx).Where("name = ?", "secret").First(&w).Error
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	const parentTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/widgets/42", nil)
+	req.Header.Set("traceparent", "00-"+parentTraceID+"-00f067aa0ba902b7-01")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	require.NoError(t, tp.ForceFlush(context.Background()))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	query, server := spans[0], spans[1]
+
+	// 服务端 span 延续上游 traceparent，名称使用路由模板
+	assert.Equal(t, "GET /widgets/:id", server.Name)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, parentTraceID, server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.Contains(t, server.Attributes, attr("http.route", "/widgets/:id"))
+
+	// 数据库 span 是服务端 span 的子级，记录不存在不视为错误，SQL 不展开参数
+	assert.Equal(t, "gorm.query", query.Name)
+	assert.Equal(t, server.SpanContext.SpanID(), query.Parent.SpanID())
+	assert.Contains(t, query.Attributes, attr("db.collection.name", "widgets"))
+	assert.Empty(t, query.Events)
+	for _, kv := range query.Attributes {
+		if kv.Key == "db.query.text" {
+			assert.NotContains(t, kv.Value.AsString(), "secret")
+		}
+	}
+
+	// 日志携带 trace_id 与 span_id
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, parentTraceID, fields["trace_id"])
+	assert.Equal(t, server.SpanContext.SpanID().String(), fields["span_id"])
+}
+
+func attr(key, value string) attribute.KeyValue {
+	return attribute.String(key, value)
+}
@@ -0,0 +1,294 @@
+// Package transfer 提供批量导入导出使用的 JSONL/CSV 编解码
+//
+// 记录类型为带 json 标签的结构体；CSV 的列名与 json 标签一致，
+// 支持 string、整数、bool 与 time.Time（RFC3339）字段。
+package transfer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 支持的数据格式
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+)
+
+// Encoder 逐条写出记录
+type Encoder interface {
+	Encode(v any) error
+	Flush() error
+}
+
+// Decoder 逐条读取记录，读取结束时返回 io.EOF
+type Decoder interface {
+	Decode(v any) error
+	// Line 返回最近一条记录所在的行号，用于错误提示
+	Line() int
+}
+
+// NewEncoder 创建编码器，sample 为记录类型的零值，用于确定 CSV 列
+func NewEncoder(w io.Writer, format string, sample any) (Encoder, error) {
+	switch format {
+	case FormatJSONL:
+		bw := bufio.NewWriter(w)
+		return &jsonlEncoder{w: bw, enc: json.NewEncoder(bw)}, nil
+	case FormatCSV:
+		fields, err := structFields(reflect.TypeOf(sample))
+		if err != nil {
+			return nil, err
+		}
+		return &csvEncoder{w: csv.NewWriter(w), fields: fields}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// NewDecoder 创建解码器
+func NewDecoder(r io.Reader, format string) (Decoder, error) {
+	switch format {
+	case FormatJSONL:
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		return &jsonlDecoder{sc: sc}, nil
+	case FormatCSV:
+		cr := csv.NewReader(r)
+		cr.ReuseRecord = true
+		return &csvDecoder{r: cr}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// FormatFromPath 根据文件扩展名推断格式，无法推断时返回 def
+func FormatFromPath(path, def string) string {
+	switch {
+	case strings.HasSuffix(path, ".csv"):
+		return FormatCSV
+	case strings.HasSuffix(path, ".jsonl"), strings.HasSuffix(path, ".ndjson"):
+		return FormatJSONL
+	default:
+		return def
+	}
+}
+
+type jsonlEncoder struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *jsonlEncoder) Encode(v any) error { return e.enc.Encode(v) }
+func (e *jsonlEncoder) Flush() error       { return e.w.Flush() }
+
+type jsonlDecoder struct {
+	sc   *bufio.Scanner
+	line int
+}
+
+func (d *jsonlDecoder) Decode(v any) error {
+	for d.sc.Scan() {
+		d.line++
+		b := d.sc.Bytes()
+		if len(strings.TrimSpace(string(b))) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(b, v); err != nil {
+			return fmt.Errorf("line %d: %w", d.line, err)
+		}
+		return nil
+	}
+	if err := d.sc.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+func (d *jsonlDecoder) Line() int { return d.line }
+
+type csvEncoder struct {
+	w             *csv.Writer
+	fields        []field
+	headerWritten bool
+}
+
+func (e *csvEncoder) Encode(v any) error {
+	if !e.headerWritten {
+		header := make([]string, len(e.fields))
+		for i, f := range e.fields {
+			header[i] = f.name
+		}
+		if err := e.w.Write(header); err != nil {
+			return err
+		}
+		e.headerWritten = true
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	row := make([]string, len(e.fields))
+	for i, f := range e.fields {
+		s, err := formatValue(rv.FieldByIndex(f.index))
+		if err != nil {
+			return fmt.Errorf("column %s: %w", f.name, err)
+		}
+		row[i] = s
+	}
+	return e.w.Write(row)
+}
+
+func (e *csvEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type csvDecoder struct {
+	r      *csv.Reader
+	header []string
+}
+
+func (d *csvDecoder) Decode(v any) error {
+	if d.header == nil {
+		header, err := d.r.Read()
+		if err != nil {
+			return err
+		}
+		d.header = append([]string(nil), header...)
+	}
+
+	row, err := d.r.Read()
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("decode target must be a struct pointer")
+	}
+	fields, err := structFields(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]field, len(fields))
+	for _, f := range fields {
+		byName[f.name] = f
+	}
+
+	for i, col := range d.header {
+		f, ok := byName[col]
+		if !ok || i >= len(row) {
+			continue
+		}
+		if err := parseValue(rv.Elem().FieldByIndex(f.index), row[i]); err != nil {
+			return fmt.Errorf("line %d column %s: %w", d.Line(), col, err)
+		}
+	}
+	return nil
+}
+
+func (d *csvDecoder) Line() int {
+	line, _ := d.r.FieldPos(0)
+	return line
+}
+
+// field 记录结构体字段对应的列
+type field struct {
+	name  string
+	index []int
+}
+
+func structFields(t reflect.Type) ([]field, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("record type %s is not a struct", t)
+	}
+
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := strings.SplitN(sf.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, field{name: name, index: sf.Index})
+	}
+	return fields, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func formatValue(v reflect.Value) (string, error) {
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return "", nil
+		}
+		return t.Format(time.RFC3339Nano), nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	default:
+		return "", fmt.Errorf("unsupported field type %s", v.Type())
+	}
+}
+
+func parseValue(v reflect.Value, s string) error {
+	if s == "" {
+		return nil
+	}
+	if v.Type() == timeType {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
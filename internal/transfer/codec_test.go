@@ -0,0 +1,81 @@
+package transfer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Secret    string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	in := []*record{
+		{ID: 1, Name: "alice", Secret: "x", CreatedAt: created},
+		{ID: 2, Name: "bob, \"the builder\""},
+	}
+
+	for _, format := range []string{FormatJSONL, FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc, err := NewEncoder(&buf, format, record{})
+			require.NoError(t, err)
+			for _, r := range in {
+				require.NoError(t, enc.Encode(r))
+			}
+			require.NoError(t, enc.Flush())
+
+			dec, err := NewDecoder(&buf, format)
+			require.NoError(t, err)
+
+			var out []*record
+			for {
+				r := new(record)
+				err := dec.Decode(r)
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+				out = append(out, r)
+			}
+
+			require.Len(t, out, 2)
+			assert.Equal(t, uint(1), out[0].ID)
+			assert.True(t, created.Equal(out[0].CreatedAt))
+			assert.Empty(t, out[0].Secret, "json:\"-\" fields are never exported")
+			assert.Equal(t, in[1].Name, out[1].Name)
+			assert.True(t, out[1].CreatedAt.IsZero())
+			// CSV 第一行为表头
+			lastLine := map[string]int{FormatJSONL: 2, FormatCSV: 3}[format]
+			assert.Equal(t, lastLine, dec.Line())
+		})
+	}
+}
+
+func TestCSVHeaderOrderIndependent(t *testing.T) {
+	dec, err := NewDecoder(bytes.NewBufferString("name,unknown,id\ncarol,?,7\n"), FormatCSV)
+	require.NoError(t, err)
+
+	var r record
+	require.NoError(t, dec.Decode(&r))
+	assert.Equal(t, uint(7), r.ID)
+	assert.Equal(t, "carol", r.Name)
+	assert.Equal(t, 2, dec.Line())
+}
+
+func TestFormatFromPath(t *testing.T) {
+	assert.Equal(t, FormatCSV, FormatFromPath("users.csv", FormatJSONL))
+	assert.Equal(t, FormatJSONL, FormatFromPath("users.ndjson", FormatCSV))
+	assert.Equal(t, FormatJSONL, FormatFromPath("-", FormatJSONL))
+}
+
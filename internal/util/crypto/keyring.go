@@ -0,0 +1,220 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/util/env"
+)
+
+// 密钥环相关的环境变量，优先级高于配置文件
+const (
+	EnvEncryptionKeys       = "ENCRYPTION_KEYS"        // 格式: "1:<base64>,2:<base64>"
+	EnvEncryptionPrimaryKey = "ENCRYPTION_PRIMARY_KEY" // 当前用于加密的密钥版本
+	EnvBlindIndexKey        = "BLIND_INDEX_KEY"        // 盲索引 HMAC 密钥（base64）
+)
+
+// KeySize AES-256 密钥长度
+const KeySize = 32
+
+var (
+	// ErrNoKeyring 未配置密钥环
+	ErrNoKeyring = errors.New("encryption keyring is not configured")
+	// ErrUnknownKeyVersion 密文引用的密钥版本不在密钥环中
+	ErrUnknownKeyVersion = errors.New("unknown encryption key version")
+	// ErrMalformedCiphertext 密文格式错误
+	ErrMalformedCiphertext = errors.New("malformed ciphertext")
+)
+
+// Keyring 带版本号的 AES-256-GCM 密钥环
+// 密文格式为 v<version>:<base64(nonce||ciphertext)>，解密时按前缀选择密钥，
+// 因此轮换主密钥后旧数据仍可读取，并可由后台任务逐步重新加密
+type Keyring struct {
+	primary  uint32
+	aeads    map[uint32]cipher.AEAD
+	indexKey []byte
+}
+
+// NewKeyring 创建密钥环，keys 为版本号到 32 字节密钥的映射
+func NewKeyring(keys map[uint32][]byte, primary uint32, indexKey []byte) (*Keyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("primary key version %d: %w", primary, ErrUnknownKeyVersion)
+	}
+	kr := &Keyring{primary: primary, aeads: make(map[uint32]cipher.AEAD, len(keys)), indexKey: indexKey}
+	for version, key := range keys {
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("key version %d must be %d bytes, got %d", version, KeySize, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		kr.aeads[version] = aead
+	}
+	return kr, nil
+}
+
+// LoadKeyring 从配置与环境变量加载密钥环，未配置任何密钥时返回 nil
+func LoadKeyring(cfg *config.AppConfig) (*Keyring, error) {
+	enc := cfg.Encryption
+	raw := enc.Keys
+	if s := os.Getenv(EnvEncryptionKeys); s != "" {
+		raw = make(map[string]string)
+		for _, pair := range strings.Split(s, ",") {
+			version, key, ok := strings.Cut(strings.TrimSpace(pair), ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid %s entry %q, expected <version>:<base64>", EnvEncryptionKeys, pair)
+			}
+			raw[version] = key
+		}
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	keys := make(map[uint32][]byte, len(raw))
+	for v, k := range raw {
+		version, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key version %q: %w", v, err)
+		}
+		key, err := base64.StdEncoding.DecodeString(k)
+		if err != nil {
+			return nil, fmt.Errorf("key version %d is not valid base64: %w", version, err)
+		}
+		keys[uint32(version)] = key
+	}
+
+	primary := enc.PrimaryKey
+	if s := os.Getenv(EnvEncryptionPrimaryKey); s != "" {
+		v, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvEncryptionPrimaryKey, err)
+		}
+		primary = uint32(v)
+	}
+
+	var indexKey []byte
+	if s := env.GetEnvOrConfig(EnvBlindIndexKey, enc.BlindIndexKey); s != "" {
+		var err error
+		if indexKey, err = base64.StdEncoding.DecodeString(s); err != nil {
+			return nil, fmt.Errorf("blind index key is not valid base64: %w", err)
+		}
+	}
+	return NewKeyring(keys, primary, indexKey)
+}
+
+// Primary 返回当前用于加密的密钥版本
+func (k *Keyring) Primary() uint32 {
+	return k.primary
+}
+
+// Encrypt 使用主密钥加密，aad 为附加认证数据（通常是 表名.列名，防止密文被挪用到其他列）
+func (k *Keyring) Encrypt(plaintext, aad []byte) (string, error) {
+	aead := k.aeads[k.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, aad)
+	return "v" + strconv.FormatUint(uint64(k.primary), 10) + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 按密文前缀中的版本号选择密钥解密
+func (k *Keyring) Decrypt(ciphertext string, aad []byte) ([]byte, error) {
+	version, payload, err := splitCiphertext(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	aead, ok := k.aeads[version]
+	if !ok {
+		return nil, fmt.Errorf("key version %d: %w", version, ErrUnknownKeyVersion)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformedCiphertext
+	}
+	nonce, body := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, body, aad)
+}
+
+// NeedsRotation 判断密文是否由非主密钥加密
+func (k *Keyring) NeedsRotation(ciphertext string) bool {
+	version, _, err := splitCiphertext(ciphertext)
+	return err == nil && version != k.primary
+}
+
+// Reencrypt 使用主密钥重新加密密文
+func (k *Keyring) Reencrypt(ciphertext string, aad []byte) (string, error) {
+	plaintext, err := k.Decrypt(ciphertext, aad)
+	if err != nil {
+		return "", err
+	}
+	return k.Encrypt(plaintext, aad)
+}
+
+// CiphertextPrefix 返回指定版本密文的前缀，便于在 SQL 中筛选
+func CiphertextPrefix(version uint32) string {
+	return "v" + strconv.FormatUint(uint64(version), 10) + ":"
+}
+
+// BlindIndex 计算用于等值查询的盲索引（HMAC-SHA256，十六进制）
+// 调用方应在写入与查询前对值做相同的归一化（例如邮箱转小写）
+func (k *Keyring) BlindIndex(value string) (string, error) {
+	if len(k.indexKey) == 0 {
+		return "", errors.New("blind index key is not configured")
+	}
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+func splitCiphertext(ciphertext string) (uint32, string, error) {
+	prefix, payload, ok := strings.Cut(ciphertext, ":")
+	if !ok || len(prefix) < 2 || prefix[0] != 'v' {
+		return 0, "", ErrMalformedCiphertext
+	}
+	version, err := strconv.ParseUint(prefix[1:], 10, 32)
+	if err != nil {
+		return 0, "", ErrMalformedCiphertext
+	}
+	return uint32(version), payload, nil
+}
+
+var (
+	defaultKeyring *Keyring
+	keyringMu      sync.RWMutex
+)
+
+// SetDefaultKeyring 设置全局密钥环（GORM 序列化器注册表是全局的，因此密钥环也需全局可见）
+func SetDefaultKeyring(k *Keyring) {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+	defaultKeyring = k
+}
+
+// DefaultKeyring 获取全局密钥环，未配置时返回 ErrNoKeyring
+func DefaultKeyring() (*Keyring, error) {
+	keyringMu.RLock()
+	defer keyringMu.RUnlock()
+	if defaultKeyring == nil {
+		return nil, ErrNoKeyring
+	}
+	return defaultKeyring, nil
+}
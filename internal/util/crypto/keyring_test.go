@@ -0,0 +1,64 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyringEncryptDecrypt(t *testing.T) {
+	keys := map[uint32][]byte{1: bytes.Repeat([]byte{1}, KeySize)}
+	kr, err := NewKeyring(keys, 1, nil)
+	require.NoError(t, err)
+
+	ct, err := kr.Encrypt([]byte("secret"), []byte("users.phone"))
+	require.NoError(t, err)
+	pt, err := kr.Decrypt(ct, []byte("users.phone"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(pt))
+
+	_, err = kr.Decrypt(ct, []byte("users.email"))
+	assert.Error(t, err, "ciphertext must not decrypt under another column")
+	_, err = kr.Decrypt("v9:AAAA", nil)
+	assert.ErrorIs(t, err, ErrUnknownKeyVersion)
+	_, err = kr.Decrypt("plain", nil)
+	assert.ErrorIs(t, err, ErrMalformedCiphertext)
+
+	_, err = kr.BlindIndex("x")
+	assert.Error(t, err, "blind index requires its own key")
+
+	_, err = NewKeyring(keys, 2, nil)
+	assert.ErrorIs(t, err, ErrUnknownKeyVersion)
+}
+
+func TestLoadKeyringFromEnv(t *testing.T) {
+	cfg := &config.AppConfig{}
+	kr, err := LoadKeyring(cfg)
+	require.NoError(t, err)
+	assert.Nil(t, kr, "no keys configured")
+
+	k1 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, KeySize))
+	k2 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, KeySize))
+	cfg.Encryption.Keys = map[string]string{"1": k1}
+	cfg.Encryption.PrimaryKey = 1
+	t.Setenv(EnvEncryptionKeys, "1:"+k1+", 2:"+k2)
+	t.Setenv(EnvEncryptionPrimaryKey, "2")
+	t.Setenv(EnvBlindIndexKey, base64.StdEncoding.EncodeToString([]byte("index")))
+
+	kr, err = LoadKeyring(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), kr.Primary())
+
+	ct, err := kr.Encrypt([]byte("v"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "v2:", ct[:3])
+
+	a, err := kr.BlindIndex("alice@example.com")
+	require.NoError(t, err)
+	b, _ := kr.BlindIndex("alice@example.com")
+	assert.Equal(t, a, b, "blind index must be deterministic")
+}
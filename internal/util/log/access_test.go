@@ -0,0 +1,36 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLoggerFile(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.AccessLog.Output = "file"
+	cfg.AccessLog.Format = AccessFormatCombined
+	cfg.AccessLog.File.Path = filepath.Join(t.TempDir(), "nested", "access.log")
+
+	logger, cleanup, err := NewAccessLogger(cfg, nil)
+	require.NoError(t, err)
+	logger.Info(`192.0.2.1 - - [01/Jan/2026:00:00:00 +0000] "GET / HTTP/1.1" 200 2 "-" "-"`)
+	cleanup()
+
+	data, err := os.ReadFile(cfg.AccessLog.File.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1 - - [01/Jan/2026:00:00:00 +0000] \"GET / HTTP/1.1\" 200 2 \"-\" \"-\"\n", string(data),
+		"combined lines contain only the message")
+
+	cfg.AccessLog.Output = "syslog"
+	_, _, err = NewAccessLogger(cfg, nil)
+	assert.Error(t, err)
+	cfg.AccessLog.Output = "file"
+	cfg.AccessLog.Format = "xml"
+	_, _, err = NewAccessLogger(cfg, nil)
+	assert.Error(t, err)
+}
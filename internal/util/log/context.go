@@ -0,0 +1,27 @@
+package log
+
+import (
+	"context"
+
+	"github.com/HoronLee/EchoHub/internal/util/requestid"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// WithContext 返回附带上下文中关联字段（request_id、trace_id、span_id）的日志器，上下文中没有关联字段时返回自身
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	var fields []zap.Field
+	if id := requestid.FromContext(ctx); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields,
+			zap.String("trace_id", sc.TraceID().String()),
+			zap.String("span_id", sc.SpanID().String()),
+		)
+	}
+	if len(fields) == 0 {
+		return l
+	}
+	return &Logger{l.With(fields...)}
+}
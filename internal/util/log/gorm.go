@@ -3,8 +3,15 @@ package log
 import (
 	"context"
 	"errors"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/HoronLee/EchoHub/internal/config"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
@@ -13,55 +20,156 @@ import (
 // GormLogger Gorm日志适配器
 type GormLogger struct {
 	logger                    *Logger
+	LogLevel                  gormlogger.LogLevel
 	SlowThreshold             time.Duration
 	IgnoreRecordNotFoundError bool
+	RedactParams              bool
+	sampler                   *querySampler
+	helpers                   map[string]bool // 定位调用方时跳过的查询辅助函数（完整函数名）
 }
 
-// NewGormLogger 创建Gorm日志适配器
-func NewGormLogger(logger *Logger) *GormLogger {
+// NewGormLogger 根据 database 配置创建Gorm日志适配器
+func NewGormLogger(cfg *config.AppConfig, logger *Logger) *GormLogger {
+	db := cfg.Database
 	return &GormLogger{
 		logger:                    logger,
-		SlowThreshold:             200 * time.Millisecond,
+		LogLevel:                  ParseGormLogLevel(db.LogMode),
+		SlowThreshold:             db.SlowThreshold,
 		IgnoreRecordNotFoundError: true,
+		RedactParams:              db.RedactParams,
+		sampler:                   newQuerySampler(db.Sampling.Initial, db.Sampling.Thereafter, db.Sampling.Tick),
+		helpers:                   make(map[string]bool),
 	}
 }
 
+// SkipCallers 登记由仓储方法调用的共享查询辅助函数（如乐观锁更新），
+// 错误与慢查询日志的 source 跳过这些函数，指向调用它们的仓储方法；需在打开数据库连接前调用
+func (l *GormLogger) SkipCallers(fns ...any) {
+	for _, fn := range fns {
+		l.helpers[runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()] = true
+	}
+}
+
+// ParseGormLogLevel 将配置中的 logmode 转换为 Gorm 日志级别，未知值按 info 处理
+func ParseGormLogLevel(mode string) gormlogger.LogLevel {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "silent":
+		return gormlogger.Silent
+	case "error":
+		return gormlogger.Error
+	case "warn", "warning":
+		return gormlogger.Warn
+	default:
+		return gormlogger.Info
+	}
+}
+
+// LogMode 返回使用指定级别的副本，与 gorm 默认 logger 的语义一致
 func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
-	return l
+	newLogger := *l
+	newLogger.LogLevel = level
+	return &newLogger
 }
 
 func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
-	l.logger.Sugar().Infof(msg, data...)
+	if l.LogLevel >= gormlogger.Info {
+		l.logger.Sugar().Infof(msg, data...)
+	}
 }
 
 func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
-	l.logger.Sugar().Warnf(msg, data...)
+	if l.LogLevel >= gormlogger.Warn {
+		l.logger.Sugar().Warnf(msg, data...)
+	}
 }
 
 func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
-	l.logger.Sugar().Errorf(msg, data...)
+	if l.LogLevel >= gormlogger.Error {
+		l.logger.Sugar().Errorf(msg, data...)
+	}
+}
+
+// ParamsFilter 开启 RedactParams 时丢弃绑定参数，日志中的 SQL 保留 ? 占位符
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if l.RedactParams {
+		return sql, nil
+	}
+	return sql, params
 }
 
 func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.LogLevel <= gormlogger.Silent {
+		return
+	}
+
 	elapsed := time.Since(begin)
-	sql, rows := fc()
+	logger := l.logger.WithContext(ctx)
+	switch {
+	case err != nil && l.LogLevel >= gormlogger.Error && (!errors.Is(err, gorm.ErrRecordNotFound) || !l.IgnoreRecordNotFoundError):
+		sql, rows := fc()
+		fields := append(queryFields(sql, elapsed, rows), zap.String("source", l.callerSource()), zap.Error(err))
+		logger.Error("Database Error", fields...)
+	case l.SlowThreshold != 0 && elapsed > l.SlowThreshold && l.LogLevel >= gormlogger.Warn:
+		sql, rows := fc()
+		fields := append(queryFields(sql, elapsed, rows),
+			zap.String("source", l.callerSource()),
+			zap.Duration("threshold", l.SlowThreshold),
+		)
+		logger.Warn("Slow SQL", fields...)
+	case l.LogLevel >= gormlogger.Info:
+		sql, rows := fc()
+		if !l.sampler.allow(sql) {
+			return
+		}
+		logger.Info("Database Query", queryFields(sql, elapsed, rows)...)
+	}
+}
 
-	fields := []zap.Field{
+func queryFields(sql string, elapsed time.Duration, rows int64) []zap.Field {
+	return []zap.Field{
 		zap.String("sql", sql),
 		zap.Duration("elapsed", elapsed),
 		zap.Int64("rows", rows),
 	}
+}
 
-	if err != nil && (!errors.Is(err, gorm.ErrRecordNotFound) || !l.IgnoreRecordNotFoundError) {
-		fields = append(fields, zap.Error(err))
-		l.logger.Error("Database Error", fields...)
-		return
+// callerSource 返回 gorm、本适配器与已登记辅助函数之外的第一个调用方（通常是 data 层的仓储方法），格式为 dir/file.go:line
+func (l *GormLogger) callerSource() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		file := filepath.ToSlash(frame.File)
+		if !strings.Contains(file, "gorm.io/") && !strings.HasSuffix(file, "/util/log/gorm.go") && !l.helpers[frame.Function] {
+			return filepath.Base(filepath.Dir(file)) + "/" + filepath.Base(file) + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
 	}
+}
 
-	if l.SlowThreshold != 0 && elapsed > l.SlowThreshold {
-		l.logger.Warn("Slow SQL", fields...)
-		return
+// literalPattern 匹配 SQL 中的字符串和数字字面量，用于归一化出采样所用的语句模板
+var literalPattern = regexp.MustCompile(`'(?:[^']|'')*'|"(?:[^"]|"")*"|\b\d+(?:\.\d+)?\b`)
+
+// querySampler 按 SQL 模板采样普通查询日志，字面量不同但模板相同的语句共享计数
+type querySampler struct {
+	*Sampler
+}
+
+func newQuerySampler(initial, thereafter int, tick time.Duration) *querySampler {
+	s := NewSampler(initial, thereafter, tick)
+	if s == nil {
+		return nil
 	}
+	return &querySampler{s}
+}
 
-	l.logger.Debug("Database Query", fields...)
+// allow 判断本条查询日志是否需要输出，nil 采样器表示不采样
+func (s *querySampler) allow(sql string) bool {
+	if s == nil {
+		return true
+	}
+	return s.Allow(literalPattern.ReplaceAllString(sql, "?"))
 }
@@ -0,0 +1,133 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/HoronLee/EchoHub/internal/config"
+	"github.com/HoronLee/EchoHub/internal/util/requestid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type account struct {
+	ID       uint
+	Username string
+}
+
+func newObservedDB(t *testing.T, cfg *config.AppConfig) (*gorm.DB, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: NewGormLogger(cfg, &Logger{zap.New(core)}),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Session(&gorm.Session{Logger: gormlogger.Discard}).AutoMigrate(&account{}))
+	return db, logs
+}
+
+func findAccount(db *gorm.DB, name string) {
+	var a account
+	db.Where("username = ?", name).Find(&a)
+}
+
+func TestGormLoggerLogMode(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.Database.LogMode = "error"
+	db, logs := newObservedDB(t, cfg)
+
+	findAccount(db, "alice")
+	assert.Zero(t, logs.FilterMessage("Database Query").Len(), "error level must not log plain queries")
+
+	// Debug() 通过 LogMode(Info) 返回副本，不影响原实例
+	findAccount(db.Debug(), "alice")
+	assert.Equal(t, 1, logs.FilterMessage("Database Query").Len())
+
+	findAccount(db, "alice")
+	assert.Equal(t, 1, logs.FilterMessage("Database Query").Len())
+}
+
+func TestGormLoggerRedactParams(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.Database.RedactParams = true
+	db, logs := newObservedDB(t, cfg)
+
+	findAccount(db, "top-secret")
+	entries := logs.FilterMessage("Database Query").All()
+	require.Len(t, entries, 1)
+	sql := entries[0].ContextMap()["sql"].(string)
+	assert.Contains(t, sql, "username = ?")
+	assert.NotContains(t, sql, "top-secret")
+}
+
+func TestGormLoggerSlowQuerySource(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.Database.LogMode = "warn"
+	cfg.Database.SlowThreshold = time.Nanosecond
+	db, logs := newObservedDB(t, cfg)
+
+	findAccount(db, "alice")
+	entries := logs.FilterMessage("Slow SQL").All()
+	require.Len(t, entries, 1)
+	assert.Regexp(t, `^log/gorm_test\.go:\d+$`, entries[0].ContextMap()["source"])
+}
+
+func TestGormLoggerSkipCallers(t *testing.T) {
+	cfg := &config.AppConfig{}
+	cfg.Database.LogMode = "warn"
+	cfg.Database.SlowThreshold = time.Nanosecond
+	core, logs := observer.New(zapcore.DebugLevel)
+	gl := NewGormLogger(cfg, &Logger{zap.New(core)})
+	gl.SkipCallers(findAccount)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gl})
+	require.NoError(t, err)
+	require.NoError(t, db.Session(&gorm.Session{Logger: gormlogger.Discard}).AutoMigrate(&account{}))
+
+	// 辅助函数 findAccount 被跳过，source 指向调用它的这一行
+	findAccount(db, "alice")
+	_, _, line, _ := runtime.Caller(0)
+	entries := logs.FilterMessage("Slow SQL").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, fmt.Sprintf("log/gorm_test.go:%d", line-1), entries[0].ContextMap()["source"])
+}
+
+func TestQuerySampler(t *testing.T) {
+	s := newQuerySampler(2, 3, time.Minute)
+	now := time.Unix(0, 0)
+	s.now = func() time.Time { return now }
+
+	var allowed []int
+	for i := 1; i <= 8; i++ {
+		// 字面量不同但模板相同的语句共享计数
+		if s.allow("SELECT * FROM users WHERE id = " + string(rune('0'+i))) {
+			allowed = append(allowed, i)
+		}
+	}
+	assert.Equal(t, []int{1, 2, 5, 8}, allowed)
+	assert.True(t, s.allow("SELECT * FROM orders"), "other templates have their own budget")
+
+	// 新周期重新计数
+	now = now.Add(2 * time.Minute)
+	assert.True(t, s.allow("SELECT * FROM users WHERE id = 9"))
+
+	var nilSampler *querySampler
+	assert.True(t, nilSampler.allow("SELECT 1"))
+}
+
+func TestGormLoggerRequestID(t *testing.T) {
+	db, logs := newObservedDB(t, &config.AppConfig{})
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	findAccount(db.WithContext(ctx), "alice")
+	entries := logs.FilterMessage("Database Query").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "req-1", entries[0].ContextMap()["request_id"])
+}
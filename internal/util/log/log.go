@@ -118,3 +118,25 @@ func NewLogger(cfg *config.AppConfig) *Logger {
 	SetGlobalLogger(logger) // 自动设置为全局 logger
 	return logger
 }
+
+// NewCLILogger 创建命令行工具使用的日志记录器
+// 仅输出 Warn 及以上级别到 stderr，避免污染标准输出（例如 export 命令输出到 stdout）
+func NewCLILogger(cfg *config.AppConfig) *Logger {
+	encoderConfig := zapcore.EncoderConfig{
+		TimeKey:        "time",
+		LevelKey:       "level",
+		MessageKey:     "msg",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.SecondsDurationEncoder,
+		EncodeLevel:    zapcore.CapitalColorLevelEncoder,
+	}
+	core := zapcore.NewCore(
+		zapcore.NewConsoleEncoder(encoderConfig),
+		zapcore.AddSync(os.Stderr),
+		zapcore.WarnLevel,
+	)
+	logger := &Logger{zap.New(core)}
+	SetGlobalLogger(logger)
+	return logger
+}
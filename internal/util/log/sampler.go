@@ -0,0 +1,56 @@
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+// Sampler 按键采样日志：每个周期内同一键前 initial 条全部记录，之后每 thereafter 条记录一条
+// 用于 SQL 查询日志（键为语句模板）与访问日志（键为路由）
+type Sampler struct {
+	initial    int
+	thereafter int
+	tick       time.Duration
+
+	mu      sync.Mutex
+	resetAt time.Time
+	counts  map[string]int
+	now     func() time.Time
+}
+
+// NewSampler 创建采样器，initial 不大于 0 时返回 nil 表示不采样
+func NewSampler(initial, thereafter int, tick time.Duration) *Sampler {
+	if initial <= 0 {
+		return nil
+	}
+	if tick <= 0 {
+		tick = time.Second
+	}
+	return &Sampler{
+		initial:    initial,
+		thereafter: thereafter,
+		tick:       tick,
+		counts:     make(map[string]int),
+		now:        time.Now,
+	}
+}
+
+// Allow 判断本条日志是否需要输出，nil 采样器表示不采样
+func (s *Sampler) Allow(key string) bool {
+	if s == nil {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now := s.now(); now.After(s.resetAt) {
+		clear(s.counts)
+		s.resetAt = now.Add(s.tick)
+	}
+	s.counts[key]++
+	n := s.counts[key]
+	if n <= s.initial {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.initial)%s.thereafter == 0
+}
@@ -0,0 +1,49 @@
+// Package pathmatch 提供配置中路径规则的匹配：以 * 结尾的规则按前缀匹配，其余精确匹配
+// 规则同时适用于请求路径与路由模板（echo.Context.Path），由调用方决定传入哪一个
+package pathmatch
+
+import "strings"
+
+// Pattern 单条路径规则
+type Pattern struct {
+	path   string
+	prefix bool
+}
+
+// Parse 解析路径规则，以 * 结尾时按前缀匹配
+func Parse(pattern string) Pattern {
+	path, prefix := strings.CutSuffix(pattern, "*")
+	return Pattern{path: path, prefix: prefix}
+}
+
+// Match 判断路径是否匹配规则
+func (p Pattern) Match(path string) bool {
+	if p.prefix {
+		return strings.HasPrefix(path, p.path)
+	}
+	return path == p.path
+}
+
+// List 路径规则列表，任一规则匹配即视为匹配
+type List []Pattern
+
+// ParseList 解析路径规则列表
+func ParseList(patterns []string) List {
+	l := make(List, 0, len(patterns))
+	for _, p := range patterns {
+		l = append(l, Parse(p))
+	}
+	return l
+}
+
+// Match 判断任一候选路径（如请求路径与路由模板）是否匹配任一规则
+func (l List) Match(paths ...string) bool {
+	for _, p := range l {
+		for _, path := range paths {
+			if p.Match(path) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package pathmatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPattern(t *testing.T) {
+	exact := Parse("/api/v1/login")
+	assert.True(t, exact.Match("/api/v1/login"))
+	assert.False(t, exact.Match("/api/v1/login/extra"))
+
+	prefix := Parse("/api/v1/admin/*")
+	assert.True(t, prefix.Match("/api/v1/admin/maintenance"))
+	assert.True(t, prefix.Match("/api/v1/admin/"))
+	assert.False(t, prefix.Match("/api/v1/admin"))
+	assert.False(t, prefix.Match("/api/v1/user"))
+}
+
+func TestList(t *testing.T) {
+	l := ParseList([]string{"/metrics", "/api/v1/helloworld/:id", "/static/*"})
+	assert.True(t, l.Match("/metrics"))
+	assert.True(t, l.Match("/api/v1/helloworld/42", "/api/v1/helloworld/:id"), "route template should match")
+	assert.True(t, l.Match("/static/app.js"))
+	assert.False(t, l.Match("/api/v1/user", "/api/v1/user"))
+	assert.False(t, ParseList(nil).Match("/metrics"))
+}
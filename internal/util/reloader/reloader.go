@@ -0,0 +1,93 @@
+// Package reloader 提供热重载的后台触发循环：收到 SIGHUP 或按固定间隔调用重载函数
+// 证书、IP 名单等需要在运行时从磁盘重新加载的组件共用该循环，各自只实现加载逻辑
+package reloader
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// 重载触发来源，传给重载函数用于记录日志或区分处理
+const (
+	TriggerSignal = "sighup" // 收到 SIGHUP
+	TriggerPoll   = "poll"   // 定时检查
+)
+
+// Reloader 在后台监听 SIGHUP 并按间隔触发重载
+type Reloader struct {
+	interval time.Duration
+	reload   func(trigger string)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New 创建Reloader实例，interval <= 0 时只在收到 SIGHUP 时触发
+func New(interval time.Duration, reload func(trigger string)) *Reloader {
+	return &Reloader{
+		interval: interval,
+		reload:   reload,
+	}
+}
+
+// Start 启动后台循环，重复调用无效果
+func (r *Reloader) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		defer close(r.done)
+		defer signal.Stop(hup)
+
+		var tick <-chan time.Time
+		if r.interval > 0 {
+			ticker := time.NewTicker(r.interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				r.reload(TriggerSignal)
+			case <-tick:
+				r.reload(TriggerPoll)
+			}
+		}
+	}()
+}
+
+// Stop 停止后台循环并等待正在进行的重载结束
+func (r *Reloader) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel = nil
+	r.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
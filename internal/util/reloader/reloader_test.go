@@ -0,0 +1,39 @@
+package reloader
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloader(t *testing.T) {
+	triggers := make(chan string, 16)
+	r := New(10*time.Millisecond, func(trigger string) { triggers <- trigger })
+	r.Start()
+	r.Start() // 重复启动无效果
+
+	assert.Equal(t, TriggerPoll, <-triggers)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	deadline := time.After(time.Second)
+	for got := false; !got; {
+		select {
+		case trigger := <-triggers:
+			got = trigger == TriggerSignal
+		case <-deadline:
+			t.Fatal("SIGHUP did not trigger a reload")
+		}
+	}
+
+	require.NoError(t, r.Stop(context.Background()))
+	require.NoError(t, r.Stop(context.Background()), "stopping twice should be a no-op")
+	for len(triggers) > 0 {
+		<-triggers
+	}
+	time.Sleep(30 * time.Millisecond)
+	assert.Empty(t, triggers, "no reloads after Stop")
+}
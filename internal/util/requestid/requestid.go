@@ -0,0 +1,52 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Header 请求ID请求头/响应头
+const Header = "X-Request-ID"
+
+// maxLength 客户端传入的请求ID最大长度，超出或包含非法字符时重新生成
+const maxLength = 128
+
+// ctxKey 是一个未导出的类型，用作在上下文中存储请求ID的键
+type ctxKey struct{}
+
+// New 生成新的请求ID
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid 判断客户端传入的请求ID是否可以直接使用
+// 仅允许字母、数字及 -_.:，避免日志注入
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext 将请求ID存储到上下文中
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext 从上下文中获取请求ID，不存在时返回空字符串
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}